@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/api"
 	"github.com/spf13/cobra"
+	"istio.io/istio/pkg/config/mesh/meshwatcher"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -38,8 +40,10 @@ import (
 	"github.com/cert-manager/istio-csr/pkg/certmanager"
 	"github.com/cert-manager/istio-csr/pkg/controller"
 	"github.com/cert-manager/istio-csr/pkg/istiodcert"
+	"github.com/cert-manager/istio-csr/pkg/meshconfig"
 	"github.com/cert-manager/istio-csr/pkg/server"
 	"github.com/cert-manager/istio-csr/pkg/tls"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
 )
 
 const (
@@ -63,6 +67,20 @@ func NewCommand(ctx context.Context) *cobra.Command {
 				return errs.ToAggregate()
 			}
 
+			shutdownTracing, err := tracing.Setup(ctx, opts.Tracing)
+			if err != nil {
+				return fmt.Errorf("failed to set up tracing: %w", err)
+			}
+			defer func() {
+				// Flush any remaining spans, using a fresh context as ctx is
+				// cancelled on shutdown.
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := shutdownTracing(shutdownCtx); err != nil {
+					opts.Logr.Error(err, "failed to shut down tracing")
+				}
+			}()
+
 			cm, err := certmanager.New(opts.Logr, opts.RestConfig, opts.CertManager)
 			if err != nil {
 				return fmt.Errorf("failed to initialise cert-manager manager: %w", err)
@@ -125,6 +143,16 @@ func NewCommand(ctx context.Context) *cobra.Command {
 				}
 			}
 
+			if err := mgr.Add(cm.CertificateRequestInformer()); err != nil {
+				return fmt.Errorf("failed to add CertificateRequest informer as runnable: %w", err)
+			}
+
+			if opts.CertManager.GarbageCollection.Enabled {
+				if err := mgr.Add(cm.GarbageCollector()); err != nil {
+					return fmt.Errorf("failed to add CertificateRequest garbage collector as runnable: %w", err)
+				}
+			}
+
 			if opts.CertManager.HasRuntimeConfiguration() {
 				if err := mgr.Add(cm.RuntimeConfigurationWatcher(ctx)); err != nil {
 					return fmt.Errorf("failed to add runtime configuration watcher as runnable: %w", err)
@@ -155,10 +183,30 @@ func NewCommand(ctx context.Context) *cobra.Command {
 			}
 
 			// Create an new server instance that implements the certificate signing API
-			server, err := server.New(opts.Logr, opts.RestConfig, cm, tls, opts.Server)
+			var meshWatcher meshwatcher.WatcherCollection
+			if opts.MeshConfig.Enabled {
+				watcher, err := meshconfig.New(opts.Logr, opts.RestConfig, opts.MeshConfig, opts.TLS.TrustDomain)
+				if err != nil {
+					return fmt.Errorf("failed to create mesh config watcher: %w", err)
+				}
+				if err := mgr.AddReadyzCheck("mesh_config", watcher.Check); err != nil {
+					return fmt.Errorf("failed to add mesh config readiness check: %w", err)
+				}
+				if err := mgr.Add(watcher); err != nil {
+					return fmt.Errorf("failed to add mesh config watcher as runnable: %w", err)
+				}
+				meshWatcher = watcher.Collection()
+			}
+
+			server, err := server.New(opts.Logr, opts.RestConfig, cm, tls, meshWatcher, opts.Server)
 			if err != nil {
 				return fmt.Errorf("failed to create grpc server: %w", err)
 			}
+			defer func() {
+				if err := server.Close(); err != nil {
+					opts.Logr.Error(err, "failed to close grpc server")
+				}
+			}()
 			if err := mgr.AddReadyzCheck("grpc_server", deferCheckUntilIssuerConfig(server.Check)); err != nil {
 				return fmt.Errorf("failed to add grpc server readiness check: %w", err)
 			}
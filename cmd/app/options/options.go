@@ -33,8 +33,10 @@ import (
 
 	"github.com/cert-manager/istio-csr/pkg/certmanager"
 	"github.com/cert-manager/istio-csr/pkg/istiodcert"
+	"github.com/cert-manager/istio-csr/pkg/meshconfig"
 	"github.com/cert-manager/istio-csr/pkg/server"
 	"github.com/cert-manager/istio-csr/pkg/tls"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
@@ -66,6 +68,8 @@ type Options struct {
 	TLS         tls.Options
 	Server      server.Options
 	IstiodCert  istiodcert.Options
+	Tracing     tracing.Options
+	MeshConfig  meshconfig.Options
 }
 
 // OptionsController is the Controller specific options
@@ -162,10 +166,47 @@ func (o *Options) Complete() error {
 		log.Info("WARNING: --preserve-certificate-requests is enabled. Do not enable this option in production, or environments with any non-trivial number of workloads for an extended period of time. Doing so will balloon the resource consumption of ETCD, the API server, and istio-csr, leading to errors and slowdown. This option is intended for debugging purposes only, for limited periods of time.")
 	}
 
+	if rc := o.CertManager.RequestCoalescing; rc.Enabled {
+		if rc.Timeout <= 0 {
+			return fmt.Errorf("certificate-request-coalescing-timeout must be positive, got %s", rc.Timeout)
+		}
+		if rc.CacheTTL < 0 {
+			return fmt.Errorf("certificate-request-coalescing-cache-ttl must not be negative, got %s", rc.CacheTTL)
+		}
+	}
+
+	if gc := o.CertManager.GarbageCollection; gc.Enabled {
+		if gc.Interval <= 0 {
+			return fmt.Errorf("certificate-request-gc-interval must be positive, got %s", gc.Interval)
+		}
+		if gc.Retention < 0 {
+			return fmt.Errorf("certificate-request-gc-retention must not be negative, got %s", gc.Retention)
+		}
+		if gc.RetainPerIdentity < 0 {
+			return fmt.Errorf("certificate-request-gc-retain-per-identity must not be negative, got %d", gc.RetainPerIdentity)
+		}
+		if gc.Retention == 0 && gc.RetainPerIdentity == 0 {
+			return fmt.Errorf("certificate-request-gc requires certificate-request-gc-retention or certificate-request-gc-retain-per-identity to be set")
+		}
+		// Shared requests may be waited on for up to the coalescing timeout,
+		// so must not be deleted before then.
+		if rc := o.CertManager.RequestCoalescing; rc.Enabled && gc.Retention > 0 && gc.Retention <= rc.Timeout {
+			return fmt.Errorf("certificate-request-gc-retention (%s) must be longer than certificate-request-coalescing-timeout (%s)", gc.Retention, rc.Timeout)
+		}
+	}
+
 	if o.Controller.MaxConcurrentReconciles < 1 {
 		return fmt.Errorf("max-concurrent-reconciles must be at least 1, got %d", o.Controller.MaxConcurrentReconciles)
 	}
 
+	if o.Server.Authenticators.CacheTTL < 0 {
+		return fmt.Errorf("authentication-cache-ttl must not be negative, got %s", o.Server.Authenticators.CacheTTL)
+	}
+
+	if o.Server.Authenticators.CacheTTL > 0 && o.Server.Authenticators.CacheSize < 1 {
+		return fmt.Errorf("authentication-cache-size must be at least 1 when the authentication cache is enabled, got %d", o.Server.Authenticators.CacheSize)
+	}
+
 	o.IstiodCert.MaxConcurrentReconciles = o.Controller.MaxConcurrentReconciles
 
 	err = o.IstiodCert.Validate()
@@ -173,6 +214,14 @@ func (o *Options) Complete() error {
 		return err
 	}
 
+	if err := o.Tracing.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.MeshConfig.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -189,6 +238,8 @@ func (o *Options) addFlags(cmd *cobra.Command) {
 	o.addAdditionalAnnotationsFlags(nfs.FlagSet("additional-annotations"))
 
 	istiodcert.AddFlags(&o.IstiodCert, nfs.FlagSet("istiod-cert"))
+	tracing.AddFlags(&o.Tracing, nfs.FlagSet("tracing"))
+	meshconfig.AddFlags(&o.MeshConfig, nfs.FlagSet("mesh-config"))
 
 	usageFmt := "Usage:\n  %s\n"
 	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
@@ -235,6 +286,11 @@ func (o *Options) addTLSFlags(fs *pflag.FlagSet) {
 		"trust-domain", "cluster.local",
 		"The Istio cluster's trust domain.")
 
+	fs.StringSliceVar(&o.TLS.TrustDomainAliases,
+		"trust-domain-aliases", []string{},
+		"Other trust domains of the mesh, which are accepted for authentication, client certificate "+
+			"verification and CSR URIs, for example when migrating between trust domains.")
+
 	fs.StringVar(&o.TLS.RootCAsCertFile, "root-ca-file", "",
 		"File location of a PEM encoded Roots CA bundle to be used as root of "+
 			"trust for TLS in the mesh. If empty, the CA returned from the "+
@@ -311,6 +367,54 @@ func (o *Options) addCertManagerFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&o.CertManager.IssuanceConfigMapNamespace, "runtime-issuance-config-map-namespace", "",
 		"Namespace for ConfigMap to be watched at runtime for issuer details")
+
+	fs.BoolVar(&o.CertManager.RequestCoalescing.Enabled,
+		"certificate-request-coalescing", true,
+		"If enabled, concurrent or recently completed requests with the same "+
+			"identity and CSR will share a single CertificateRequest and result. "+
+			"Enabled by default; set to false to create a CertificateRequest for "+
+			"every request.")
+	fs.DurationVar(&o.CertManager.RequestCoalescing.CacheTTL,
+		"certificate-request-coalescing-cache-ttl", 30*time.Second,
+		"The duration a signed result is reused for identical requests. Set to 0s "+
+			"to only coalesce requests which are in flight.")
+	fs.DurationVar(&o.CertManager.RequestCoalescing.Timeout,
+		"certificate-request-coalescing-timeout", time.Minute,
+		"The maximum duration to wait for a shared CertificateRequest to be "+
+			"signed, independent of the requests waiting on it.")
+
+	fs.BoolVar(&o.CertManager.DeterministicNames,
+		"deterministic-certificate-request-names", false,
+		"If enabled, CertificateRequests are named after a hash of the identity "+
+			"and CSR, rather than a generated name. Identical requests received by "+
+			"any replica wait on the existing CertificateRequest, rather than "+
+			"creating another.")
+
+	fs.BoolVar(&o.CertManager.GarbageCollection.Enabled,
+		"certificate-request-gc", false,
+		"If enabled, the leader periodically deletes CertificateRequests created by "+
+			"istio-csr which have completed and are older than the retention, or are not "+
+			"among the most recent completed requests of their identity. This removes requests "+
+			"leaked by replicas exiting before they could delete them. Note that this "+
+			"also deletes requests kept by preserve-certificate-requests.")
+	fs.DurationVar(&o.CertManager.GarbageCollection.Interval,
+		"certificate-request-gc-interval", 10*time.Minute,
+		"The duration between CertificateRequest garbage collections.")
+	fs.DurationVar(&o.CertManager.GarbageCollection.Retention,
+		"certificate-request-gc-retention", 24*time.Hour,
+		"The age after which completed CertificateRequests are deleted. Must be longer "+
+			"than certificate-request-coalescing-timeout. Set to 0s to disable deleting by age.")
+	fs.IntVar(&o.CertManager.GarbageCollection.RetainPerIdentity,
+		"certificate-request-gc-retain-per-identity", 0,
+		"The number of completed CertificateRequests kept for each identity. Set to "+
+			"0 to disable deleting by count.")
+	fs.BoolVar(&o.CertManager.GarbageCollection.CollectUnlabelled,
+		"certificate-request-gc-unlabelled", false,
+		"If enabled, also collects CertificateRequests created by earlier versions of "+
+			"istio-csr, which are not labelled as managed by istio-csr. This lists every "+
+			"CertificateRequest without the label, so a bounded number are listed per "+
+			"collection. Intended to be enabled after upgrading, until the unlabelled "+
+			"requests have been deleted.")
 }
 
 func (o *Options) addAdditionalAnnotationsFlags(fs *pflag.FlagSet) {
@@ -329,6 +433,14 @@ func (o *Options) addServerFlags(fs *pflag.FlagSet) {
 		"Maximum duration a client certificate can be requested and valid for. Will "+
 			"override with this value if the requested duration is larger")
 
+	fs.BoolVar(&o.Server.NamespaceDurationAnnotations,
+		"namespace-duration-annotations", false,
+		"Read certificate duration limits from the annotations of the namespace of "+
+			"requesting workloads: istio.cert-manager.io/max-certificate-duration, "+
+			"istio.cert-manager.io/min-certificate-duration and "+
+			"istio.cert-manager.io/default-certificate-duration. The lowest of "+
+			"--max-client-certificate-duration and the namespace maximum is applied.")
+
 	fs.StringVar(&o.Server.ClusterID, "cluster-id", "Kubernetes",
 		"The ID of the istio cluster to verify.")
 
@@ -336,6 +448,22 @@ func (o *Options) addServerFlags(fs *pflag.FlagSet) {
 		"enable-client-cert-authenticator", false,
 		"Enable the client certificate authenticator.")
 
+	fs.StringSliceVar(&o.Server.Authenticators.Audiences,
+		"token-audiences", []string{},
+		"The audiences accepted for Kubernetes service account tokens presented by "+
+			"workloads, for example istio-ca. Tokens must have been minted for at least one of "+
+			"these audiences. If empty, the default audiences of the Kubernetes JWT authenticator are used.")
+
+	fs.DurationVar(&o.Server.Authenticators.CacheTTL,
+		"authentication-cache-ttl", time.Minute,
+		"The maximum duration the result of a successful Kubernetes token authentication "+
+			"is cached for, avoiding a TokenReview for every request. Cache entries never "+
+			"outlive the expiry of the token. Set to 0 to disable the cache.")
+
+	fs.IntVar(&o.Server.Authenticators.CacheSize,
+		"authentication-cache-size", 4096,
+		"The maximum number of authenticated tokens held in the authentication cache.")
+
 	fs.StringSliceVar(&o.Server.CATrustedNodeAccounts,
 		"ca-trusted-node-accounts", []string{},
 		"A list of service accounts that are allowed to use node authentication for CSRs. "+
@@ -343,6 +471,162 @@ func (o *Options) addServerFlags(fs *pflag.FlagSet) {
 			"running on the same node with that identity. "+
 			"This is intended for use with node proxies.",
 	)
+
+	fs.StringSliceVar(&o.Server.KeyPolicy.AllowedAlgorithms,
+		"csr-key-allowed-algorithms", []string{},
+		"The public key algorithms workload CSRs may use. Valid values are RSA, ECDSA and Ed25519. "+
+			"If empty, all algorithms are allowed.")
+
+	fs.IntVar(&o.Server.KeyPolicy.MinRSAKeySize,
+		"csr-key-min-rsa-size", 0,
+		"The minimum size in bits of RSA public keys in workload CSRs. If 0, RSA keys of any size are allowed.")
+
+	fs.StringSliceVar(&o.Server.KeyPolicy.AllowedCurves,
+		"csr-key-allowed-curves", []string{},
+		"The elliptic curves ECDSA public keys in workload CSRs may use. Valid values are "+
+			"P-224, P-256, P-384 and P-521. If empty, all curves are allowed.")
+
+	fs.StringSliceVar(&o.Server.ExtKeyUsagePolicy.ClientAuthOnlyIdentities,
+		"client-auth-only-identities", []string{},
+		"SPIFFE ID glob patterns of workloads which are only granted the client auth extended key "+
+			"usage, for example spiffe://cluster.local/ns/batch/sa/*. Workloads are otherwise granted "+
+			"the extended key usages requested by their CSR, or client and server auth if none are requested.")
+
+	fs.StringSliceVar(&o.Server.ExtKeyUsagePolicy.ServerAuthOnlyIdentities,
+		"server-auth-only-identities", []string{},
+		"SPIFFE ID glob patterns of workloads which are only granted the server auth extended key "+
+			"usage. Workloads are otherwise granted the extended key usages requested by their CSR, or "+
+			"client and server auth if none are requested.")
+
+	fs.StringVar(&o.Server.ExternalAuthorization.Endpoint,
+		"external-authorization-endpoint", "",
+		"Optional URL of a policy service which must approve each authenticated certificate request "+
+			"before it is signed. The scheme selects the transport: https or http POST the request as JSON, "+
+			"grpcs or grpc call the istiocsr.authorization.v1.Authorizer/Authorize method. If empty, "+
+			"external authorization is disabled.")
+
+	fs.StringVar(&o.Server.ExternalAuthorization.CAFile,
+		"external-authorization-ca-file", "",
+		"Optional file containing PEM CA certificates used to verify the external authorization "+
+			"policy service. If empty, the system roots are used.")
+
+	fs.DurationVar(&o.Server.ExternalAuthorization.Timeout,
+		"external-authorization-timeout", 2*time.Second,
+		"The maximum duration to wait for a decision from the external authorization policy service.")
+
+	fs.BoolVar(&o.Server.ExternalAuthorization.FailOpen,
+		"external-authorization-fail-open", false,
+		"If true, allow requests when the external authorization policy service cannot be reached "+
+			"or returns an error. If false, such requests are rejected.")
+
+	fs.DurationVar(&o.Server.ExternalAuthorization.CacheTTL,
+		"external-authorization-cache-ttl", 30*time.Second,
+		"The duration external authorization decisions are cached for. Set to 0 to disable the cache.")
+
+	fs.IntVar(&o.Server.ExternalAuthorization.CacheSize,
+		"external-authorization-cache-size", 1024,
+		"The maximum number of cached external authorization decisions.")
+
+	fs.StringVar(&o.Server.Denylist.File,
+		"denylist-file", "",
+		"Optional file path to an identity denylist of SPIFFE IDs, namespaces and service accounts "+
+			"which are never issued certificates. The file is reloaded when changed. Only one of "+
+			"--denylist-file or --denylist-configmap-name may be set.")
+
+	fs.StringVar(&o.Server.Denylist.ConfigMapNamespace,
+		"denylist-configmap-namespace", "",
+		"The namespace of the identity denylist ConfigMap.")
+
+	fs.StringVar(&o.Server.Denylist.ConfigMapName,
+		"denylist-configmap-name", "",
+		"Optional name of an identity denylist ConfigMap, which is watched for changes. The "+
+			"denylist is read from the \"denylist.yaml\" key. Requests are rejected until the "+
+			"ConfigMap has been read; a missing ConfigMap is an empty denylist.")
+
+	fs.StringVar(&o.Server.MultiCluster.RemoteSecretNamespace,
+		"remote-secret-namespace", "",
+		"The namespace of istio remote secrets, labelled istio/multiCluster=true, holding "+
+			"kubeconfigs for remote clusters. Workloads in remote clusters are authenticated against "+
+			"their own cluster, using the cluster ID sent by the istio agent. If empty, only "+
+			"workloads in the local cluster are authenticated.")
+	fs.StringSliceVar(&o.Server.MultiCluster.InsecureKubeconfigOptions,
+		"remote-secret-insecure-kubeconfig-options", nil,
+		"Kubeconfig options which remote secrets may use. By default kubeconfigs using "+
+			"options which read files from, or run commands in, the istio-csr container are "+
+			"rejected. Options are exec, tokenFile, clientCertificate, clientKey, "+
+			"certificateAuthority, or the name of an auth provider.")
+
+	fs.StringVar((*string)(&o.Server.IssuedTrustDomainPolicy),
+		"issued-trust-domain-policy", string(server.TrustDomainPolicyRequested),
+		"The policy on which trust domain the SPIFFE IDs of issued certificates must use. "+
+			"\"Requested\" issues for the trust domain requested in the CSR, which may be the trust "+
+			"domain or one of its aliases. \"TrustDomain\" rejects CSRs for any trust domain other "+
+			"than the configured trust domain.")
+
+	fs.StringVar(&o.Server.AdmissionPolicyFile,
+		"admission-policy-file", "",
+		"Optional file path to a list of CEL admission policies. "+
+			"Each policy is an expression evaluated against the caller and the parsed CSR, which must "+
+			"evaluate to true for the request to be signed. The file is reloaded when changed. Policies "+
+			"are only read from this file; to manage them in a ConfigMap, mount the ConfigMap as a volume.")
+
+	fs.StringVar(&o.Server.SANPolicyFile,
+		"san-policy-file", "",
+		"Optional file path to a SAN policy, which allows the workloads of specific service "+
+			"accounts, such as ingress and egress gateways, to request certificates with DNS names "+
+			"and IP addresses alongside their SPIFFE ID. If empty, workload CSRs may only contain URI SANs.")
+
+	fs.StringVar(&o.Server.IssuerRoutingPolicyFile,
+		"issuer-routing-policy-file", "",
+		"Optional file path to an issuer routing policy. The policy routes workload "+
+			"certificate requests to an issuer based on the workload's namespace or SPIFFE "+
+			"identity. Workloads which match no rule are signed by the default issuer.")
+
+	fs.StringToStringVar(&o.Server.CertSignerIssuers,
+		"cert-signer-issuers", map[string]string{},
+		"Map of CertSigner names to the issuer that signs their certificates, in the form "+
+			"<cert-signer>=<kind>.<group>/<name> (e.g. payments=ClusterIssuer.cert-manager.io/payments-ca). "+
+			"Istio agents may request a CertSigner in the request metadata (meshConfig.ca.certSigners). "+
+			"If set, requests for an unknown CertSigner are rejected. If empty, requested CertSigners are ignored.")
+
+	fs.Float64Var(&o.Server.RateLimit.IdentityQPS,
+		"identity-rate-limit-qps", 0,
+		"Sustained number of certificate requests per second allowed for a single workload identity. "+
+			"Requests exceeding the limit are rejected with ResourceExhausted. 0 disables per-identity rate limiting.")
+
+	fs.IntVar(&o.Server.RateLimit.IdentityBurst,
+		"identity-rate-limit-burst", 5,
+		"Maximum burst of certificate requests allowed for a single workload identity.")
+
+	fs.Float64Var(&o.Server.RateLimit.NamespaceQPS,
+		"namespace-rate-limit-qps", 0,
+		"Sustained number of certificate requests per second allowed for all workload identities in a single namespace. "+
+			"Requests exceeding the limit are rejected with ResourceExhausted. 0 disables per-namespace rate limiting.")
+
+	fs.IntVar(&o.Server.RateLimit.NamespaceBurst,
+		"namespace-rate-limit-burst", 50,
+		"Maximum burst of certificate requests allowed for all workload identities in a single namespace.")
+
+	fs.IntVar(&o.Server.MaxInFlightSigningRequests,
+		"max-in-flight-signing-requests", 0,
+		"Maximum number of workload certificate requests which are signed concurrently. "+
+			"Each in-flight request creates and watches a CertificateRequest. 0 means unlimited.")
+
+	fs.IntVar(&o.Server.MaxQueuedSigningRequests,
+		"max-queued-signing-requests", 100,
+		"Maximum number of workload certificate requests which wait for a signing slot when "+
+			"max-in-flight-signing-requests is reached. Requests exceeding the queue are rejected "+
+			"with Unavailable so that clients back off.")
+
+	fs.StringVar(&o.Server.AuditLogPath,
+		"audit-log-path", "",
+		"Optional file path to which a JSON audit record of every workload certificate request "+
+			"is appended. If \"-\", records are written to stdout. If empty, audit logging is disabled.")
+
+	fs.BoolVar(&o.Server.VerboseErrorDetails,
+		"verbose-error-details", false,
+		"If true, the underlying cause of rejected workload certificate requests is returned to "+
+			"clients. The cause may contain sensitive information, such as token validation errors.")
 }
 
 func (o *Options) addControllerFlags(fs *pflag.FlagSet) {
@@ -30,19 +30,43 @@ import (
 	cmversioned "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
 	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/typed/certmanager/v1"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/cert-manager/istio-csr/pkg/kubewatch"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
 )
 
 const (
 	identityAnnotation = "istio.cert-manager.io/identities"
 )
 
+var tracer = otel.Tracer("github.com/cert-manager/istio-csr/pkg/certmanager")
+
+var (
+	// ErrNoActiveIssuer is returned by Sign if no issuer was given, and no
+	// issuer is currently active.
+	ErrNoActiveIssuer = errors.New("no active issuerRef is configured for istio-csr")
+
+	// ErrCertificateRequestDenied is returned by Sign if the created
+	// CertificateRequest was denied by an approver.
+	ErrCertificateRequestDenied = errors.New("created CertificateRequest has been denied")
+
+	// ErrCertificateRequestFailed is returned by Sign if the issuer failed to
+	// sign the created CertificateRequest.
+	ErrCertificateRequestFailed = errors.New("created CertificateRequest has failed")
+)
+
 type Options struct {
 	// If PreserveCertificateRequests is true, requests will not be deleted after
 	// they are signed.
@@ -65,6 +89,21 @@ type Options struct {
 
 	// AdditionalAnnotations are any additional annotations to include on created CertificateRequests.
 	AdditionalAnnotations map[string]string
+
+	// RequestCoalescing configures sharing a single CertificateRequest
+	// between identical sign requests, such as those retried by istio agents.
+	RequestCoalescing RequestCoalescingOptions
+
+	// If DeterministicNames is true, CertificateRequests are named after a
+	// hash of the request, rather than a generated name. Identical requests
+	// received by any replica wait on the existing CertificateRequest, rather
+	// than creating another.
+	DeterministicNames bool
+
+	// GarbageCollection configures the periodic deletion of
+	// CertificateRequests which have been preserved beyond their retention,
+	// or leaked by a replica exiting before it could delete them.
+	GarbageCollection GarbageCollectionOptions
 }
 
 func (o Options) HasRuntimeConfiguration() bool {
@@ -76,7 +115,8 @@ type Signer interface {
 	// wait for it to reach a terminal state, before optionally deleting it if
 	// preserving CertificateRequests if turned off. Will return the certificate
 	// bundle on successful signing.
-	Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage) (Bundle, error)
+	// If issuerRef is nil, the currently active issuer is used.
+	Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (Bundle, error)
 }
 
 // IssuerChangeSubscription is a subscription that can be used to get changes
@@ -142,6 +182,15 @@ type manager struct {
 
 	certManagerClient cmclient.CertificateRequestInterface
 
+	// crInformer is a shared informer of all CertificateRequests created by
+	// istio-csr, whose events are dispatched to the callers waiting on them.
+	crInformer cache.SharedIndexInformer
+	waiters    requestWaiters
+
+	// coalescer shares CertificateRequests between identical sign requests.
+	// Nil if request coalescing is disabled.
+	coalescer *requestCoalescer
+
 	// activeIssuerRef controls the issuerRef actually used when creating
 	// CertificateRequest objects. Can be empty, which will cause issuance to
 	// fail until runtime configuration is applied.
@@ -163,6 +212,9 @@ type manager struct {
 type Bundle struct {
 	Certificate []byte
 	CA          []byte
+
+	// IssuerRef is the issuer which signed the certificate.
+	IssuerRef cmmeta.IssuerReference
 }
 
 func New(log logr.Logger, restConfig *rest.Config, opts Options) (*manager, error) {
@@ -191,34 +243,81 @@ func New(log logr.Logger, restConfig *rest.Config, opts Options) (*manager, erro
 		activeIssuerRef = nil
 	}
 
-	return &manager{
+	m := &manager{
 		log: log.WithName("cert-manager"),
 
 		kubernetesClient:  k8sClient,
 		certManagerClient: cmClient.CertmanagerV1().CertificateRequests(opts.Namespace),
+		crInformer:        newCertificateRequestInformer(cmClient, opts.Namespace),
+		coalescer:         newRequestCoalescer(opts.RequestCoalescing),
 		opts:              opts,
 
 		activeIssuerRef: activeIssuerRef,
 
 		originalIssuerRef: originalIssuerRef,
-	}, nil
+	}
+
+	if _, err := m.crInformer.AddEventHandler(m.waiters.eventHandler()); err != nil {
+		return nil, fmt.Errorf("failed to add CertificateRequest informer event handler: %w", err)
+	}
+
+	return m, nil
 }
 
-// Sign will sign a request against the manager's configured client.
-func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage) (Bundle, error) {
-	m.activeIssuerRefMutex.RLock()
-	defer m.activeIssuerRefMutex.RUnlock()
+// Sign will sign a request against the manager's configured client. If
+// issuerRef is nil, the request is signed by the active issuer. If request
+// coalescing is enabled, identical requests share a single
+// CertificateRequest.
+func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (Bundle, error) {
+	if issuerRef == nil {
+		m.activeIssuerRefMutex.RLock()
+		issuerRef = m.activeIssuerRef
+		m.activeIssuerRefMutex.RUnlock()
+	}
 
-	if m.activeIssuerRef == nil {
-		return Bundle{}, fmt.Errorf("no active issuerRef is configured for istio-csr")
+	if issuerRef == nil {
+		return Bundle{}, ErrNoActiveIssuer
+	}
+
+	// Hold a signing slot for as long as the request is being signed. A
+	// coalesced request is signed with a context which outlives its caller,
+	// so the slot is acquired and released by the shared sign.
+	sign := func(ctx context.Context) (Bundle, error) {
+		release, err := AcquireSigningSlot(ctx)
+		if err != nil {
+			return Bundle{}, err
+		}
+		defer release()
+
+		return m.sign(ctx, identities, csrPEM, duration, usages, issuerRef)
+	}
+
+	if m.coalescer == nil {
+		return sign(ctx)
+	}
+
+	key := requestHash(identities, csrPEM, duration, usages, *issuerRef)
+	return m.coalescer.do(ctx, key, sign)
+}
+
+// sign creates a CertificateRequest for the given issuer, and waits for it to
+// be signed.
+func (m *manager) sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (Bundle, error) {
+	// Ensure the informer has synced before creating the request, so that we
+	// are able to observe it being signed.
+	if !cache.WaitForCacheSync(ctx.Done(), m.crInformer.HasSynced) {
+		return Bundle{}, fmt.Errorf("failed to wait for CertificateRequest informer to sync: %w", ctx.Err())
 	}
 
 	cr := &cmapi.CertificateRequest{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "istio-csr-",
+			GenerateName: certificateRequestNamePrefix,
 			Annotations: map[string]string{
 				identityAnnotation: identities,
 			},
+			Labels: map[string]string{
+				managedByLabelKey: managedByLabelValue,
+			},
 		},
 		Spec: cmapi.CertificateRequestSpec{
 			Duration: &metav1.Duration{
@@ -227,19 +326,38 @@ func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, du
 			IsCA:      false,
 			Request:   csrPEM,
 			Usages:    usages,
-			IssuerRef: *m.activeIssuerRef,
+			IssuerRef: *issuerRef,
 		},
 	}
 
+	if m.opts.DeterministicNames {
+		cr.GenerateName = ""
+		cr.Name = deterministicCertificateRequestName(requestHash(identities, csrPEM, duration, usages, *issuerRef))
+	}
+
 	maps.Copy(cr.ObjectMeta.Annotations, m.opts.AdditionalAnnotations)
 	// Create CertificateRequest and wait for it to be successfully signed.
-	cr, err := m.certManagerClient.Create(ctx, cr, metav1.CreateOptions{})
+	createCtx, createSpan := tracer.Start(ctx, "CreateCertificateRequest", trace.WithAttributes(
+		attribute.String("issuer.name", issuerRef.Name),
+		attribute.String("issuer.kind", issuerRef.Kind),
+		attribute.String("issuer.group", issuerRef.Group),
+	))
+	created, adopted, err := m.createCertificateRequest(createCtx, cr)
+	if err == nil {
+		createSpan.SetAttributes(attribute.String("certificaterequest.name", created.Name), attribute.Bool("certificaterequest.adopted", adopted))
+	}
+	tracing.EndSpan(createSpan, err)
 	if err != nil {
 		return Bundle{}, fmt.Errorf("failed to create CertificateRequest: %w", err)
 	}
+	cr = created
 
-	log := m.log.WithValues("namespace", cr.Namespace, "name", cr.Name, "identity", identities)
-	log.V(2).Info("created CertificateRequest")
+	log := m.requestLogger(ctx).WithValues("namespace", cr.Namespace, "name", cr.Name, "identity", identities, "issuer-name", issuerRef.Name, "issuer-kind", issuerRef.Kind)
+	if adopted {
+		log.V(2).Info("adopted existing CertificateRequest")
+	} else {
+		log.V(2).Info("created CertificateRequest")
+	}
 
 	// If we are not preserving CertificateRequests, always delete from
 	// Kubernetes on return.
@@ -249,10 +367,24 @@ func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, du
 			// Use go routine to prevent blocking on Delete call.
 			go func() {
 				// Use the Background context so that this call is not cancelled by the
-				// gRPC context closing.
-				cleanupCtx := context.Background()
-
-				if err := m.certManagerClient.Delete(cleanupCtx, cr.Name, metav1.DeleteOptions{}); err != nil {
+				// gRPC context closing. The request's span is kept so that the delete
+				// is traced as part of the request.
+				cleanupCtx, span := tracer.Start(trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx)),
+					"DeleteCertificateRequest", trace.WithAttributes(attribute.String("certificaterequest.name", cr.Name)))
+
+				// The UID precondition ensures that a CertificateRequest re-created
+				// with the same deterministic name is not deleted.
+				err := m.certManagerClient.Delete(cleanupCtx, cr.Name, metav1.DeleteOptions{
+					Preconditions: metav1.NewUIDPreconditions(string(cr.UID)),
+				})
+				// A CertificateRequest adopted by identical requests is deleted
+				// by whichever completes first, and may since have been
+				// re-created.
+				if m.opts.DeterministicNames && (apierrors.IsNotFound(err) || apierrors.IsConflict(err)) {
+					err = nil
+				}
+				tracing.EndSpan(span, err)
+				if err != nil {
 					log.Error(err, "failed to delete CertificateRequest")
 					return
 				}
@@ -262,7 +394,9 @@ func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, du
 		}()
 	}
 
-	signedCR, err := m.waitForCertificateRequest(ctx, log, cr)
+	waitCtx, waitSpan := tracer.Start(ctx, "WaitForCertificateRequest", trace.WithAttributes(attribute.String("certificaterequest.name", cr.Name)))
+	signedCR, err := m.waitForCertificateRequest(waitCtx, log, cr)
+	tracing.EndSpan(waitSpan, err)
 	if err != nil {
 		return Bundle{}, fmt.Errorf("failed to wait for CertificateRequest %s/%s to be signed: %w",
 			cr.Namespace, cr.Name, err)
@@ -270,31 +404,43 @@ func (m *manager) Sign(ctx context.Context, identities string, csrPEM []byte, du
 
 	log.V(2).Info("signed CertificateRequest")
 
-	return Bundle{Certificate: signedCR.Status.Certificate, CA: signedCR.Status.CA}, nil
+	return Bundle{Certificate: signedCR.Status.Certificate, CA: signedCR.Status.CA, IssuerRef: *issuerRef}, nil
 }
 
-// waitForCertificateRequest will set a watch for the CertificateRequest, and
-// will return the CertificateRequest once it has reached a terminal state. If
-// the terminal state is either Denied or Failed, then this will also return an
-// error.
-func (m *manager) waitForCertificateRequest(ctx context.Context, log logr.Logger, cr *cmapi.CertificateRequest) (*cmapi.CertificateRequest, error) {
-	watcher, err := m.certManagerClient.Watch(ctx, metav1.ListOptions{
-		FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, cr.Name).String(),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to build watcher for CertificateRequest: %w", err)
+// requestLogger returns the request-scoped logger stored in the context,
+// named for this manager. If none is stored, the manager's logger is returned.
+func (m *manager) requestLogger(ctx context.Context) logr.Logger {
+	if log, err := logr.FromContext(ctx); err == nil {
+		return log.WithName("cert-manager")
 	}
-	defer watcher.Stop()
+	return m.log
+}
+
+// waitForCertificateRequest will wait on events from the CertificateRequest
+// informer, and will return the CertificateRequest once it has reached a
+// terminal state. If the terminal state is either Denied or Failed, then this
+// will also return an error.
+func (m *manager) waitForCertificateRequest(ctx context.Context, log logr.Logger, cr *cmapi.CertificateRequest) (*cmapi.CertificateRequest, error) {
+	events, unregister := m.waiters.register(cr.Name)
+	defer unregister()
 
-	// Get the request in-case it has already reached a terminal state.
-	cr, err = m.certManagerClient.Get(ctx, cr.Name, metav1.GetOptions{})
+	// Get the request from the informer cache in-case it has already reached a
+	// terminal state before we registered for events.
+	obj, exists, err := m.crInformer.GetIndexer().GetByKey(cr.Namespace + "/" + cr.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get CertificateRequest: %w", err)
+		return nil, fmt.Errorf("failed to get CertificateRequest from informer cache: %w", err)
+	}
+	if exists {
+		// The cache may still hold a previous CertificateRequest with the same
+		// deterministic name, which has since been replaced.
+		if cached, ok := obj.(*cmapi.CertificateRequest); ok && cached.UID == cr.UID {
+			cr = cached
+		}
 	}
 
 	for {
 		if apiutil.CertificateRequestIsDenied(cr) {
-			return cr, fmt.Errorf("created CertificateRequest has been denied: %v", cr.Status.Conditions)
+			return cr, fmt.Errorf("%w: %v", ErrCertificateRequestDenied, cr.Status.Conditions)
 		}
 
 		if apiutil.CertificateRequestHasCondition(cr, cmapi.CertificateRequestCondition{
@@ -302,7 +448,7 @@ func (m *manager) waitForCertificateRequest(ctx context.Context, log logr.Logger
 			Status: cmmeta.ConditionFalse,
 			Reason: cmapi.CertificateRequestReasonFailed,
 		}) {
-			return cr, fmt.Errorf("created CertificateRequest has failed: %v", cr.Status.Conditions)
+			return cr, fmt.Errorf("%w: %v", ErrCertificateRequestFailed, cr.Status.Conditions)
 		}
 
 		if len(cr.Status.Certificate) > 0 {
@@ -311,25 +457,42 @@ func (m *manager) waitForCertificateRequest(ctx context.Context, log logr.Logger
 
 		log.V(3).Info("waiting for CertificateRequest to become ready")
 
-		for {
-			w, ok := <-watcher.ResultChan()
-			if !ok {
-				return cr, errors.New("watcher channel closed")
-			}
-			if w.Type == watch.Deleted {
-				return cr, errors.New("created CertificateRequest has been unexpectedly deleted")
-			}
+		select {
+		case <-ctx.Done():
+			return cr, ctx.Err()
 
-			cr, ok = w.Object.(*cmapi.CertificateRequest)
-			if !ok {
-				log.Error(nil, "got unexpected object response from watcher", "object", w.Object)
+		case event := <-events:
+			if event.cr.UID != cr.UID {
 				continue
 			}
-			break
+
+			// A request shared by deterministic names may be deleted by another
+			// replica once it has completed, so its final state is still used.
+			if event.deleted && !certificateRequestCompleted(event.cr) {
+				return cr, errors.New("created CertificateRequest has been unexpectedly deleted")
+			}
+			cr = event.cr
 		}
 	}
 }
 
+// certificateRequestCompleted returns true if the CertificateRequest has
+// reached a terminal state.
+func certificateRequestCompleted(cr *cmapi.CertificateRequest) bool {
+	return certificateRequestUnsuccessful(cr) || len(cr.Status.Certificate) > 0
+}
+
+// certificateRequestUnsuccessful returns true if the CertificateRequest has
+// been denied, or has failed.
+func certificateRequestUnsuccessful(cr *cmapi.CertificateRequest) bool {
+	return apiutil.CertificateRequestIsDenied(cr) ||
+		apiutil.CertificateRequestHasCondition(cr, cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionReady,
+			Status: cmmeta.ConditionFalse,
+			Reason: cmapi.CertificateRequestReasonFailed,
+		})
+}
+
 const (
 	issuerNameKey  = "issuer-name"
 	issuerKindKey  = "issuer-kind"
@@ -416,79 +579,46 @@ func (rcw *RuntimeConfigurationWatcher) NeedLeaderElection() bool {
 func (rcw *RuntimeConfigurationWatcher) Start(ctx context.Context) error {
 	logger := rcw.m.log.WithName("runtime-config-watcher").WithValues("config-map-name", rcw.m.opts.IssuanceConfigMapName, "config-map-namespace", rcw.m.opts.IssuanceConfigMapNamespace)
 
-LOOP:
-	for {
-		logger.Info("Starting / restarting watcher for runtime configuration")
-		cmList := &corev1.ConfigMapList{}
-
-		// First create a watcher. This is in a labelled loop in case the watcher dies for some reason
-		// while we're running - in that case, we don't want to give up entirely on watching for runtime config
-		// but instead we want to recreate the watcher.
-
-		watcher, err := rcw.m.kubernetesClient.Watch(ctx, cmList, &client.ListOptions{
+	// The watch is recreated if it dies for some reason while we're running, since
+	// we don't want to give up entirely on watching for runtime config.
+	kubewatch.Run(ctx, logger, "runtime configuration", func(ctx context.Context) (watch.Interface, error) {
+		return rcw.m.kubernetesClient.Watch(ctx, &corev1.ConfigMapList{}, &client.ListOptions{
 			FieldSelector: fields.OneTermEqualSelector("metadata.name", rcw.m.opts.IssuanceConfigMapName),
 			Namespace:     rcw.m.opts.IssuanceConfigMapNamespace,
 		})
+	}, func(event watch.Event) {
+		switch event.Type {
+		case watch.Deleted:
+			rcw.m.handleRuntimeConfigIssuerDeletion(logger)
+
+		case watch.Added:
+			err := rcw.m.handleRuntimeConfigIssuerChange(logger, event)
+			if err != nil {
+				logger.Error(err, "Failed to handle new runtime configuration for issuerRef")
+			}
 
-		if err != nil {
-			logger.Error(err, "Failed to create ConfigMap watcher; will retry in 5s")
-			time.Sleep(5 * time.Second)
-			continue
-		}
+		case watch.Modified:
+			err := rcw.m.handleRuntimeConfigIssuerChange(logger, event)
+			if err != nil {
+				logger.Error(err, "Failed to handle runtime configuration issuerRef change")
+			}
 
-		for {
-			// Now loop indefinitely until the main context cancels or we get an event to process.
-			// If the main context cancels, we break out of the outer loop and this function returns.
-			// If we get an event, we first check whether the channel closed. If so, we recreate the watcher by continuing
-			// the outer loop.
-			select {
-			case <-ctx.Done():
-				logger.Info("Received context cancellation, shutting down runtime configuration watcher")
-				watcher.Stop()
-				break LOOP
-
-			case event, open := <-watcher.ResultChan():
-				if !open {
-					logger.Info("Received closed channel from ConfigMap watcher, will recreate")
-					watcher.Stop()
-					continue LOOP
-				}
+		case watch.Bookmark:
+			// Ignore
 
-				switch event.Type {
-				case watch.Deleted:
-					rcw.m.handleRuntimeConfigIssuerDeletion(logger)
-
-				case watch.Added:
-					err := rcw.m.handleRuntimeConfigIssuerChange(logger, event)
-					if err != nil {
-						logger.Error(err, "Failed to handle new runtime configuration for issuerRef")
-					}
-
-				case watch.Modified:
-					err := rcw.m.handleRuntimeConfigIssuerChange(logger, event)
-					if err != nil {
-						logger.Error(err, "Failed to handle runtime configuration issuerRef change")
-					}
-
-				case watch.Bookmark:
-					// Ignore
-
-				case watch.Error:
-					err, ok := event.Object.(error)
-					if !ok {
-						logger.Error(nil, "Got an error event when watching runtime configuration but unable to determine further information")
-					} else {
-						logger.Error(err, "Got an error event when watching runtime configuration")
-					}
-
-				default:
-					logger.Info("Got unknown event for runtime configuration ConfigMap; ignoring", "event-type", string(event.Type))
-				}
+		case watch.Error:
+			err, ok := event.Object.(error)
+			if !ok {
+				logger.Error(nil, "Got an error event when watching runtime configuration but unable to determine further information")
+			} else {
+				logger.Error(err, "Got an error event when watching runtime configuration")
 			}
+
+		default:
+			logger.Info("Got unknown event for runtime configuration ConfigMap; ignoring", "event-type", string(event.Type))
 		}
-	}
+	})
 
-	logger.Info("Stopped runtime configuration watcher")
 	return nil
 }
 
@@ -17,24 +17,115 @@ limitations under the License.
 package certmanager
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
-	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/typed/certmanager/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
+	clientfeatures "k8s.io/client-go/features"
+	clienttesting "k8s.io/client-go/features/testing"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2/ktesting"
 
 	"github.com/cert-manager/istio-csr/test/gen"
 )
 
+// newTestManager returns a manager using the given fake client, with its
+// CertificateRequest informer running.
+func newTestManager(t testing.TB, client *fake.Clientset, opts Options) *manager {
+	dummyIssuerRef := cmmeta.IssuerReference{
+		Name:  "dummy",
+		Kind:  "Issuer",
+		Group: "cert-manager.io",
+	}
+
+	m := &manager{
+		certManagerClient: client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace),
+		crInformer:        newCertificateRequestInformer(client, gen.DefaultTestNamespace),
+
+		originalIssuerRef: &dummyIssuerRef,
+		activeIssuerRef:   &dummyIssuerRef,
+
+		log:  ktesting.NewLogger(t, ktesting.DefaultConfig),
+		opts: opts,
+	}
+
+	if _, err := m.crInformer.AddEventHandler(m.waiters.eventHandler()); err != nil {
+		t.Fatal(err)
+	}
+
+	go m.crInformer.RunWithContext(t.Context())
+	if !cache.WaitForCacheSync(t.Context().Done(), m.crInformer.HasSynced) {
+		t.Fatal("failed to wait for CertificateRequest informer to sync")
+	}
+
+	return m
+}
+
+// waitForWaiter blocks until a caller is waiting on the named
+// CertificateRequest.
+func waitForWaiter(t testing.TB, m *manager, name string) {
+	waitForWaiters(t, m, name, 1)
+}
+
+// waitForWaiters blocks until at least n callers are waiting on the named
+// CertificateRequest.
+func waitForWaiters(t testing.TB, m *manager, name string, n int) {
+	for {
+		m.waiters.lock.Lock()
+		waiting := len(m.waiters.waiters[name])
+		m.waiters.lock.Unlock()
+		if waiting >= n {
+			return
+		}
+
+		select {
+		case <-t.Context().Done():
+			t.Fatal("timed out waiting for CertificateRequest waiter")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// nameCertificateRequests sets the name of created CertificateRequests, since
+// the fake client does not support generateName.
+func nameCertificateRequests(client *fake.Clientset, name func() string) {
+	client.PrependReactor("create", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+		cr := action.(coretesting.CreateAction).GetObject().(*cmapi.CertificateRequest)
+		if len(cr.Name) == 0 {
+			cr.Name = name()
+		}
+		return false, nil, nil
+	})
+}
+
 func Test_Sign(t *testing.T) {
+	denied := gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+		Type:   cmapi.CertificateRequestConditionDenied,
+		Status: cmmeta.ConditionTrue,
+	})
+	failed := gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+		Type:   cmapi.CertificateRequestConditionReady,
+		Status: cmmeta.ConditionFalse,
+		Reason: cmapi.CertificateRequestReasonFailed,
+	})
+	signed := func(cr *cmapi.CertificateRequest) {
+		gen.SetCertificateRequestCertificate([]byte("signed-cert"))(cr)
+		gen.SetCertificateRequestCA([]byte("ca"))(cr)
+	}
+
 	tests := map[string]struct {
-		client      func() *fake.Clientset
+		update      gen.CertificateRequestModifier
 		preserveCRs bool
 
 		expBundle Bundle
@@ -42,25 +133,7 @@ func Test_Sign(t *testing.T) {
 		expErr    bool
 	}{
 		"preserveCRs=true if request is denied, return error": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionDenied,
-								Status: cmmeta.ConditionTrue,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      denied,
 			preserveCRs: true,
 
 			expObject: true,
@@ -69,25 +142,7 @@ func Test_Sign(t *testing.T) {
 		},
 
 		"preserveCRs=false if request is denied, return error and delete object": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionDenied,
-								Status: cmmeta.ConditionTrue,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      denied,
 			preserveCRs: false,
 
 			expObject: false,
@@ -96,26 +151,7 @@ func Test_Sign(t *testing.T) {
 		},
 
 		"preserveCRs=true if request is failed, return error": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionReady,
-								Status: cmmeta.ConditionFalse,
-								Reason: cmapi.CertificateRequestReasonFailed,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      failed,
 			preserveCRs: true,
 
 			expObject: true,
@@ -124,26 +160,7 @@ func Test_Sign(t *testing.T) {
 		},
 
 		"preserveCRs=false if request is failed, return error and delete object": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionReady,
-								Status: cmmeta.ConditionFalse,
-								Reason: cmapi.CertificateRequestReasonFailed,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      failed,
 			preserveCRs: false,
 
 			expObject: false,
@@ -152,57 +169,35 @@ func Test_Sign(t *testing.T) {
 		},
 
 		"preserveCRs=true if request is signed, return bundle": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.SetCertificateRequestCertificate([]byte("signed-cert")),
-							gen.SetCertificateRequestCA([]byte("ca")),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      signed,
 			preserveCRs: true,
 
 			expObject: true,
 			expBundle: Bundle{
 				Certificate: []byte("signed-cert"),
 				CA:          []byte("ca"),
+				IssuerRef: cmmeta.IssuerReference{
+					Name:  "dummy",
+					Kind:  "Issuer",
+					Group: "cert-manager.io",
+				},
 			},
 			expErr: false,
 		},
 
 		"preserveCRs=false if request is signed, return bundle and delete object": {
-			client: func() *fake.Clientset {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.SetCertificateRequestCertificate([]byte("signed-cert")),
-							gen.SetCertificateRequestCA([]byte("ca")),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client
-			},
+			update:      signed,
 			preserveCRs: false,
 
 			expObject: false,
 			expBundle: Bundle{
 				Certificate: []byte("signed-cert"),
 				CA:          []byte("ca"),
+				IssuerRef: cmmeta.IssuerReference{
+					Name:  "dummy",
+					Kind:  "Issuer",
+					Group: "cert-manager.io",
+				},
 			},
 			expErr: false,
 		},
@@ -210,35 +205,38 @@ func Test_Sign(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			client := test.client()
+			clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
 
-			dummyIssuerRef := cmmeta.IssuerReference{
-				Name:  "dummy",
-				Kind:  "Issuer",
-				Group: "cert-manager.io",
-			}
+			client := fake.NewClientset()
+			nameCertificateRequests(client, func() string { return "test-cr" })
 
-			m := &manager{
-				certManagerClient: client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace),
+			m := newTestManager(t, client, Options{
+				PreserveCertificateRequests: test.preserveCRs,
+			})
 
-				originalIssuerRef: &dummyIssuerRef,
-				activeIssuerRef:   &dummyIssuerRef,
+			go func() {
+				waitForWaiter(t, m, "test-cr")
+				cr, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).Get(t.Context(), "test-cr", metav1.GetOptions{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
 
-				log: ktesting.NewLogger(t, ktesting.DefaultConfig),
-				opts: Options{
-					PreserveCertificateRequests: test.preserveCRs,
-				},
-			}
+				if !managedByLabelSelector().Matches(labels.Set(cr.Labels)) {
+					t.Errorf("expected created CertificateRequest to have managed-by label, got=%v", cr.Labels)
+				}
 
-			bundle, err := m.Sign(t.Context(), "", nil, 0, nil)
+				if _, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).UpdateStatus(t.Context(),
+					gen.CertificateRequestFrom(cr, test.update), metav1.UpdateOptions{}); err != nil {
+					t.Error(err)
+				}
+			}()
+
+			bundle, err := m.Sign(t.Context(), "", nil, 0, nil, nil)
 			if (err != nil) != test.expErr {
 				t.Errorf("unexpected error, exp=%t got=%v", test.expErr, err)
 			}
 
-			if test.expErr {
-				return
-			}
-
 			// Wait for delete go routine to finish
 			time.Sleep(time.Millisecond * 50)
 
@@ -262,32 +260,32 @@ func Test_Sign(t *testing.T) {
 }
 
 func Test_waitForCertificateRequest(t *testing.T) {
+	managed := gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue)
+
 	tests := map[string]struct {
-		client func() cmclient.CertificateRequestInterface
+		objects []runtime.Object
+		events  func(*watch.FakeWatcher)
 
 		expResult *cmapi.CertificateRequest
 		expErr    bool
 	}{
-		"if the request does not exist, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				return fake.NewClientset().CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
-			},
+		"if the request does not exist, should return with error once the context is done": {
+			objects: nil,
 
-			expResult: nil,
+			expResult: gen.CertificateRequest("test-cr"),
 			expErr:    true,
 		},
 		"if the request is denied, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				return fake.NewClientset(
-					gen.CertificateRequest("test-cr",
-						gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-							Type:   cmapi.CertificateRequestConditionDenied,
-							Status: cmmeta.ConditionTrue,
-						}),
-					)).CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed,
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+					}),
+				),
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
 					Type:   cmapi.CertificateRequestConditionDenied,
 					Status: cmmeta.ConditionTrue,
@@ -296,18 +294,17 @@ func Test_waitForCertificateRequest(t *testing.T) {
 		},
 
 		"if the request has failed, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				return fake.NewClientset(
-					gen.CertificateRequest("test-cr",
-						gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-							Type:   cmapi.CertificateRequestConditionReady,
-							Status: cmmeta.ConditionFalse,
-							Reason: cmapi.CertificateRequestReasonFailed,
-						}),
-					)).CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed,
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				),
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
 					Type:   cmapi.CertificateRequestConditionReady,
 					Status: cmmeta.ConditionFalse,
@@ -317,42 +314,33 @@ func Test_waitForCertificateRequest(t *testing.T) {
 		},
 
 		"if the request has been signed, should return with no error": {
-			client: func() cmclient.CertificateRequestInterface {
-				return fake.NewClientset(
-					gen.CertificateRequest("test-cr",
-						gen.SetCertificateRequestCertificate([]byte("signed-cert")),
-					),
-				).CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				),
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
 			),
 			expErr: false,
 		},
 
 		"if the request is not signed then receives denied update, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionDenied,
-								Status: cmmeta.ConditionTrue,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Modify(gen.CertificateRequest("test-cr", managed))
+				watcher.Modify(gen.CertificateRequest("test-cr", managed,
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+					}),
+				))
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
 					Type:   cmapi.CertificateRequestConditionDenied,
 					Status: cmmeta.ConditionTrue,
@@ -361,28 +349,21 @@ func Test_waitForCertificateRequest(t *testing.T) {
 			expErr: true,
 		},
 		"if the request is not signed then receives failed update, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionReady,
-								Status: cmmeta.ConditionFalse,
-								Reason: cmapi.CertificateRequestReasonFailed,
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Modify(gen.CertificateRequest("test-cr", managed))
+				watcher.Modify(gen.CertificateRequest("test-cr", managed,
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				))
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
 					Type:   cmapi.CertificateRequestConditionReady,
 					Status: cmmeta.ConditionFalse,
@@ -392,63 +373,99 @@ func Test_waitForCertificateRequest(t *testing.T) {
 			expErr: true,
 		},
 		"if the request is not signed then receives signed update, should return with no error": {
-			client: func() cmclient.CertificateRequestInterface {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Modify(gen.CertificateRequest("test-cr",
-							gen.SetCertificateRequestCertificate([]byte("signed-cert")),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Modify(gen.CertificateRequest("test-cr", managed))
+				watcher.Modify(gen.CertificateRequest("test-cr", managed,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				))
 			},
 
-			expResult: gen.CertificateRequest("test-cr",
+			expResult: gen.CertificateRequest("test-cr", managed,
 				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
 			),
 			expErr: false,
 		},
 		"if the request is not signed then gets deleted, should return with error": {
-			client: func() cmclient.CertificateRequestInterface {
-				client := fake.NewClientset(
-					gen.CertificateRequest("test-cr"),
-				)
-				client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
-					watcher := watch.NewFake()
-					go func() {
-						watcher.Modify(gen.CertificateRequest("test-cr"))
-						watcher.Delete(gen.CertificateRequest("test-cr",
-							gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
-								Type:   cmapi.CertificateRequestConditionReady,
-								Status: cmmeta.ConditionFalse,
-								Reason: "random condition",
-							}),
-						))
-					}()
-					return true, watcher, nil
-				})
-				return client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace)
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Modify(gen.CertificateRequest("test-cr", managed))
+				watcher.Delete(gen.CertificateRequest("test-cr", managed,
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: "random condition",
+					}),
+				))
 			},
 
-			expResult: gen.CertificateRequest("test-cr"),
+			expResult: gen.CertificateRequest("test-cr", managed),
 			expErr:    true,
 		},
+		"if the request is deleted once signed, should return with no error": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Delete(gen.CertificateRequest("test-cr", managed,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				))
+			},
+
+			expResult: gen.CertificateRequest("test-cr", managed,
+				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+			),
+			expErr: false,
+		},
+		"if an unrelated request is signed, should continue waiting": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("test-cr", managed),
+				gen.CertificateRequest("other-cr", managed),
+			},
+			events: func(watcher *watch.FakeWatcher) {
+				watcher.Modify(gen.CertificateRequest("other-cr", managed,
+					gen.SetCertificateRequestCertificate([]byte("other-cert")),
+				))
+				watcher.Modify(gen.CertificateRequest("test-cr", managed,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				))
+			},
+
+			expResult: gen.CertificateRequest("test-cr", managed,
+				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+			),
+			expErr: false,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			m := &manager{
-				certManagerClient: test.client(),
+			clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+			client := fake.NewClientset(test.objects...)
+			watcher := watch.NewFake()
+			client.PrependWatchReactor("certificaterequests", func(coretesting.Action) (bool, watch.Interface, error) {
+				return true, watcher, nil
+			})
+
+			m := newTestManager(t, client, Options{})
+
+			if test.events != nil {
+				go func() {
+					waitForWaiter(t, m, "test-cr")
+					test.events(watcher)
+				}()
 			}
 
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
 			log := ktesting.NewLogger(t, ktesting.DefaultConfig)
-			cr, err := m.waitForCertificateRequest(t.Context(), log, gen.CertificateRequest("test-cr"))
+			cr, err := m.waitForCertificateRequest(ctx, log, gen.CertificateRequest("test-cr"))
 			if (err != nil) != test.expErr {
 				t.Errorf("unexpected error, exp=%t got=%v", test.expErr, err)
 			}
@@ -459,3 +476,65 @@ func Test_waitForCertificateRequest(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkSign measures the number of API calls made per signed request.
+// Each request results in a single create and delete call, since all
+// requests share one CertificateRequest informer. Previously each request
+// also made a watch and get call.
+func BenchmarkSign(b *testing.B) {
+	clienttesting.SetFeatureDuringTest(b, clientfeatures.WatchListClient, false)
+
+	var names, calls, deletes atomic.Int64
+
+	client := fake.NewClientset()
+	nameCertificateRequests(client, func() string { return fmt.Sprintf("test-cr-%d", names.Add(1)) })
+	client.PrependReactor("*", "*", func(action coretesting.Action) (bool, runtime.Object, error) {
+		calls.Add(1)
+		if action.GetVerb() == "delete" {
+			deletes.Add(1)
+		}
+		return false, nil, nil
+	})
+	client.PrependWatchReactor("*", func(coretesting.Action) (bool, watch.Interface, error) {
+		calls.Add(1)
+		return false, nil, nil
+	})
+
+	m := newTestManager(b, client, Options{})
+
+	// Sign every created request, acting as cert-manager. The tracker is used
+	// directly so that these calls are not counted.
+	gvr := cmapi.SchemeGroupVersion.WithResource("certificaterequests")
+	if _, err := m.crInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			cr := obj.(*cmapi.CertificateRequest)
+			go func() {
+				if err := client.Tracker().Update(gvr, gen.CertificateRequestFrom(cr,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				), cr.Namespace); err != nil {
+					b.Error(err)
+				}
+			}()
+		},
+	}); err != nil {
+		b.Fatal(err)
+	}
+
+	calls.Store(0)
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := m.Sign(b.Context(), "", nil, 0, nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.StopTimer()
+
+	// Wait for the asynchronous deletes to complete.
+	for deletes.Load() < names.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.ReportMetric(float64(calls.Load())/float64(names.Load()), "api-calls/op")
+}
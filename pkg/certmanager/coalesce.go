@@ -0,0 +1,133 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// coalesceCacheSize is the maximum number of signed results held for
+	// reuse by identical requests.
+	coalesceCacheSize = 4096
+
+	coalescedInFlight = "in_flight"
+	coalescedCached   = "cached"
+)
+
+var (
+	metricCoalescedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "coalesced_certificate_requests",
+			Help:      "Total number of sign requests which shared the CertificateRequest of an identical request, by whether it was in flight or recently signed.",
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricCoalescedRequests)
+}
+
+// RequestCoalescingOptions configures sharing a single CertificateRequest,
+// and its result, between identical sign requests. Requests are identical if
+// they are for the same identities, CSR, duration, usages and issuer.
+type RequestCoalescingOptions struct {
+	// Enabled enables request coalescing.
+	Enabled bool
+
+	// CacheTTL is the duration the result of a signed request is reused for
+	// identical requests. A value of 0 only coalesces in-flight requests.
+	CacheTTL time.Duration
+
+	// Timeout is the maximum duration a shared request is waited on. Shared
+	// requests are not cancelled when their caller goes away, so that retries
+	// may still share it.
+	Timeout time.Duration
+}
+
+// requestCoalescer coalesces identical sign requests, so that they share a
+// single CertificateRequest.
+type requestCoalescer struct {
+	opts  RequestCoalescingOptions
+	group singleflight.Group
+	cache *cache.LRUExpireCache
+}
+
+// newRequestCoalescer returns a new requestCoalescer. Returns nil if request
+// coalescing is not enabled.
+func newRequestCoalescer(opts RequestCoalescingOptions) *requestCoalescer {
+	if !opts.Enabled {
+		return nil
+	}
+
+	return &requestCoalescer{
+		opts:  opts,
+		cache: cache.NewLRUExpireCache(coalesceCacheSize),
+	}
+}
+
+// do returns the result of sign for the key. If an identical request is in
+// flight, its result is shared. If an identical request was recently signed,
+// its result is reused. sign is called with a context which is not cancelled
+// with ctx, but is bounded by the coalescing timeout.
+func (c *requestCoalescer) do(ctx context.Context, key string, sign func(context.Context) (Bundle, error)) (Bundle, error) {
+	if cached, ok := c.cache.Get(key); ok {
+		metricCoalescedRequests.WithLabelValues(coalescedCached).Inc()
+		return cached.(Bundle), nil
+	}
+
+	var leader bool
+	results := c.group.DoChan(key, func() (any, error) {
+		leader = true
+
+		// The request may have been signed since the cache was checked.
+		if cached, ok := c.cache.Get(key); ok {
+			return cached, nil
+		}
+
+		signCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.Timeout)
+		defer cancel()
+
+		bundle, err := sign(signCtx)
+		if err == nil && c.opts.CacheTTL > 0 {
+			c.cache.Add(key, bundle, c.opts.CacheTTL)
+		}
+		return bundle, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return Bundle{}, ctx.Err()
+
+	case result := <-results:
+		if !leader {
+			metricCoalescedRequests.WithLabelValues(coalescedInFlight).Inc()
+		}
+		if result.Err != nil {
+			return Bundle{}, result.Err
+		}
+		return result.Val.(Bundle), nil
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfeatures "k8s.io/client-go/features"
+	clienttesting "k8s.io/client-go/features/testing"
+
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_newRequestCoalescer(t *testing.T) {
+	if c := newRequestCoalescer(RequestCoalescingOptions{Enabled: false}); c != nil {
+		t.Errorf("expected nil coalescer when disabled, got=%v", c)
+	}
+	if c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true}); c == nil {
+		t.Error("expected coalescer when enabled, got=nil")
+	}
+}
+
+func Test_requestCoalescer(t *testing.T) {
+	bundle := Bundle{Certificate: []byte("signed-cert"), CA: []byte("ca")}
+
+	t.Run("concurrent identical requests are signed once", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute})
+		inFlight := testutil.ToFloat64(metricCoalescedRequests.WithLabelValues(coalescedInFlight))
+
+		var calls atomic.Int32
+		release := make(chan struct{})
+		sign := func(context.Context) (Bundle, error) {
+			calls.Add(1)
+			<-release
+			return bundle, nil
+		}
+
+		const callers = 5
+		var wg sync.WaitGroup
+		results := make([]Bundle, callers)
+		errs := make([]error, callers)
+		for i := range callers {
+			wg.Go(func() {
+				results[i], errs[i] = c.do(t.Context(), "key", sign)
+			})
+		}
+
+		// Wait for the first call to be signing before releasing, and give the
+		// remaining callers time to join it.
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected a single sign call, got=%d", got)
+		}
+		for i := range callers {
+			if errs[i] != nil {
+				t.Errorf("unexpected error: %v", errs[i])
+			}
+			if !apiequality.Semantic.DeepEqual(results[i], bundle) {
+				t.Errorf("unexpected bundle, exp=%v got=%v", bundle, results[i])
+			}
+		}
+		if got := testutil.ToFloat64(metricCoalescedRequests.WithLabelValues(coalescedInFlight)) - inFlight; got != callers-1 {
+			t.Errorf("unexpected in_flight coalesced requests metric, exp=%d got=%v", callers-1, got)
+		}
+	})
+
+	t.Run("recently signed results are reused within the cache ttl", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute, CacheTTL: time.Minute})
+		cached := testutil.ToFloat64(metricCoalescedRequests.WithLabelValues(coalescedCached))
+
+		var calls atomic.Int32
+		sign := func(context.Context) (Bundle, error) {
+			calls.Add(1)
+			return bundle, nil
+		}
+
+		for range 3 {
+			got, err := c.do(t.Context(), "key", sign)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !apiequality.Semantic.DeepEqual(got, bundle) {
+				t.Errorf("unexpected bundle, exp=%v got=%v", bundle, got)
+			}
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected a single sign call, got=%d", got)
+		}
+		if got := testutil.ToFloat64(metricCoalescedRequests.WithLabelValues(coalescedCached)) - cached; got != 2 {
+			t.Errorf("unexpected cached coalesced requests metric, exp=2 got=%v", got)
+		}
+
+		if _, err := c.do(t.Context(), "other-key", sign); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected a different request to be signed, got=%d sign calls", got)
+		}
+	})
+
+	t.Run("results are not reused if the cache is disabled", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute})
+
+		var calls atomic.Int32
+		sign := func(context.Context) (Bundle, error) {
+			calls.Add(1)
+			return bundle, nil
+		}
+
+		for range 2 {
+			if _, err := c.do(t.Context(), "key", sign); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected each request to be signed, got=%d sign calls", got)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute, CacheTTL: time.Minute})
+
+		var calls atomic.Int32
+		sign := func(context.Context) (Bundle, error) {
+			if calls.Add(1) == 1 {
+				return Bundle{}, errors.New("denied")
+			}
+			return bundle, nil
+		}
+
+		if _, err := c.do(t.Context(), "key", sign); err == nil {
+			t.Fatal("expected error, got=nil")
+		}
+		got, err := c.do(t.Context(), "key", sign)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !apiequality.Semantic.DeepEqual(got, bundle) {
+			t.Errorf("unexpected bundle, exp=%v got=%v", bundle, got)
+		}
+	})
+
+	t.Run("a cancelled caller does not cancel the shared request", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute})
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		sign := func(ctx context.Context) (Bundle, error) {
+			close(started)
+			select {
+			case <-release:
+				return bundle, nil
+			case <-ctx.Done():
+				return Bundle{}, ctx.Err()
+			}
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := c.do(ctx, "key", sign)
+			errCh <- err
+		}()
+
+		<-started
+		cancel()
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected cancelled caller to return context.Canceled, got=%v", err)
+		}
+
+		// A retry joins the request which is still in flight.
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		got, err := c.do(t.Context(), "key", func(context.Context) (Bundle, error) {
+			t.Error("unexpected sign call for retried request")
+			return Bundle{}, nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !apiequality.Semantic.DeepEqual(got, bundle) {
+			t.Errorf("unexpected bundle, exp=%v got=%v", bundle, got)
+		}
+	})
+
+	t.Run("shared requests are bounded by the timeout", func(t *testing.T) {
+		c := newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: 10 * time.Millisecond})
+
+		_, err := c.do(t.Context(), "key", func(ctx context.Context) (Bundle, error) {
+			<-ctx.Done()
+			return Bundle{}, ctx.Err()
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got=%v", err)
+		}
+	})
+}
+
+func Test_SignCoalescing(t *testing.T) {
+	clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+	client := fake.NewClientset()
+	var created atomic.Int32
+	nameCertificateRequests(client, func() string {
+		created.Add(1)
+		return "test-cr"
+	})
+
+	m := newTestManager(t, client, Options{})
+	m.coalescer = newRequestCoalescer(RequestCoalescingOptions{Enabled: true, Timeout: time.Minute, CacheTTL: time.Minute})
+
+	go func() {
+		waitForWaiter(t, m, "test-cr")
+		// Give the retried request time to join the pending request.
+		time.Sleep(50 * time.Millisecond)
+
+		cr, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).Get(t.Context(), "test-cr", metav1.GetOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if _, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).UpdateStatus(t.Context(),
+			gen.CertificateRequestFrom(cr,
+				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				gen.SetCertificateRequestCA([]byte("ca")),
+			), metav1.UpdateOptions{}); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	var acquired, held atomic.Int32
+	slotCtx := WithSigningSlots(t.Context(), func(context.Context) (func(), error) {
+		acquired.Add(1)
+		held.Add(1)
+		return func() { held.Add(-1) }, nil
+	})
+
+	// The first request times out before the CertificateRequest is signed, as
+	// an istio agent would, and is retried.
+	ctx, cancel := context.WithTimeout(slotCtx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Sign(ctx, "spiffe://foo", []byte("csr"), time.Hour, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected first request to time out, got=%v", err)
+	}
+
+	// The signing slot is held by the shared request, which is still signing.
+	if got := held.Load(); got != 1 {
+		t.Errorf("expected the signing slot to be held after the caller went away, got=%d held slots", got)
+	}
+
+	bundle, err := m.Sign(slotCtx, "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(bundle.Certificate) != "signed-cert" {
+		t.Errorf("unexpected certificate, got=%q", bundle.Certificate)
+	}
+
+	// A further retry reuses the recently signed result.
+	if _, err := m.Sign(slotCtx, "spiffe://foo", []byte("csr"), time.Hour, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := created.Load(); got != 1 {
+		t.Errorf("expected a single CertificateRequest to be created, got=%d", got)
+	}
+	if got := acquired.Load(); got != 1 {
+		t.Errorf("expected a single signing slot to be acquired, got=%d", got)
+	}
+	if got := held.Load(); got != 0 {
+		t.Errorf("expected the signing slot to be released once signed, got=%d held slots", got)
+	}
+}
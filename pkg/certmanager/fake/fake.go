@@ -21,11 +21,12 @@ import (
 	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 
 	"github.com/cert-manager/istio-csr/pkg/certmanager"
 )
 
-type signFn func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage) (certmanager.Bundle, error)
+type signFn func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error)
 
 type Fake struct {
 	sign signFn
@@ -33,7 +34,7 @@ type Fake struct {
 
 func New() *Fake {
 	return &Fake{
-		sign: func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage) (certmanager.Bundle, error) {
+		sign: func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 			return certmanager.Bundle{}, nil
 		},
 	}
@@ -44,6 +45,12 @@ func (f *Fake) WithSign(fn signFn) *Fake {
 	return f
 }
 
-func (f *Fake) Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage) (certmanager.Bundle, error) {
-	return f.sign(ctx, identities, csrPEM, duration, usages)
+func (f *Fake) Sign(ctx context.Context, identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+	release, err := certmanager.AcquireSigningSlot(ctx)
+	if err != nil {
+		return certmanager.Bundle{}, err
+	}
+	defer release()
+
+	return f.sign(ctx, identities, csrPEM, duration, usages, issuerRef)
 }
@@ -0,0 +1,304 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/typed/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	metricRetainedCertificateRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "retained_certificate_requests",
+			Help:      "Number of CertificateRequests created by istio-csr which remained after the last garbage collection.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricRetainedCertificateRequests)
+}
+
+const (
+	// gcListPageSize is the maximum number of CertificateRequests listed per
+	// request during garbage collection.
+	gcListPageSize = 500
+
+	// gcUnlabelledMaxPages is the maximum number of pages of unlabelled
+	// CertificateRequests listed per garbage collection.
+	gcUnlabelledMaxPages = 10
+)
+
+// GarbageCollectionOptions configures the periodic deletion of
+// CertificateRequests created by istio-csr. CertificateRequests are deleted
+// if they have completed and are older than the retention, or are not among
+// the most recent completed CertificateRequests of their identity.
+type GarbageCollectionOptions struct {
+	// Enabled enables garbage collection.
+	Enabled bool
+
+	// Interval is the duration between garbage collections.
+	Interval time.Duration
+
+	// Retention is the age after which a completed CertificateRequest is
+	// deleted. A value of 0 disables deleting by age.
+	Retention time.Duration
+
+	// RetainPerIdentity is the number of completed CertificateRequests kept
+	// for each identity. A value of 0 disables deleting by count.
+	RetainPerIdentity int
+
+	// CollectUnlabelled also collects CertificateRequests created by earlier
+	// versions of istio-csr, which do not have the managed-by label. These
+	// cannot be selected by label, so every CertificateRequest without the
+	// label is listed. At most gcUnlabelledMaxPages pages are listed per
+	// collection, each resuming from where the previous stopped.
+	CollectUnlabelled bool
+}
+
+// GarbageCollector is a wrapper around ctrlmgr.Runnable for deleting
+// CertificateRequests which have been preserved beyond their retention, or
+// leaked by a replica exiting before it could delete them.
+type GarbageCollector struct {
+	log    logr.Logger
+	client cmclient.CertificateRequestInterface
+	clock  clock.PassiveClock
+	opts   GarbageCollectionOptions
+
+	// unlabelledContinue is the continue token of the next page of
+	// unlabelled CertificateRequests to collect.
+	unlabelledContinue string
+}
+
+// GarbageCollector returns a runnable which periodically deletes
+// CertificateRequests created by istio-csr, according to the garbage
+// collection options.
+func (m *manager) GarbageCollector() ctrlmgr.Runnable {
+	return &GarbageCollector{
+		log:    m.log.WithName("garbage-collector"),
+		client: m.certManagerClient,
+		clock:  clock.RealClock{},
+		opts:   m.opts.GarbageCollection,
+	}
+}
+
+// NeedLeaderElection returns true, since only a single replica needs to
+// delete CertificateRequests.
+func (g *GarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs garbage collection every interval until the context is
+// cancelled.
+func (g *GarbageCollector) Start(ctx context.Context) error {
+	g.log.Info("starting CertificateRequest garbage collector", "interval", g.opts.Interval, "retention", g.opts.Retention, "retain-per-identity", g.opts.RetainPerIdentity, "collect-unlabelled", g.opts.CollectUnlabelled)
+
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.collect(ctx); err != nil {
+			g.log.Error(err, "failed to garbage collect CertificateRequests")
+		}
+	}, g.opts.Interval, 0.1, true)
+
+	return nil
+}
+
+// collect deletes the CertificateRequests created by istio-csr which have
+// completed and are older than the retention, or are not among the most
+// recent completed CertificateRequests of their identity.
+func (g *GarbageCollector) collect(ctx context.Context) error {
+	now := g.clock.Now()
+
+	var (
+		retained  int
+		expired   []*cmapi.CertificateRequest
+		completed = make(map[string][]*cmapi.CertificateRequest)
+	)
+	add := func(cr *cmapi.CertificateRequest) {
+		if !createdByIstioCSR(cr) || cr.DeletionTimestamp != nil {
+			return
+		}
+
+		// Requests which have not completed may still be waited on.
+		if !certificateRequestCompleted(cr) {
+			retained++
+			return
+		}
+
+		if g.opts.Retention > 0 && now.Sub(cr.CreationTimestamp.Time) > g.opts.Retention {
+			expired = append(expired, cr)
+			return
+		}
+
+		retained++
+		identity := cr.Annotations[identityAnnotation]
+		completed[identity] = append(completed[identity], cr)
+	}
+
+	if _, err := g.list(ctx, managedByLabelSelector(), "", 0, add); err != nil {
+		return err
+	}
+
+	if g.opts.CollectUnlabelled {
+		if err := g.listUnlabelled(ctx, add); err != nil {
+			return err
+		}
+	}
+
+	if g.opts.RetainPerIdentity > 0 {
+		for _, crs := range completed {
+			if len(crs) <= g.opts.RetainPerIdentity {
+				continue
+			}
+
+			// Keep the most recently created requests.
+			slices.SortFunc(crs, func(a, b *cmapi.CertificateRequest) int {
+				return cmp.Or(
+					b.CreationTimestamp.Compare(a.CreationTimestamp.Time),
+					strings.Compare(b.Name, a.Name),
+				)
+			})
+
+			expired = append(expired, crs[g.opts.RetainPerIdentity:]...)
+			retained -= len(crs) - g.opts.RetainPerIdentity
+		}
+	}
+
+	var errs []error
+	for _, cr := range expired {
+		if err := g.delete(ctx, cr); err != nil {
+			retained++
+			errs = append(errs, err)
+			continue
+		}
+
+		g.log.V(2).Info("deleted CertificateRequest", "name", cr.Name, "identity", cr.Annotations[identityAnnotation], "age", now.Sub(cr.CreationTimestamp.Time))
+	}
+
+	metricRetainedCertificateRequests.Set(float64(retained))
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// listUnlabelled calls fn for the next pages of CertificateRequests without
+// the managed-by label, which may have been created by earlier versions of
+// istio-csr. Listing resumes from the previous collection, and restarts once
+// all have been listed or the continue token has expired.
+func (g *GarbageCollector) listUnlabelled(ctx context.Context, fn func(*cmapi.CertificateRequest)) error {
+	unlabelled, err := labels.NewRequirement(managedByLabelKey, selection.NotEquals, []string{managedByLabelValue})
+	if err != nil {
+		return fmt.Errorf("failed to build label selector: %w", err)
+	}
+
+	next, err := g.list(ctx, labels.NewSelector().Add(*unlabelled), g.unlabelledContinue, gcUnlabelledMaxPages, fn)
+	if apierrors.IsResourceExpired(err) {
+		g.log.V(2).Info("unlabelled CertificateRequest list expired, restarting from the beginning")
+		g.unlabelledContinue = ""
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if next == "" && g.unlabelledContinue != "" {
+		g.log.V(2).Info("listed all unlabelled CertificateRequests, restarting from the beginning")
+	}
+	g.unlabelledContinue = next
+
+	return nil
+}
+
+// list calls fn for each CertificateRequest matching the selector, listing
+// them in pages to bound the size of each response. Listing starts from the
+// continue token, and stops after maxPages pages if positive. Returns the
+// continue token of the next page, which is empty if all have been listed.
+func (g *GarbageCollector) list(ctx context.Context, selector labels.Selector, continueToken string, maxPages int, fn func(*cmapi.CertificateRequest)) (string, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: selector.String(),
+		Limit:         gcListPageSize,
+		Continue:      continueToken,
+	}
+
+	for page := 1; ; page++ {
+		list, err := g.client.List(ctx, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list CertificateRequests matching %q: %w", opts.LabelSelector, err)
+		}
+
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+
+		if list.Continue == "" || (maxPages > 0 && page >= maxPages) {
+			return list.Continue, nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// delete deletes the CertificateRequest, retrying on failure. Does not
+// return an error if the CertificateRequest has already been deleted, or
+// replaced by another of the same name.
+func (g *GarbageCollector) delete(ctx context.Context, cr *cmapi.CertificateRequest) error {
+	err := retry.OnError(retry.DefaultBackoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		err := g.client.Delete(ctx, cr.Name, metav1.DeleteOptions{
+			Preconditions: metav1.NewUIDPreconditions(string(cr.UID)),
+		})
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete CertificateRequest %s: %w", cr.Name, err)
+	}
+
+	return nil
+}
+
+// createdByIstioCSR returns true if the CertificateRequest was created by
+// istio-csr. CertificateRequests created by earlier versions of istio-csr,
+// without the managed-by label, are identified by their name and identity
+// annotation.
+func createdByIstioCSR(cr *cmapi.CertificateRequest) bool {
+	if cr.Labels[managedByLabelKey] == managedByLabelValue {
+		return true
+	}
+
+	_, ok := cr.Annotations[identityAnnotation]
+	return ok && strings.HasPrefix(cr.Name, certificateRequestNamePrefix)
+}
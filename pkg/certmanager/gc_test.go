@@ -0,0 +1,382 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+	"k8s.io/klog/v2/ktesting"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_GarbageCollector_collect(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	managed := gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue)
+	signed := gen.SetCertificateRequestCertificate([]byte("signed-cert"))
+	identity := func(id string) gen.CertificateRequestModifier {
+		return gen.AddCertificateRequestAnnotation(identityAnnotation, id)
+	}
+	age := func(age time.Duration) gen.CertificateRequestModifier {
+		return func(cr *cmapi.CertificateRequest) {
+			cr.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		}
+	}
+
+	tests := map[string]struct {
+		objects []runtime.Object
+		opts    GarbageCollectionOptions
+		reactor coretesting.ReactionFunc
+
+		expRemaining []string
+		expRetained  float64
+		expErr       bool
+	}{
+		"completed requests older than the retention should be deleted": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-old", managed, identity("spiffe://foo"), signed, age(2*time.Hour)),
+				gen.CertificateRequest("istio-csr-old-pending", managed, identity("spiffe://foo"), age(2*time.Hour)),
+				gen.CertificateRequest("istio-csr-new", managed, identity("spiffe://foo"), signed, age(time.Minute)),
+				gen.CertificateRequest("istio-csr-new-pending", managed, identity("spiffe://foo"), age(time.Minute)),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour},
+
+			expRemaining: []string{"istio-csr-new", "istio-csr-new-pending", "istio-csr-old-pending"},
+			expRetained:  3,
+		},
+		"failed and denied requests older than the retention should be deleted": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-failed", managed, identity("spiffe://foo"), age(2*time.Hour),
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					})),
+				gen.CertificateRequest("istio-csr-denied", managed, identity("spiffe://foo"), age(2*time.Hour),
+					gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+					})),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour},
+
+			expRemaining: nil,
+			expRetained:  0,
+		},
+		"requests not created by istio-csr should not be deleted": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-legacy", identity("spiffe://foo"), signed, age(2*time.Hour)),
+				gen.CertificateRequest("istio-csr-unrelated", signed, age(2*time.Hour)),
+				gen.CertificateRequest("istiod-1", identity("spiffe://foo"), signed, age(2*time.Hour)),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour, CollectUnlabelled: true},
+
+			expRemaining: []string{"istio-csr-unrelated", "istiod-1"},
+			expRetained:  0,
+		},
+		"if collecting unlabelled requests is disabled, unlabelled requests should not be deleted": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-legacy", identity("spiffe://foo"), signed, age(2*time.Hour)),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour},
+
+			expRemaining: []string{"istio-csr-legacy"},
+			expRetained:  0,
+		},
+		"only the most recent completed requests of each identity should be retained": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-foo-1", managed, identity("spiffe://foo"), signed, age(3*time.Minute)),
+				gen.CertificateRequest("istio-csr-foo-2", managed, identity("spiffe://foo"), signed, age(2*time.Minute)),
+				gen.CertificateRequest("istio-csr-foo-3", managed, identity("spiffe://foo"), signed, age(time.Minute)),
+				gen.CertificateRequest("istio-csr-foo-pending", managed, identity("spiffe://foo"), age(4*time.Minute)),
+				gen.CertificateRequest("istio-csr-bar-1", managed, identity("spiffe://bar"), signed, age(3*time.Minute)),
+			},
+			opts: GarbageCollectionOptions{RetainPerIdentity: 2},
+
+			expRemaining: []string{"istio-csr-bar-1", "istio-csr-foo-2", "istio-csr-foo-3", "istio-csr-foo-pending"},
+			expRetained:  4,
+		},
+		"if retention is disabled, old requests should not be deleted": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-old", managed, identity("spiffe://foo"), signed, age(48*time.Hour)),
+			},
+			opts: GarbageCollectionOptions{RetainPerIdentity: 1},
+
+			expRemaining: []string{"istio-csr-old"},
+			expRetained:  1,
+		},
+		"failed deletes should be retried": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-old", managed, identity("spiffe://foo"), signed, age(2*time.Hour)),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour},
+			reactor: func() coretesting.ReactionFunc {
+				var failed bool
+				return func(coretesting.Action) (bool, runtime.Object, error) {
+					if !failed {
+						failed = true
+						return true, nil, errors.New("transient error")
+					}
+					return false, nil, nil
+				}
+			}(),
+
+			expRemaining: nil,
+			expRetained:  0,
+		},
+		"if deletes keep failing, should return error and count the request as retained": {
+			objects: []runtime.Object{
+				gen.CertificateRequest("istio-csr-old", managed, identity("spiffe://foo"), signed, age(2*time.Hour)),
+			},
+			opts: GarbageCollectionOptions{Retention: time.Hour},
+			reactor: func(coretesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("persistent error")
+			},
+
+			expRemaining: []string{"istio-csr-old"},
+			expRetained:  1,
+			expErr:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewClientset(test.objects...)
+			if test.reactor != nil {
+				client.PrependReactor("delete", "certificaterequests", test.reactor)
+			}
+
+			g := &GarbageCollector{
+				log:    ktesting.NewLogger(t, ktesting.DefaultConfig),
+				client: client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace),
+				clock:  clocktesting.NewFakePassiveClock(now),
+				opts:   test.opts,
+			}
+
+			err := g.collect(t.Context())
+			if (err != nil) != test.expErr {
+				t.Errorf("unexpected error, exp=%t got=%v", test.expErr, err)
+			}
+
+			list, err := g.client.List(t.Context(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var remaining []string
+			for _, cr := range list.Items {
+				remaining = append(remaining, cr.Name)
+			}
+			slices.Sort(remaining)
+
+			if !slices.Equal(remaining, test.expRemaining) {
+				t.Errorf("unexpected remaining CertificateRequests, exp=%v got=%v", test.expRemaining, remaining)
+			}
+
+			if got := testutil.ToFloat64(metricRetainedCertificateRequests); got != test.expRetained {
+				t.Errorf("unexpected retained CertificateRequests metric, exp=%v got=%v", test.expRetained, got)
+			}
+		})
+	}
+}
+
+func Test_GarbageCollector_collectPaginated(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := func(name string) cmapi.CertificateRequest {
+		return *gen.CertificateRequest(name,
+			gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+			gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+			gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+			func(cr *cmapi.CertificateRequest) {
+				cr.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+			},
+		)
+	}
+
+	client := fake.NewClientset()
+
+	var selectors []string
+	client.PrependReactor("list", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+		opts := action.(coretesting.ListActionImpl).GetListOptions()
+		if opts.Limit != gcListPageSize {
+			t.Errorf("expected list limit %d, got=%d", gcListPageSize, opts.Limit)
+		}
+
+		selectors = append(selectors, opts.LabelSelector)
+
+		if opts.LabelSelector != managedByLabelSelector().String() {
+			return true, &cmapi.CertificateRequestList{}, nil
+		}
+
+		switch opts.Continue {
+		case "":
+			return true, &cmapi.CertificateRequestList{
+				ListMeta: metav1.ListMeta{Continue: "page-2"},
+				Items:    []cmapi.CertificateRequest{old("istio-csr-1")},
+			}, nil
+		case "page-2":
+			return true, &cmapi.CertificateRequestList{
+				Items: []cmapi.CertificateRequest{old("istio-csr-2")},
+			}, nil
+		default:
+			return true, nil, fmt.Errorf("unexpected continue token %q", opts.Continue)
+		}
+	})
+
+	g := &GarbageCollector{
+		log:    ktesting.NewLogger(t, ktesting.DefaultConfig),
+		client: client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace),
+		clock:  clocktesting.NewFakePassiveClock(now),
+		opts:   GarbageCollectionOptions{Retention: time.Hour},
+	}
+
+	if err := g.collect(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expSelectors := []string{
+		managedByLabelSelector().String(),
+		managedByLabelSelector().String(),
+	}
+	if !slices.Equal(selectors, expSelectors) {
+		t.Errorf("unexpected list label selectors, exp=%v got=%v", expSelectors, selectors)
+	}
+
+	var deleted []string
+	for _, a := range client.Fake.Actions() {
+		if d, ok := a.(coretesting.DeleteAction); ok {
+			deleted = append(deleted, d.GetName())
+		}
+	}
+	slices.Sort(deleted)
+	if exp := []string{"istio-csr-1", "istio-csr-2"}; !slices.Equal(deleted, exp) {
+		t.Errorf("expected requests from every page to be deleted, exp=%v got=%v", exp, deleted)
+	}
+}
+
+func Test_GarbageCollector_collectUnlabelled(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	unlabelledSelector := managedByLabelKey + "!=" + managedByLabelValue
+
+	// Serve more pages of unlabelled requests than are listed per collection.
+	// Each page holds one legacy request created by istio-csr.
+	const pages = gcUnlabelledMaxPages + 2
+
+	client := fake.NewClientset()
+
+	var (
+		listed  []string
+		expired bool
+	)
+	client.PrependReactor("list", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+		opts := action.(coretesting.ListActionImpl).GetListOptions()
+		if opts.LabelSelector != unlabelledSelector {
+			return true, &cmapi.CertificateRequestList{}, nil
+		}
+
+		if expired && opts.Continue != "" {
+			return true, nil, apierrors.NewResourceExpired("continue token expired")
+		}
+
+		page := 0
+		if opts.Continue != "" {
+			if _, err := fmt.Sscanf(opts.Continue, "page-%d", &page); err != nil {
+				return true, nil, err
+			}
+		}
+
+		name := fmt.Sprintf("istio-csr-legacy-%d", page)
+		listed = append(listed, name)
+
+		list := &cmapi.CertificateRequestList{
+			Items: []cmapi.CertificateRequest{*gen.CertificateRequest(name,
+				gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+				gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+				func(cr *cmapi.CertificateRequest) {
+					cr.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+				},
+			)},
+		}
+		if page+1 < pages {
+			list.Continue = fmt.Sprintf("page-%d", page+1)
+		}
+		return true, list, nil
+	})
+
+	g := &GarbageCollector{
+		log:    ktesting.NewLogger(t, ktesting.DefaultConfig),
+		client: client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace),
+		clock:  clocktesting.NewFakePassiveClock(now),
+		opts:   GarbageCollectionOptions{Retention: time.Hour, CollectUnlabelled: true},
+	}
+
+	t.Log("the first collection lists a bounded number of pages")
+	if err := g.collect(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(listed) != gcUnlabelledMaxPages {
+		t.Errorf("expected %d pages to be listed, got=%d", gcUnlabelledMaxPages, len(listed))
+	}
+	if exp := fmt.Sprintf("page-%d", gcUnlabelledMaxPages); g.unlabelledContinue != exp {
+		t.Errorf("unexpected continue token, exp=%q got=%q", exp, g.unlabelledContinue)
+	}
+
+	t.Log("the next collection resumes from where the previous stopped")
+	listed = nil
+	if err := g.collect(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := []string{fmt.Sprintf("istio-csr-legacy-%d", pages-2), fmt.Sprintf("istio-csr-legacy-%d", pages-1)}; !slices.Equal(listed, exp) {
+		t.Errorf("unexpected listed requests, exp=%v got=%v", exp, listed)
+	}
+	if g.unlabelledContinue != "" {
+		t.Errorf("expected the continue token to be reset once all pages were listed, got=%q", g.unlabelledContinue)
+	}
+
+	var deleted int
+	for _, a := range client.Fake.Actions() {
+		if _, ok := a.(coretesting.DeleteAction); ok {
+			deleted++
+		}
+	}
+	if deleted != pages {
+		t.Errorf("expected a request from every page to be deleted, got=%d deletes", deleted)
+	}
+
+	t.Log("an expired continue token restarts listing from the beginning")
+	g.unlabelledContinue = "page-1"
+	expired = true
+	if err := g.collect(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.unlabelledContinue != "" {
+		t.Errorf("expected the expired continue token to be reset, got=%q", g.unlabelledContinue)
+	}
+}
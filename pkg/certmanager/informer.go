@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"sync"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmversioned "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
+	cminformers "github.com/cert-manager/cert-manager/pkg/client/informers/externalversions/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// managedByLabelKey is the label set on all CertificateRequests created by
+	// istio-csr. The CertificateRequest informer only watches requests with
+	// this label.
+	managedByLabelKey   = "app.kubernetes.io/managed-by"
+	managedByLabelValue = "istio-csr"
+)
+
+// certificateRequestEvent is an update to a CertificateRequest which is being
+// waited on.
+type certificateRequestEvent struct {
+	cr      *cmapi.CertificateRequest
+	deleted bool
+}
+
+// requestWaiters dispatches CertificateRequest events from the shared
+// informer to the callers waiting on those requests, by name.
+type requestWaiters struct {
+	lock    sync.Mutex
+	waiters map[string]map[chan certificateRequestEvent]struct{}
+}
+
+// managedByLabelSelector returns the label selector matching
+// CertificateRequests created by istio-csr.
+func managedByLabelSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{managedByLabelKey: managedByLabelValue})
+}
+
+// newCertificateRequestInformer returns an informer for all CertificateRequests
+// created by istio-csr in the given namespace. Fields which are not needed to
+// determine whether a request has completed are dropped to reduce memory.
+func newCertificateRequestInformer(client cmversioned.Interface, namespace string) cache.SharedIndexInformer {
+	informer := cminformers.NewFilteredCertificateRequestInformer(client, namespace, 0, cache.Indexers{}, func(opts *metav1.ListOptions) {
+		opts.LabelSelector = managedByLabelSelector().String()
+	})
+
+	_ = informer.SetTransform(func(obj any) (any, error) {
+		if cr, ok := obj.(*cmapi.CertificateRequest); ok {
+			cr.ManagedFields = nil
+			cr.Spec.Request = nil
+		}
+		return obj, nil
+	})
+
+	return informer
+}
+
+// register returns a channel which will receive events for the
+// CertificateRequest with the given name. Only the latest event is kept if
+// the caller has not yet received the previous one. Several callers may wait
+// on the same CertificateRequest. The returned func must be called once the
+// caller has finished waiting.
+func (r *requestWaiters) register(name string) (<-chan certificateRequestEvent, func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.waiters == nil {
+		r.waiters = make(map[string]map[chan certificateRequestEvent]struct{})
+	}
+	if r.waiters[name] == nil {
+		r.waiters[name] = make(map[chan certificateRequestEvent]struct{})
+	}
+
+	ch := make(chan certificateRequestEvent, 1)
+	r.waiters[name][ch] = struct{}{}
+	return ch, func() { r.unregister(name, ch) }
+}
+
+// unregister stops dispatching events for the CertificateRequest with the
+// given name to the channel.
+func (r *requestWaiters) unregister(name string, ch chan certificateRequestEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.waiters[name], ch)
+	if len(r.waiters[name]) == 0 {
+		delete(r.waiters, name)
+	}
+}
+
+// dispatch sends the event to the waiters of the CertificateRequest, if any,
+// replacing any event a waiter has not yet received.
+func (r *requestWaiters) dispatch(name string, event certificateRequestEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for ch := range r.waiters[name] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// eventHandler returns the informer event handler which dispatches events to
+// waiters.
+func (r *requestWaiters) eventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if cr, ok := obj.(*cmapi.CertificateRequest); ok {
+				r.dispatch(cr.Name, certificateRequestEvent{cr: cr})
+			}
+		},
+		UpdateFunc: func(_, obj any) {
+			if cr, ok := obj.(*cmapi.CertificateRequest); ok {
+				r.dispatch(cr.Name, certificateRequestEvent{cr: cr})
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cr, ok := obj.(*cmapi.CertificateRequest); ok {
+				r.dispatch(cr.Name, certificateRequestEvent{cr: cr, deleted: true})
+			}
+		},
+	}
+}
+
+// CertificateRequestInformer is a wrapper around ctrlmgr.Runnable for running
+// the shared CertificateRequest informer.
+type CertificateRequestInformer struct {
+	m *manager
+}
+
+// NeedLeaderElection always returns false, since all replicas sign
+// certificates and so must watch their CertificateRequests.
+func (cri *CertificateRequestInformer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the informer until the context is cancelled.
+func (cri *CertificateRequestInformer) Start(ctx context.Context) error {
+	cri.m.crInformer.RunWithContext(ctx)
+	return nil
+}
+
+// CertificateRequestInformer returns a runnable for the informer which is used
+// to wait on created CertificateRequests. The informer must be running for
+// Sign to complete.
+func (m *manager) CertificateRequestInformer() ctrlmgr.Runnable {
+	return &CertificateRequestInformer{
+		m: m,
+	}
+}
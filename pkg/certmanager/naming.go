@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// certificateRequestNamePrefix is the prefix of the names of all
+	// CertificateRequests created by istio-csr.
+	certificateRequestNamePrefix = "istio-csr-"
+
+	// deterministicNameHashLength is the number of hex characters of the
+	// request hash used in deterministic CertificateRequest names.
+	deterministicNameHashLength = 32
+
+	// maxCreateAttempts is the maximum number of attempts to create, or adopt,
+	// a CertificateRequest with a deterministic name, which may be deleted or
+	// replaced concurrently.
+	maxCreateAttempts = 3
+
+	// adoptNotAfterSkew is how much sooner than the requested duration from
+	// now the certificate of an adopted CertificateRequest may expire.
+	adoptNotAfterSkew = time.Minute
+)
+
+// requestHash returns a hash identifying identical sign requests. Requests
+// are identical if they are for the same identities, CSR, duration, usages and
+// issuer.
+func requestHash(identities string, csrPEM []byte, duration time.Duration, usages []cmapi.KeyUsage, issuerRef cmmeta.IssuerReference) string {
+	h := sha256.New()
+	for _, field := range []string{identities, string(csrPEM), duration.String(), issuerRef.Name, issuerRef.Kind, issuerRef.Group} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	for _, usage := range usages {
+		h.Write([]byte(usage))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// deterministicCertificateRequestName returns the name of the
+// CertificateRequest for the given request hash, so that identical requests
+// received by any replica map to the same CertificateRequest.
+func deterministicCertificateRequestName(hash string) string {
+	return certificateRequestNamePrefix + hash[:deterministicNameHashLength]
+}
+
+// createCertificateRequest creates the CertificateRequest. If deterministic
+// names are enabled and an identical request has already been received,
+// possibly by another replica, its existing CertificateRequest is adopted
+// and returned instead. Existing CertificateRequests which were denied or
+// failed are replaced, so that retries of the same CSR are not failed
+// forever. So are those signed long enough ago that their certificate would
+// not be valid for the requested duration. Returns whether the
+// CertificateRequest was adopted.
+func (m *manager) createCertificateRequest(ctx context.Context, cr *cmapi.CertificateRequest) (*cmapi.CertificateRequest, bool, error) {
+	for range maxCreateAttempts {
+		created, err := m.certManagerClient.Create(ctx, cr, metav1.CreateOptions{})
+		if err == nil {
+			return created, false, nil
+		}
+		if !m.opts.DeterministicNames || !apierrors.IsAlreadyExists(err) {
+			return nil, false, err
+		}
+
+		existing, err := m.adoptCertificateRequest(ctx, cr)
+		if apierrors.IsNotFound(err) {
+			// Deleted since it was created, so create it again.
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		if !certificateRequestUnsuccessful(existing) && !certificateRequestExpiring(existing, time.Now()) {
+			return existing, true, nil
+		}
+
+		// The UID precondition ensures that only the unsuccessful or expiring
+		// CertificateRequest is deleted, and not one which has since been
+		// re-created by another replica.
+		err = m.certManagerClient.Delete(ctx, existing.Name, metav1.DeleteOptions{
+			Preconditions: metav1.NewUIDPreconditions(string(existing.UID)),
+		})
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			return nil, false, fmt.Errorf("failed to delete unusable CertificateRequest %s: %w", existing.Name, err)
+		}
+	}
+
+	return nil, false, fmt.Errorf("CertificateRequest %s was replaced concurrently %d times", cr.Name, maxCreateAttempts)
+}
+
+// adoptCertificateRequest returns the existing CertificateRequest with the
+// same name as cr, so that it may be waited on in place of creating a new
+// one. Returns an error if the existing CertificateRequest is not for the same
+// request, or is being deleted. The error from getting the existing
+// CertificateRequest is returned unwrapped, so that a NotFound may be retried.
+func (m *manager) adoptCertificateRequest(ctx context.Context, cr *cmapi.CertificateRequest) (*cmapi.CertificateRequest, error) {
+	existing, err := m.certManagerClient.Get(ctx, cr.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if existing.Labels[managedByLabelKey] != managedByLabelValue ||
+		existing.Annotations[identityAnnotation] != cr.Annotations[identityAnnotation] ||
+		!bytes.Equal(existing.Spec.Request, cr.Spec.Request) ||
+		existing.Spec.IssuerRef != cr.Spec.IssuerRef {
+		return nil, fmt.Errorf("existing CertificateRequest %s does not match the request", cr.Name)
+	}
+
+	if existing.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("existing CertificateRequest %s is being deleted", cr.Name)
+	}
+
+	return existing, nil
+}
+
+// certificateRequestExpiring returns true if the CertificateRequest has been
+// signed, and its certificate expires before the requested duration from now,
+// less adoptNotAfterSkew. A certificate which cannot be parsed is treated as
+// expiring, so that the CertificateRequest is replaced.
+func certificateRequestExpiring(cr *cmapi.CertificateRequest, now time.Time) bool {
+	if len(cr.Status.Certificate) == 0 || cr.Spec.Duration == nil {
+		return false
+	}
+
+	cert, err := pki.DecodeX509CertificateBytes(cr.Status.Certificate)
+	if err != nil {
+		return true
+	}
+
+	return cert.NotAfter.Before(now.Add(cr.Spec.Duration.Duration - adoptNotAfterSkew))
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import (
+	"crypto/x509"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientfeatures "k8s.io/client-go/features"
+	clienttesting "k8s.io/client-go/features/testing"
+	coretesting "k8s.io/client-go/testing"
+
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_requestHash(t *testing.T) {
+	issuerRef := cmmeta.IssuerReference{Name: "ca", Kind: "Issuer", Group: "cert-manager.io"}
+	base := requestHash("spiffe://foo", []byte("csr"), time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef)
+
+	if got := requestHash("spiffe://foo", []byte("csr"), time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef); got != base {
+		t.Errorf("expected identical requests to have the same key, exp=%s got=%s", base, got)
+	}
+
+	tests := map[string]string{
+		"identities":     requestHash("spiffe://bar", []byte("csr"), time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef),
+		"csr":            requestHash("spiffe://foo", []byte("other"), time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef),
+		"duration":       requestHash("spiffe://foo", []byte("csr"), time.Minute, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef),
+		"usages":         requestHash("spiffe://foo", []byte("csr"), time.Hour, []cmapi.KeyUsage{cmapi.UsageServerAuth}, issuerRef),
+		"issuer":         requestHash("spiffe://foo", []byte("csr"), time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, cmmeta.IssuerReference{Name: "other", Kind: "Issuer", Group: "cert-manager.io"}),
+		"field boundary": requestHash("spiffe://foocsr", nil, time.Hour, []cmapi.KeyUsage{cmapi.UsageClientAuth}, issuerRef),
+	}
+
+	for name, got := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got == base {
+				t.Errorf("expected different requests to have different keys, got=%s", got)
+			}
+		})
+	}
+}
+
+func Test_deterministicCertificateRequestName(t *testing.T) {
+	issuerRef := cmmeta.IssuerReference{Name: "ca", Kind: "Issuer", Group: "cert-manager.io"}
+	name := deterministicCertificateRequestName(requestHash("spiffe://foo", []byte("csr"), time.Hour, nil, issuerRef))
+
+	if !strings.HasPrefix(name, certificateRequestNamePrefix) {
+		t.Errorf("expected name to have prefix %q, got=%q", certificateRequestNamePrefix, name)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		t.Errorf("expected name to be a valid object name, got=%q: %v", name, errs)
+	}
+	if other := deterministicCertificateRequestName(requestHash("spiffe://foo", []byte("csr"), time.Hour, nil, issuerRef)); other != name {
+		t.Errorf("expected identical requests to have the same name, exp=%q got=%q", name, other)
+	}
+}
+
+func Test_SignDeterministicNames(t *testing.T) {
+	dummyIssuerRef := cmmeta.IssuerReference{Name: "dummy", Kind: "Issuer", Group: "cert-manager.io"}
+	name := deterministicCertificateRequestName(requestHash("spiffe://foo", []byte("csr"), time.Hour, nil, dummyIssuerRef))
+
+	t.Run("identical requests to different replicas share a CertificateRequest", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		client := fake.NewClientset()
+		replicas := []*manager{
+			newTestManager(t, client, Options{DeterministicNames: true}),
+			newTestManager(t, client, Options{DeterministicNames: true}),
+		}
+
+		go func() {
+			for _, m := range replicas {
+				waitForWaiter(t, m, name)
+			}
+
+			cr, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).Get(t.Context(), name, metav1.GetOptions{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if _, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).UpdateStatus(t.Context(),
+				gen.CertificateRequestFrom(cr,
+					gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+					gen.SetCertificateRequestCA([]byte("ca")),
+				), metav1.UpdateOptions{}); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for _, m := range replicas {
+			wg.Go(func() {
+				bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if string(bundle.Certificate) != "signed-cert" {
+					t.Errorf("unexpected certificate, got=%q", bundle.Certificate)
+				}
+			})
+		}
+		wg.Wait()
+
+		var creates int
+		for _, a := range client.Fake.Actions() {
+			if a.GetVerb() == "create" && a.GetResource().Resource == "certificaterequests" {
+				creates++
+			}
+		}
+		if creates != 2 {
+			t.Errorf("expected both replicas to attempt to create the CertificateRequest, got=%d creates", creates)
+		}
+	})
+
+	t.Run("an existing CertificateRequest for a different request is not adopted", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		existing := gen.CertificateRequest(name,
+			gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://bar"),
+			gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+			gen.SetCertificateRequestCSR([]byte("csr")),
+			gen.SetCertificateRequestIssuerRef(dummyIssuerRef),
+		)
+		client := fake.NewClientset(existing)
+		m := newTestManager(t, client, Options{DeterministicNames: true})
+
+		_, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "does not match the request") {
+			t.Errorf("expected mismatched CertificateRequest error, got=%v", err)
+		}
+	})
+
+	t.Run("without deterministic names an existing CertificateRequest is not adopted", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		client := fake.NewClientset()
+		nameCertificateRequests(client, func() string { return name })
+		m := newTestManager(t, client, Options{})
+
+		if _, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).Create(t.Context(),
+			gen.CertificateRequest(name), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "failed to create CertificateRequest") {
+			t.Errorf("expected create error, got=%v", err)
+		}
+	})
+
+	t.Run("identical concurrent requests to one replica share a CertificateRequest", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		client := fake.NewClientset()
+		m := newTestManager(t, client, Options{DeterministicNames: true})
+
+		go func() {
+			waitForWaiters(t, m, name, 2)
+			signCertificateRequest(t, client, name)
+		}()
+
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Go(func() {
+				bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if string(bundle.Certificate) != "signed-cert" {
+					t.Errorf("unexpected certificate, got=%q", bundle.Certificate)
+				}
+			})
+		}
+		wg.Wait()
+	})
+
+	for condName, condition := range map[string]cmapi.CertificateRequestCondition{
+		"denied": {
+			Type:   cmapi.CertificateRequestConditionDenied,
+			Status: cmmeta.ConditionTrue,
+		},
+		"failed": {
+			Type:   cmapi.CertificateRequestConditionReady,
+			Status: cmmeta.ConditionFalse,
+			Reason: cmapi.CertificateRequestReasonFailed,
+		},
+	} {
+		t.Run("an existing "+condName+" CertificateRequest is re-created", func(t *testing.T) {
+			clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+			existing := gen.CertificateRequest(name,
+				gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+				gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+				gen.SetCertificateRequestCSR([]byte("csr")),
+				gen.SetCertificateRequestIssuerRef(dummyIssuerRef),
+				gen.AddCertificateRequestStatusCondition(condition),
+			)
+			existing.UID = "previous"
+			client := fake.NewClientset(existing)
+			m := newTestManager(t, client, Options{DeterministicNames: true})
+
+			go func() {
+				waitForWaiter(t, m, name)
+				signCertificateRequest(t, client, name)
+			}()
+
+			bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(bundle.Certificate) != "signed-cert" {
+				t.Errorf("unexpected certificate, got=%q", bundle.Certificate)
+			}
+
+			var deletedPrevious bool
+			for _, a := range client.Fake.Actions() {
+				if d, ok := a.(coretesting.DeleteAction); ok {
+					if p := d.GetDeleteOptions().Preconditions; p != nil && p.UID != nil && *p.UID == existing.UID {
+						deletedPrevious = true
+					}
+				}
+			}
+			if !deletedPrevious {
+				t.Errorf("expected the %s CertificateRequest to be deleted with a UID precondition", condName)
+			}
+		})
+	}
+
+	t.Run("an existing CertificateRequest signed too long ago is re-created", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		existing := gen.CertificateRequest(name,
+			gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+			gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+			gen.SetCertificateRequestCSR([]byte("csr")),
+			gen.SetCertificateRequestIssuerRef(dummyIssuerRef),
+			gen.SetCertificateRequestDuration(time.Hour),
+			gen.SetCertificateRequestCertificate(mustCertificate(t, time.Now().Add(10*time.Minute))),
+		)
+		existing.UID = "previous"
+		client := fake.NewClientset(existing)
+		m := newTestManager(t, client, Options{DeterministicNames: true})
+
+		go func() {
+			waitForWaiter(t, m, name)
+			signCertificateRequest(t, client, name)
+		}()
+
+		bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(bundle.Certificate) != "signed-cert" {
+			t.Errorf("expected the CertificateRequest to be re-created and signed, got=%q", bundle.Certificate)
+		}
+	})
+
+	t.Run("an existing recently signed CertificateRequest is adopted", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		cert := mustCertificate(t, time.Now().Add(time.Hour))
+		existing := gen.CertificateRequest(name,
+			gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+			gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+			gen.SetCertificateRequestCSR([]byte("csr")),
+			gen.SetCertificateRequestIssuerRef(dummyIssuerRef),
+			gen.SetCertificateRequestDuration(time.Hour),
+			gen.SetCertificateRequestCertificate(cert),
+			gen.SetCertificateRequestCA([]byte("ca")),
+		)
+		client := fake.NewClientset(existing)
+		m := newTestManager(t, client, Options{DeterministicNames: true})
+
+		bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(bundle.Certificate) != string(cert) {
+			t.Errorf("expected the existing certificate to be returned, got=%q", bundle.Certificate)
+		}
+		for _, a := range client.Fake.Actions() {
+			if a.GetVerb() == "delete" {
+				t.Errorf("unexpected delete of the adopted CertificateRequest")
+			}
+		}
+	})
+
+	t.Run("a CertificateRequest deleted before it is adopted is re-created", func(t *testing.T) {
+		clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+
+		existing := gen.CertificateRequest(name,
+			gen.AddCertificateRequestAnnotation(identityAnnotation, "spiffe://foo"),
+			gen.AddCertificateRequestLabel(managedByLabelKey, managedByLabelValue),
+			gen.SetCertificateRequestCSR([]byte("csr")),
+			gen.SetCertificateRequestIssuerRef(dummyIssuerRef),
+		)
+		existing.UID = "previous"
+		client := fake.NewClientset(existing)
+
+		// Delete the CertificateRequest between the create and get, as if it
+		// had been completed and cleaned up by another replica.
+		var deleted sync.Once
+		client.PrependReactor("get", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+			var handled bool
+			deleted.Do(func() { handled = true })
+			if !handled {
+				return false, nil, nil
+			}
+			if err := client.Tracker().Delete(action.GetResource(), action.GetNamespace(), name); err != nil {
+				return true, nil, err
+			}
+			return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), name)
+		})
+
+		m := newTestManager(t, client, Options{DeterministicNames: true})
+
+		go func() {
+			waitForWaiter(t, m, name)
+			signCertificateRequest(t, client, name)
+		}()
+
+		bundle, err := m.Sign(t.Context(), "spiffe://foo", []byte("csr"), time.Hour, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(bundle.Certificate) != "signed-cert" {
+			t.Errorf("unexpected certificate, got=%q", bundle.Certificate)
+		}
+	})
+}
+
+// signCertificateRequest sets a certificate on the named CertificateRequest.
+func signCertificateRequest(t *testing.T, client *fake.Clientset, name string) {
+	cr, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).Get(t.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := client.CertmanagerV1().CertificateRequests(gen.DefaultTestNamespace).UpdateStatus(t.Context(),
+		gen.CertificateRequestFrom(cr,
+			gen.SetCertificateRequestCertificate([]byte("signed-cert")),
+			gen.SetCertificateRequestCA([]byte("ca")),
+		), metav1.UpdateOptions{}); err != nil {
+		t.Error(err)
+	}
+}
+
+// mustCertificate returns a PEM encoded self-signed certificate expiring at
+// notAfter.
+func mustCertificate(t *testing.T, notAfter time.Time) []byte {
+	pk, err := pki.GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     notAfter,
+		PublicKey:    pk.Public(),
+	}
+	certPEM, _, err := pki.SignCertificate(tmpl, tmpl, pk.Public(), pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certmanager
+
+import "context"
+
+// AcquireSigningSlotFunc blocks until a slot to sign a request is available,
+// returning a func which releases the slot.
+type AcquireSigningSlotFunc func(context.Context) (release func(), err error)
+
+// signingSlotKey is the context key of the AcquireSigningSlotFunc.
+type signingSlotKey struct{}
+
+// WithSigningSlots returns a copy of the context which makes Sign hold a slot
+// acquired with acquire while it signs a request. Coalesced requests share the
+// slot of the request which signs on their behalf. That slot is held until
+// signing completes, even if the caller which acquired it has gone away.
+func WithSigningSlots(ctx context.Context, acquire AcquireSigningSlotFunc) context.Context {
+	return context.WithValue(ctx, signingSlotKey{}, acquire)
+}
+
+// AcquireSigningSlot acquires a signing slot with the AcquireSigningSlotFunc
+// of the context. If the context has none, no slot is needed and the returned
+// release func does nothing. Errors from acquiring the slot are returned as
+// is.
+func AcquireSigningSlot(ctx context.Context) (func(), error) {
+	acquire, ok := ctx.Value(signingSlotKey{}).(AcquireSigningSlotFunc)
+	if !ok {
+		return func() {}, nil
+	}
+	return acquire(ctx)
+}
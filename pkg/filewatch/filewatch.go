@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filewatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// Watcher watches a single file for changes, including a file mounted from a
+// ConfigMap or Secret volume, where an update replaces the symlink to the
+// file rather than writing to it.
+type Watcher struct {
+	log      logr.Logger
+	filepath string
+	watcher  *fsnotify.Watcher
+}
+
+// New creates a watch on the file at the given path. The watch is released
+// once Run returns.
+func New(log logr.Logger, filepath string) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watch: %w", err)
+	}
+
+	if err := watcher.Add(filepath); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to add file for watching %q: %w", filepath, err)
+	}
+
+	return &Watcher{
+		log:      log,
+		filepath: filepath,
+		watcher:  watcher,
+	}, nil
+}
+
+// Run calls reload every time the file may have changed, until the context
+// is cancelled. reload is responsible for checking whether the file contents
+// actually changed.
+func (w *Watcher) Run(ctx context.Context, reload func()) {
+	defer w.watcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("closing file watcher")
+			return
+
+		case event := <-w.watcher.Events:
+			w.log.V(3).Info("received event from file watcher", "event", event.Op.String())
+
+			// Watch for remove events, since this is actually the syslink being
+			// changed in the volume mount.
+			if event.Op == fsnotify.Remove {
+				if err := w.watcher.Remove(event.Name); err != nil {
+					w.log.Error(err, "failed to remove file watch")
+				}
+				if err := w.watcher.Add(w.filepath); err != nil {
+					w.log.Error(err, "failed to add new file watch")
+				}
+				reload()
+			}
+
+			// Also allow normal files to be modified and reloaded.
+			if event.Op&fsnotify.Write == fsnotify.Write {
+				reload()
+			}
+
+		case err := <-w.watcher.Errors:
+			w.log.Error(err, "errors watching file")
+		}
+	}
+}
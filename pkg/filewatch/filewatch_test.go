@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filewatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/klog/v2/ktesting"
+)
+
+func Test_Run(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	// Mimic a mounted ConfigMap volume, where the file is a symlink to a data
+	// directory which is swapped on update.
+	if err := os.WriteFile(filepath.Join(dir, "data-1"), []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "data-1"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := New(ktesting.NewLogger(t, ktesting.DefaultConfig), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	reloaded := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.Run(ctx, func() { reloaded <- struct{}{} })
+	}()
+
+	waitForReload := func() {
+		t.Helper()
+		select {
+		case <-reloaded:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected file to be reloaded")
+		}
+	}
+
+	t.Log("writing to the file")
+	if err := os.WriteFile(path, []byte("b"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitForReload()
+
+	t.Log("swapping the symlink")
+	if err := os.WriteFile(filepath.Join(dir, "data-2"), []byte("c"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "data-2"), path+".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "data-1")); err != nil {
+		t.Fatal(err)
+	}
+	waitForReload()
+
+	t.Log("writing to the new file")
+	for len(reloaded) > 0 {
+		<-reloaded
+	}
+	if err := os.WriteFile(path, []byte("d"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitForReload()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected watcher to stop when the context is cancelled")
+	}
+}
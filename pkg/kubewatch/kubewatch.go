@@ -0,0 +1,78 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubewatch
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// retryInterval is how long to wait before retrying a watch which could not
+// be started.
+var retryInterval = 5 * time.Second
+
+// Run starts a watch with newWatch and calls handle for each of its events,
+// until the context is cancelled. The watch is recreated if it could not be
+// started, or if its result channel is closed. name describes the watched
+// resources in log messages.
+func Run(ctx context.Context, log logr.Logger, name string, newWatch func(context.Context) (watch.Interface, error), handle func(watch.Event)) {
+	defer log.Info("Stopped watcher for " + name)
+
+	for {
+		log.Info("Starting / restarting watcher for " + name)
+
+		watcher, err := newWatch(ctx)
+		if err != nil {
+			log.Error(err, "Failed to create watcher for "+name+"; will retry", "retry-interval", retryInterval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		if !consume(ctx, log, name, watcher, handle) {
+			return
+		}
+	}
+}
+
+// consume calls handle for each event of the watch until the context is
+// cancelled, returning false, or the result channel is closed, returning
+// true.
+func consume(ctx context.Context, log logr.Logger, name string, watcher watch.Interface, handle func(watch.Event)) bool {
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+
+		case event, open := <-watcher.ResultChan():
+			if !open {
+				log.Info("Received closed channel from watcher for " + name + ", will recreate")
+				return true
+			}
+
+			handle(event)
+		}
+	}
+}
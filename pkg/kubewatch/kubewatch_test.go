@@ -0,0 +1,96 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubewatch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func Test_Run(t *testing.T) {
+	defer func(interval time.Duration) { retryInterval = interval }(retryInterval)
+	retryInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var (
+		starts   atomic.Int32
+		watchers = make(chan *watch.FakeWatcher, 2)
+	)
+	newWatch := func(context.Context) (watch.Interface, error) {
+		// Fail the first attempt, to check the watch is retried.
+		if starts.Add(1) == 1 {
+			return nil, errors.New("watch failed")
+		}
+		watcher := watch.NewFake()
+		watchers <- watcher
+		return watcher, nil
+	}
+
+	events := make(chan watch.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, ktesting.NewLogger(t, ktesting.DefaultConfig), "test", newWatch, func(event watch.Event) {
+			events <- event
+		})
+	}()
+
+	nextWatcher := func() *watch.FakeWatcher {
+		t.Helper()
+		select {
+		case watcher := <-watchers:
+			return watcher
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected watch to be started")
+			return nil
+		}
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	t.Log("events are handled")
+	watcher := nextWatcher()
+	watcher.Add(cm)
+	assert.Equal(t, watch.Event{Type: watch.Added, Object: cm}, <-events)
+
+	t.Log("a closed watch is recreated")
+	watcher.Stop()
+	watcher = nextWatcher()
+	watcher.Modify(cm)
+	assert.Equal(t, watch.Event{Type: watch.Modified, Object: cm}, <-events)
+	assert.Equal(t, int32(3), starts.Load())
+
+	t.Log("cancelling the context stops the watch")
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to return when the context is cancelled")
+	}
+	assert.True(t, watcher.IsStopped())
+}
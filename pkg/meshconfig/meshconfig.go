@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meshconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/mesh/meshwatcher"
+	"istio.io/istio/pkg/kube/krt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/cert-manager/istio-csr/pkg/kubewatch"
+)
+
+const (
+	// meshConfigKey is the key of the istio mesh ConfigMap holding the
+	// MeshConfig.
+	meshConfigKey = "mesh"
+)
+
+// Watcher watches the istio mesh ConfigMap, and holds the live MeshConfig in
+// an istio mesh config collection, which can be given to the istio
+// authenticators. The trust domain of the MeshConfig is always that
+// configured for istio-csr.
+type Watcher struct {
+	log    logr.Logger
+	opts   Options
+	client client.WithWatch
+
+	defaults *meshconfig.MeshConfig
+	synced   atomic.Bool
+
+	// lock serialises updates of the live MeshConfig.
+	lock       sync.Mutex
+	mesh       krt.StaticSingleton[meshwatcher.MeshConfigResource]
+	collection meshwatcher.WatcherCollection
+}
+
+// New constructs a new MeshConfig watcher. Until the ConfigMap has been
+// read, the default MeshConfig with the given trust domain is used.
+func New(log logr.Logger, restConfig *rest.Config, opts Options, trustDomain string) (*Watcher, error) {
+	k8sClient, err := client.NewWithWatch(restConfig, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+	}
+
+	return newWatcher(log, k8sClient, opts, trustDomain), nil
+}
+
+func newWatcher(log logr.Logger, k8sClient client.WithWatch, opts Options, trustDomain string) *Watcher {
+	defaults := mesh.DefaultMeshConfig()
+	defaults.TrustDomain = trustDomain
+
+	live := krt.NewStatic(&meshwatcher.MeshConfigResource{MeshConfig: defaults}, true)
+
+	return &Watcher{
+		log:        log.WithName("mesh-config-watcher").WithValues("config-map-name", opts.ConfigMapName(), "config-map-namespace", opts.Namespace),
+		opts:       opts,
+		client:     k8sClient,
+		defaults:   defaults,
+		mesh:       live,
+		collection: meshwatcher.ConfigAdapter(live),
+	}
+}
+
+// Collection returns the collection holding the live MeshConfig. Handlers
+// registered with the collection are called whenever the MeshConfig changes.
+func (w *Watcher) Collection() meshwatcher.WatcherCollection {
+	return w.collection
+}
+
+// NeedLeaderElection is false, as every replica must use the live MeshConfig.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Check returns an error until the mesh ConfigMap has been read.
+func (w *Watcher) Check(_ *http.Request) error {
+	if !w.synced.Load() {
+		return errors.New("mesh config has not been read")
+	}
+	return nil
+}
+
+// Start reads the mesh ConfigMap, then watches it for changes until the
+// context is cancelled.
+func (w *Watcher) Start(ctx context.Context) error {
+	for !w.synced.Load() {
+		var cm corev1.ConfigMap
+		err := w.client.Get(ctx, types.NamespacedName{Namespace: w.opts.Namespace, Name: w.opts.ConfigMapName()}, &cm)
+		switch {
+		case err == nil:
+			w.update(&cm)
+			w.synced.Store(true)
+
+		case apierrors.IsNotFound(err):
+			w.log.Info("mesh ConfigMap not found, using default MeshConfig")
+			w.synced.Store(true)
+
+		default:
+			w.log.Error(err, "Failed to get mesh ConfigMap; will retry in 5s")
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+
+	kubewatch.Run(ctx, w.log, "mesh config", func(ctx context.Context) (watch.Interface, error) {
+		return w.client.Watch(ctx, &corev1.ConfigMapList{}, &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", w.opts.ConfigMapName()),
+			Namespace:     w.opts.Namespace,
+		})
+	}, func(event watch.Event) {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			if cm, ok := event.Object.(*corev1.ConfigMap); ok {
+				w.update(cm)
+			}
+
+		case watch.Deleted:
+			w.log.Info("mesh ConfigMap deleted, using default MeshConfig")
+			w.set(w.defaults)
+
+		case watch.Error:
+			w.log.Error(apierrors.FromObject(event.Object), "Got an error event when watching mesh config")
+		}
+	})
+
+	return nil
+}
+
+// update parses the MeshConfig in the ConfigMap, and sets it as the live
+// MeshConfig. An invalid MeshConfig is ignored, keeping the previous one.
+func (w *Watcher) update(cm *corev1.ConfigMap) {
+	meshConfig, err := Parse(cm.Data[meshConfigKey], w.defaults)
+	if err != nil {
+		w.log.Error(err, "Failed to parse mesh config, keeping previous mesh config")
+		return
+	}
+
+	if meshConfig.GetTrustDomain() != w.defaults.GetTrustDomain() {
+		w.log.Info("mesh config trust domain differs from the configured trust domain; using the configured trust domain",
+			"mesh-trust-domain", meshConfig.GetTrustDomain(), "trust-domain", w.defaults.GetTrustDomain())
+		meshConfig.TrustDomain = w.defaults.GetTrustDomain()
+	}
+
+	w.set(meshConfig)
+}
+
+// set sets the live MeshConfig if it has changed, which calls the handlers
+// registered with the collection.
+func (w *Watcher) set(meshConfig *meshconfig.MeshConfig) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	previous := w.collection.Mesh()
+	if proto.Equal(previous, meshConfig) {
+		return
+	}
+
+	if !slices.Equal(previous.GetTrustDomainAliases(), meshConfig.GetTrustDomainAliases()) {
+		w.log.Info("trust domain aliases changed", "trust-domain-aliases", meshConfig.GetTrustDomainAliases())
+	}
+	w.log.V(2).Info("mesh config updated")
+
+	w.mesh.Set(&meshwatcher.MeshConfigResource{MeshConfig: meshConfig})
+}
+
+// Parse parses the MeshConfig YAML, as stored in the istio mesh ConfigMap,
+// applying it on top of the given defaults. Unknown fields are ignored.
+func Parse(data string, defaults *meshconfig.MeshConfig) (*meshconfig.MeshConfig, error) {
+	meshConfig := proto.Clone(defaults).(*meshconfig.MeshConfig)
+	if len(strings.TrimSpace(data)) == 0 {
+		return meshConfig, nil
+	}
+
+	jsonData, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert mesh config to JSON: %w", err)
+	}
+
+	var parsed meshconfig.MeshConfig
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(jsonData, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse mesh config: %w", err)
+	}
+
+	proto.Merge(meshConfig, &parsed)
+
+	return meshConfig, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meshconfig
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"istio.io/istio/pkg/config/mesh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_Parse(t *testing.T) {
+	defaults := mesh.DefaultMeshConfig()
+	defaults.TrustDomain = "cluster.local"
+
+	tests := map[string]struct {
+		data string
+
+		expErr         bool
+		expTrustDomain string
+		expAliases     []string
+	}{
+		"if empty, should return the defaults": {
+			data:           "",
+			expTrustDomain: "cluster.local",
+		},
+		"if trust domain aliases are set, should return them": {
+			data:           "trustDomainAliases:\n- old.example\n- corp.example\n",
+			expTrustDomain: "cluster.local",
+			expAliases:     []string{"old.example", "corp.example"},
+		},
+		"if unknown fields are set, should ignore them": {
+			data:           "trustDomainAliases: [old.example]\nnotAField: true\n",
+			expTrustDomain: "cluster.local",
+			expAliases:     []string{"old.example"},
+		},
+		"if the trust domain is set, should return it": {
+			data:           "trustDomain: corp.example\n",
+			expTrustDomain: "corp.example",
+		},
+		"if not valid YAML, should error": {
+			data:   "trustDomainAliases: [",
+			expErr: true,
+		},
+		"if a field has the wrong type, should error": {
+			data:   "trustDomainAliases: foo\n",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			meshConfig, err := Parse(test.data, defaults)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error, exp=%t got=%v", test.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if meshConfig.GetTrustDomain() != test.expTrustDomain {
+				t.Errorf("unexpected trust domain, exp=%q got=%q", test.expTrustDomain, meshConfig.GetTrustDomain())
+			}
+			if !slices.Equal(meshConfig.GetTrustDomainAliases(), test.expAliases) {
+				t.Errorf("unexpected trust domain aliases, exp=%v got=%v", test.expAliases, meshConfig.GetTrustDomainAliases())
+			}
+		})
+	}
+
+	if defaults.GetTrustDomainAliases() != nil {
+		t.Errorf("defaults were modified: %v", defaults)
+	}
+}
+
+func Test_ConfigMapName(t *testing.T) {
+	tests := map[string]struct {
+		opts Options
+		exp  string
+	}{
+		"if no revision, should return the name": {
+			opts: Options{Name: "istio"},
+			exp:  "istio",
+		},
+		"if the default revision, should return the name": {
+			opts: Options{Name: "istio", Revision: "default"},
+			exp:  "istio",
+		},
+		"if a revision, should suffix the name with the revision": {
+			opts: Options{Name: "istio", Revision: "1-24"},
+			exp:  "istio-1-24",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.opts.ConfigMapName(); got != test.exp {
+				t.Errorf("unexpected ConfigMap name, exp=%q got=%q", test.exp, got)
+			}
+		})
+	}
+}
+
+func Test_Watcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-canary"},
+		Data:       map[string]string{"mesh": "trustDomain: other.example\ntrustDomainAliases: [old.example]\n"},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(cm).Build()
+
+	w := newWatcher(logr.Discard(), k8sClient, Options{Enabled: true, Namespace: "istio-system", Name: "istio", Revision: "canary"}, "cluster.local")
+	if err := w.Check(nil); err == nil {
+		t.Fatal("expected check to fail before the ConfigMap has been read")
+	}
+
+	changed := make(chan struct{}, 10)
+	w.Collection().AddMeshHandler(func() { changed <- struct{}{} })
+
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+
+	waitFor(t, func() bool { return w.Check(nil) == nil })
+
+	if got := w.Collection().Mesh().GetTrustDomain(); got != "cluster.local" {
+		t.Errorf("expected configured trust domain to be used, got %q", got)
+	}
+	if got := w.Collection().Mesh().GetTrustDomainAliases(); !slices.Equal(got, []string{"old.example"}) {
+		t.Errorf("unexpected trust domain aliases: %v", got)
+	}
+	<-changed
+
+	// Invalid mesh config should be ignored.
+	cm.Data["mesh"] = "trustDomainAliases: ["
+	if err := k8sClient.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+
+	cm.Data["mesh"] = "trustDomainAliases: [old.example, corp.example]\n"
+	if err := k8sClient.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(w.Collection().Mesh().GetTrustDomainAliases()) == 2 })
+	<-changed
+
+	if err := k8sClient.Delete(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(w.Collection().Mesh().GetTrustDomainAliases()) == 0 })
+	<-changed
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from watcher: %v", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for range 100 {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
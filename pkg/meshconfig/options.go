@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meshconfig
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Options holds configuration for watching the istio MeshConfig.
+type Options struct {
+	// Enabled watches the istio mesh ConfigMap when true. When false, the
+	// default MeshConfig is used.
+	Enabled bool
+
+	// Namespace is the namespace of the istio mesh ConfigMap.
+	Namespace string
+
+	// Name is the name of the istio mesh ConfigMap, for the default revision.
+	Name string
+
+	// Revision is the istio revision whose MeshConfig is watched. If set, the
+	// ConfigMap name is suffixed with the revision, as done by istiod.
+	Revision string
+}
+
+// ConfigMapName returns the name of the istio mesh ConfigMap for the
+// configured revision.
+func (o *Options) ConfigMapName() string {
+	if len(o.Revision) == 0 || o.Revision == "default" {
+		return o.Name
+	}
+	return o.Name + "-" + o.Revision
+}
+
+// Validate confirms that the given MeshConfig options are valid.
+func (o *Options) Validate() error {
+	if !o.Enabled {
+		return nil
+	}
+
+	if len(o.Namespace) == 0 {
+		return fmt.Errorf("mesh-config-namespace must be set when the mesh config watch is enabled")
+	}
+
+	if len(o.Name) == 0 {
+		return fmt.Errorf("mesh-config-name must be set when the mesh config watch is enabled")
+	}
+
+	return nil
+}
+
+func AddFlags(o *Options, fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "mesh-config-enabled", false,
+		"If true, watch the istio mesh ConfigMap and use the live MeshConfig when authenticating "+
+			"requests, including its trust domain aliases. If false, the default MeshConfig is used.")
+
+	fs.StringVar(&o.Namespace, "mesh-config-namespace", "istio-system",
+		"The namespace of the istio mesh ConfigMap.")
+
+	fs.StringVar(&o.Name, "mesh-config-name", "istio",
+		"The name of the istio mesh ConfigMap.")
+
+	fs.StringVar(&o.Revision, "mesh-config-revision", "",
+		"The istio revision whose MeshConfig is watched. If set to a revision other than "+
+			"default, the ConfigMap name is suffixed with the revision, as done by istiod.")
+}
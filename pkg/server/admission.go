@@ -0,0 +1,94 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/spiffe"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/cert-manager/istio-csr/pkg/server/internal/policy"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
+)
+
+var metricAdmissionPolicyRejections = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "cert_manager_istio_csr",
+		Name:      "admission_policy_rejections",
+		Help:      "Total number of certificate requests rejected by admission policies, by policy.",
+	},
+	[]string{"policy"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricAdmissionPolicyRejections)
+}
+
+// admitRequest evaluates the admission policies against the authorized
+// request. identities are the identities the certificate is requested for,
+// and node is the node of the caller if the request impersonates a workload.
+// Returns a request error naming the policy if the request was rejected.
+func (s *Server) admitRequest(ctx context.Context, icr *securityapi.IstioCertificateRequest, csr *x509.CertificateRequest, identities string, caller *security.Caller, node string) error {
+	input := policy.Input{
+		Caller: policy.Caller{
+			Identities: strings.Split(identities, ","),
+			Namespace:  caller.KubernetesInfo.PodNamespace,
+			PodName:    caller.KubernetesInfo.PodName,
+			Node:       node,
+			ClusterID:  string(clusterIDFromContext(ctx)),
+		},
+		CSR:               csr,
+		RequestedDuration: time.Duration(icr.GetValidityDuration()) * time.Second,
+	}
+
+	// The namespace and service account are of the workload the certificate
+	// is for, which is the impersonated workload rather than the node proxy
+	// when impersonating.
+	if id, err := spiffe.ParseIdentity(input.Caller.Identities[0]); err == nil {
+		input.Caller.Namespace = id.Namespace
+		input.Caller.ServiceAccount = id.ServiceAccount
+	} else {
+		input.Caller.ServiceAccount = caller.KubernetesInfo.PodServiceAccount
+	}
+
+	if len(icr.GetMetadata().GetFields()[security.ImpersonatedIdentity].GetStringValue()) > 0 {
+		input.Caller.Impersonator = caller.Identities
+	}
+
+	policyCtx, span := tracer.Start(ctx, "AdmissionPolicy")
+	rejection := s.admissionPolicy.Evaluate(policyCtx, input)
+	if rejection == nil {
+		tracing.EndSpan(span, nil)
+		return nil
+	}
+
+	metricAdmissionPolicyRejections.WithLabelValues(rejection.Policy).Inc()
+	err := newRequestError(codes.PermissionDenied, reasonAdmissionPolicyRejected,
+		fmt.Sprintf("certificate request was rejected by admission policy %q: %s", rejection.Policy, rejection.Message), rejection.Err)
+	tracing.EndSpan(span, err)
+
+	return err
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"k8s.io/klog/v2/ktesting"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+	cmfake "github.com/cert-manager/istio-csr/pkg/certmanager/fake"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/policy"
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_CreateCertificateAdmissionPolicy(t *testing.T) {
+	const identity = "spiffe://cluster.local/ns/sandbox/sa/httpbin"
+
+	tests := map[string]struct {
+		policies string
+
+		expCode    codes.Code
+		expMessage string
+		expSigned  bool
+	}{
+		"if all policies admit the request, should sign": {
+			policies: `policies:
+- name: sandbox-only
+  expression: caller.namespace == "sandbox" && caller.serviceAccount == "httpbin"
+- name: max-duration
+  expression: csr.requestedDuration <= duration("1h")
+`,
+			expCode:   codes.OK,
+			expSigned: true,
+		},
+		"if a policy rejects the request, should return PermissionDenied naming the policy and not sign": {
+			policies: `policies:
+- name: sandbox-only
+  expression: caller.namespace == "sandbox"
+- name: no-httpbin
+  expression: caller.serviceAccount != "httpbin"
+  message: httpbin may not request certificates
+`,
+			expCode:    codes.PermissionDenied,
+			expMessage: `certificate request was rejected by admission policy "no-httpbin": httpbin may not request certificates`,
+			expSigned:  false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policies.yaml")
+			if err := os.WriteFile(path, []byte(test.policies), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			log := ktesting.NewLogger(t, ktesting.DefaultConfig)
+			admissionPolicy, err := policy.New(log, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var signed bool
+			s := &Server{
+				opts: Options{
+					MaximumClientCertificateDuration: time.Hour,
+				},
+				authenticators: []security.Authenticator{
+					newMockAuthn([]string{identity}, ""),
+				},
+				log: log,
+				cm: cmfake.New().WithSign(func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					signed = true
+					return certmanager.Bundle{}, errors.New("generic error")
+				}),
+				admissionPolicy: admissionPolicy,
+			}
+
+			icr := &securityapi.IstioCertificateRequest{
+				Csr: string(gen.MustCSR(t,
+					gen.SetCSRIdentities([]string{identity}),
+				)),
+				ValidityDuration: 600,
+			}
+
+			_, err = s.CreateCertificate(t.Context(), icr)
+			assert.Equal(t, test.expSigned, signed)
+			if test.expCode != codes.OK {
+				assert.Equal(t, test.expCode, status.Code(err), "%v", err)
+				assert.Equal(t, test.expMessage, status.Convert(err).Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+
+	"github.com/cert-manager/istio-csr/pkg/server/audit"
+)
+
+// newAuditRecord returns an audit record for the certificate request received
+// at the given time. The record's outcome is rejected until it is updated.
+func newAuditRecord(icr *securityapi.IstioCertificateRequest, now time.Time) *audit.Record {
+	return &audit.Record{
+		Timestamp:         now,
+		RequestedDuration: (time.Duration(icr.GetValidityDuration()) * time.Second).String(),
+		Outcome:           audit.OutcomeRejected,
+	}
+}
+
+// auditCaller sets the identities and pod of the authenticated caller on the
+// audit record. If the caller requested a certificate for an impersonated
+// identity, the caller is recorded as the impersonator.
+func auditCaller(record *audit.Record, icr *securityapi.IstioCertificateRequest, caller *security.Caller) {
+	if caller == nil {
+		return
+	}
+
+	if impersonatedIdentity := icr.GetMetadata().GetFields()[security.ImpersonatedIdentity].GetStringValue(); len(impersonatedIdentity) > 0 {
+		record.Identities = []string{impersonatedIdentity}
+		record.Impersonator = caller.Identities
+	} else {
+		record.Identities = caller.Identities
+	}
+
+	if info := caller.KubernetesInfo; len(info.PodName) > 0 || len(info.PodNamespace) > 0 {
+		record.Pod = &audit.Pod{
+			Name:           info.PodName,
+			Namespace:      info.PodNamespace,
+			UID:            info.PodUID,
+			ServiceAccount: info.PodServiceAccount,
+		}
+	}
+}
+
+// auditCertificate sets the serial number and expiry of the signed certificate
+// on the audit record.
+func auditCertificate(record *audit.Record, cert *x509.Certificate) {
+	record.SerialNumber = fmt.Sprintf("%x", cert.SerialNumber)
+	notAfter := cert.NotAfter
+	record.NotAfter = &notAfter
+}
+
+// writeAuditRecord writes the audit record to the configured audit sink, if
+// any. Failing to write a record is logged, but does not fail the request.
+func (s *Server) writeAuditRecord(record *audit.Record) {
+	if s.auditSink == nil {
+		return
+	}
+
+	if err := s.auditSink.Write(*record); err != nil {
+		s.log.Error(err, "failed to write audit record", "identities", record.Identities, "outcome", record.Outcome)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit provides structured audit records of workload certificate
+// signing decisions, and sinks to write them to.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+)
+
+// Outcome is the outcome of a certificate request.
+type Outcome string
+
+const (
+	// OutcomeGranted is recorded when a certificate was signed and returned to
+	// the caller.
+	OutcomeGranted Outcome = "Granted"
+
+	// OutcomeRejected is recorded when the request was rejected, for example
+	// because it failed authentication or authorization.
+	OutcomeRejected Outcome = "Rejected"
+
+	// OutcomeFailed is recorded when the request was authorized, but a
+	// certificate could not be signed.
+	OutcomeFailed Outcome = "Failed"
+)
+
+// Pod is the Kubernetes pod information of the caller.
+type Pod struct {
+	Name           string `json:"name,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	UID            string `json:"uid,omitempty"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
+// Record is a single audit record of a certificate request.
+type Record struct {
+	// Timestamp is the time the request was received.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Identities are the identities the certificate was requested for.
+	Identities []string `json:"identities,omitempty"`
+
+	// GrantedIdentities are the identities the request was authorized for,
+	// after authentication, impersonation, denylist and authorization checks.
+	// Unset if the request was rejected before being authorized.
+	GrantedIdentities []string `json:"grantedIdentities,omitempty"`
+
+	// Impersonator are the identities of the caller, if the caller requested
+	// a certificate on behalf of another identity.
+	Impersonator []string `json:"impersonator,omitempty"`
+
+	// Pod is the Kubernetes pod information of the caller, if known.
+	Pod *Pod `json:"pod,omitempty"`
+
+	// RequestedDuration is the certificate duration requested by the caller.
+	RequestedDuration string `json:"requestedDuration,omitempty"`
+
+	// GrantedDuration is the certificate duration requested from the issuer.
+	GrantedDuration string `json:"grantedDuration,omitempty"`
+
+	// MaxDuration is the effective maximum certificate duration of the
+	// request, being the lowest of the global and namespace maximums.
+	MaxDuration string `json:"maxDuration,omitempty"`
+
+	// Usages are the key usages requested from the issuer.
+	Usages []cmapi.KeyUsage `json:"usages,omitempty"`
+
+	// Issuer is the issuer which was requested to sign the certificate.
+	Issuer *cmmeta.IssuerReference `json:"issuer,omitempty"`
+
+	// SerialNumber is the hex encoded serial number of the signed
+	// certificate.
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// NotAfter is the expiry time of the signed certificate.
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+
+	// Outcome is the outcome of the request.
+	Outcome Outcome `json:"outcome"`
+
+	// Denylisted is true if the request was blocked by the identity
+	// denylist.
+	Denylisted bool `json:"denylisted,omitempty"`
+
+	// Reason is the reason the request was rejected or failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sink is a destination for audit records. Implementations must be safe for
+// concurrent use.
+type Sink interface {
+	// Write writes the audit record to the sink.
+	Write(record Record) error
+}
+
+// JSONSink writes audit records as JSON lines to a writer.
+type JSONSink struct {
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+// NewJSONSink returns a Sink which writes audit records as JSON lines to the
+// given writer.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Write writes the record as a single JSON line.
+func (j *JSONSink) Write(record Record) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.enc.Encode(record)
+}
+
+// FileSink writes audit records as JSON lines to a file. It must be closed
+// once no more records will be written.
+type FileSink struct {
+	*JSONSink
+
+	// file is the opened audit log file, or nil if writing to stdout.
+	file *os.File
+}
+
+// NewFileSink returns a Sink which appends audit records as JSON lines to the
+// file at the given path. If the path is "-", records are written to stdout.
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "-" {
+		return &FileSink{JSONSink: NewJSONSink(os.Stdout)}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file %q: %w", path, err)
+	}
+
+	return &FileSink{JSONSink: NewJSONSink(f), file: f}, nil
+}
+
+// Close flushes written records to disk and closes the audit log file. Stdout
+// is not closed.
+func (f *FileSink) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return errors.Join(f.file.Sync(), f.file.Close())
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSink(t *testing.T) {
+	notAfter := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		record  Record
+		expLine string
+	}{
+		"a rejected record should omit empty fields": {
+			record: Record{
+				Timestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Outcome:   OutcomeRejected,
+				Reason:    "request sent with no identity",
+			},
+			expLine: `{"timestamp":"2026-01-01T00:00:00Z","outcome":"Rejected","reason":"request sent with no identity"}` + "\n",
+		},
+		"a granted record should include the certificate and issuer": {
+			record: Record{
+				Timestamp:         time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Identities:        []string{"spiffe://cluster.local/ns/foo/sa/bar"},
+				GrantedIdentities: []string{"spiffe://cluster.local/ns/foo/sa/bar"},
+				Impersonator:      []string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"},
+				Pod:               &Pod{Name: "bar", Namespace: "foo"},
+				RequestedDuration: "24h0m0s",
+				GrantedDuration:   "1h0m0s",
+				Issuer:            &cmmeta.IssuerReference{Name: "ca", Kind: "Issuer", Group: "cert-manager.io"},
+				SerialNumber:      "1a",
+				NotAfter:          &notAfter,
+				Outcome:           OutcomeGranted,
+			},
+			expLine: `{"timestamp":"2026-01-01T00:00:00Z","identities":["spiffe://cluster.local/ns/foo/sa/bar"],` +
+				`"grantedIdentities":["spiffe://cluster.local/ns/foo/sa/bar"],` +
+				`"impersonator":["spiffe://cluster.local/ns/istio-system/sa/ztunnel"],"pod":{"name":"bar","namespace":"foo"},` +
+				`"requestedDuration":"24h0m0s","grantedDuration":"1h0m0s","issuer":{"name":"ca","kind":"Issuer","group":"cert-manager.io"},` +
+				`"serialNumber":"1a","notAfter":"2026-01-02T00:00:00Z","outcome":"Granted"}` + "\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, NewJSONSink(&buf).Write(test.record))
+			assert.Equal(t, test.expLine, buf.String())
+		})
+	}
+}
+
+func TestNewFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for range 2 {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, sink.Write(Record{Outcome: OutcomeGranted}))
+		assert.NoError(t, sink.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := `{"timestamp":"0001-01-01T00:00:00Z","outcome":"Granted"}` + "\n"
+	assert.Equal(t, line+line, string(data), "expected records to be appended to the file")
+
+	_, err = NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.log"))
+	assert.Error(t, err)
+
+	stdout, err := NewFileSink("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, stdout.Close(), "expected closing a stdout sink to be a no-op")
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"k8s.io/klog/v2/ktesting"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+	cmfake "github.com/cert-manager/istio-csr/pkg/certmanager/fake"
+	"github.com/cert-manager/istio-csr/pkg/server/audit"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/routing"
+	tlsfake "github.com/cert-manager/istio-csr/pkg/tls/fake"
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+type recordingSink struct {
+	lock    sync.Mutex
+	records []audit.Record
+}
+
+func (r *recordingSink) Write(record audit.Record) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func Test_CreateCertificateAudit(t *testing.T) {
+	const spiffeDomain = "spiffe://foo"
+
+	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t, spiffeDomain)
+	issuerRef := cmmeta.IssuerReference{Name: "foo-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"}
+	kubeInfo := security.KubernetesInfo{
+		PodName:           "foo-pod",
+		PodNamespace:      "foo",
+		PodUID:            "1234",
+		PodServiceAccount: "foo-sa",
+	}
+
+	tests := map[string]struct {
+		authenticator      *mockAuthenticator
+		csrIdentity        string
+		trustDomainAliases []string
+		signErr            error
+
+		expRecord audit.Record
+	}{
+		"if authn fails, should record a rejected request": {
+			authenticator: newMockAuthn(nil, "bad token"),
+			expRecord: audit.Record{
+				RequestedDuration: "1h0m0s",
+				Outcome:           audit.OutcomeRejected,
+				Reason:            "request authenticate failure: bad token",
+			},
+		},
+		"if sign fails, should record a failed request with the issuer": {
+			authenticator: newMockAuthnImpersonate([]string{spiffeDomain}, &kubeInfo),
+			signErr:       errors.New("generic error"),
+			expRecord: audit.Record{
+				Identities:        []string{spiffeDomain},
+				GrantedIdentities: []string{spiffeDomain},
+				Pod:               &audit.Pod{Name: "foo-pod", Namespace: "foo", UID: "1234", ServiceAccount: "foo-sa"},
+				RequestedDuration: "1h0m0s",
+				GrantedDuration:   "30m0s",
+				MaxDuration:       "30m0s",
+				Usages:            []cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageServerAuth},
+				Issuer:            &issuerRef,
+				Outcome:           audit.OutcomeFailed,
+				Reason:            "generic error",
+			},
+		},
+		"if the CSR uses a trust domain alias, should record the granted identities of the CSR": {
+			authenticator:      newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/foo/sa/foo-sa"}, &kubeInfo),
+			csrIdentity:        "spiffe://alias.local/ns/foo/sa/foo-sa",
+			trustDomainAliases: []string{"alias.local"},
+			signErr:            errors.New("generic error"),
+			expRecord: audit.Record{
+				Identities:        []string{"spiffe://cluster.local/ns/foo/sa/foo-sa"},
+				GrantedIdentities: []string{"spiffe://alias.local/ns/foo/sa/foo-sa"},
+				Pod:               &audit.Pod{Name: "foo-pod", Namespace: "foo", UID: "1234", ServiceAccount: "foo-sa"},
+				RequestedDuration: "1h0m0s",
+				GrantedDuration:   "30m0s",
+				MaxDuration:       "30m0s",
+				Usages:            []cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageServerAuth},
+				Outcome:           audit.OutcomeFailed,
+				Reason:            "generic error",
+			},
+		},
+		"if sign succeeds, should record a granted request with the certificate": {
+			authenticator: newMockAuthnImpersonate([]string{spiffeDomain}, &kubeInfo),
+			expRecord: audit.Record{
+				Identities:        []string{spiffeDomain},
+				GrantedIdentities: []string{spiffeDomain},
+				Pod:               &audit.Pod{Name: "foo-pod", Namespace: "foo", UID: "1234", ServiceAccount: "foo-sa"},
+				RequestedDuration: "1h0m0s",
+				GrantedDuration:   "30m0s",
+				MaxDuration:       "30m0s",
+				Usages:            []cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageServerAuth},
+				Issuer:            &issuerRef,
+				SerialNumber:      "0",
+				Outcome:           audit.OutcomeGranted,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sink := new(recordingSink)
+			s := &Server{
+				opts: Options{
+					MaximumClientCertificateDuration: time.Hour / 2,
+				},
+				authenticators: []security.Authenticator{test.authenticator},
+				log:            ktesting.NewLogger(t, ktesting.DefaultConfig),
+				cm: cmfake.New().WithSign(func(_ context.Context, _ string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					if test.signErr != nil {
+						return certmanager.Bundle{}, test.signErr
+					}
+					return certmanager.Bundle{Certificate: leafCertPEM, IssuerRef: issuerRef}, nil
+				}),
+				tls: tlsfake.New().WithRootCAs(rootCertPEM, rootPool),
+				routingPolicy: &routing.Policy{
+					Rules: []routing.Rule{{Identities: []string{spiffeDomain}, IssuerRef: issuerRef}},
+				},
+				trustDomain:        "cluster.local",
+				trustDomainAliases: test.trustDomainAliases,
+				auditSink:          sink,
+			}
+
+			csrIdentity := spiffeDomain
+			if len(test.csrIdentity) > 0 {
+				csrIdentity = test.csrIdentity
+			}
+
+			_, _ = s.CreateCertificate(t.Context(), &securityapi.IstioCertificateRequest{
+				Csr:              string(gen.MustCSR(t, gen.SetCSRIdentities([]string{csrIdentity}))),
+				ValidityDuration: 60 * 60,
+			})
+
+			if !assert.Len(t, sink.records, 1) {
+				return
+			}
+
+			record := sink.records[0]
+			assert.False(t, record.Timestamp.IsZero(), "expected timestamp to be set")
+			record.Timestamp = time.Time{}
+
+			if test.expRecord.Outcome == audit.OutcomeGranted {
+				assert.NotNil(t, record.NotAfter, "expected NotAfter to be set")
+				record.NotAfter = nil
+			}
+
+			assert.Equal(t, test.expRecord, record)
+		})
+	}
+}
+
+func Test_auditCaller(t *testing.T) {
+	impersonated, err := structpb.NewStruct(map[string]any{
+		security.ImpersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/bar",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		icr    *securityapi.IstioCertificateRequest
+		caller *security.Caller
+
+		expIdentities   []string
+		expImpersonator []string
+		expPod          *audit.Pod
+	}{
+		"if no caller, should not set identities": {
+			icr: &securityapi.IstioCertificateRequest{},
+		},
+		"if caller has no pod info, should only set identities": {
+			icr:           &securityapi.IstioCertificateRequest{},
+			caller:        &security.Caller{Identities: []string{"spiffe://foo"}},
+			expIdentities: []string{"spiffe://foo"},
+		},
+		"if caller impersonates an identity, should record caller as impersonator": {
+			icr: &securityapi.IstioCertificateRequest{Metadata: impersonated},
+			caller: &security.Caller{
+				Identities:     []string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"},
+				KubernetesInfo: security.KubernetesInfo{PodName: "ztunnel", PodNamespace: "istio-system"},
+			},
+			expIdentities:   []string{"spiffe://cluster.local/ns/foo/sa/bar"},
+			expImpersonator: []string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"},
+			expPod:          &audit.Pod{Name: "ztunnel", Namespace: "istio-system"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			record := newAuditRecord(test.icr, time.Now())
+			auditCaller(record, test.icr, test.caller)
+
+			assert.Equal(t, test.expIdentities, record.Identities)
+			assert.Equal(t, test.expImpersonator, record.Impersonator)
+			assert.Equal(t, test.expPod, record.Pod)
+		})
+	}
+}
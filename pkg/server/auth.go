@@ -18,60 +18,65 @@ package server
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/url"
 	"sort"
 	"strings"
 
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
 	securityapi "istio.io/api/security/v1alpha1"
-	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/security"
 	pkiutil "istio.io/istio/security/pkg/pki/util"
 
 	"github.com/cert-manager/istio-csr/pkg/server/internal/extensions"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
 )
 
 // authRequest will authenticate the request and authorize the CSR is valid for
-// the identity
-func (s *Server) authRequest(ctx context.Context, icr *securityapi.IstioCertificateRequest) (string, bool) {
-	var caller *security.Caller
-	var errs []error
-	found := false
-	for _, authenticator := range s.authenticators {
-		var err error
-		caller, err = authenticator.Authenticate(security.AuthContext{GrpcContext: ctx})
-		if err == nil {
-			found = true
-			break
-		}
-		errs = append(errs, err)
-	}
-	if !found {
-		// TODO: pass in logger with request context
-		s.log.Error(errors.Join(errs...), "failed to authenticate request")
-		return "", false
+// the identity. Returns the authorized identities, the parsed CSR and the
+// authenticated caller, or a request error describing why the request was
+// rejected.
+func (s *Server) authRequest(ctx context.Context, icr *securityapi.IstioCertificateRequest) (string, *x509.CertificateRequest, *security.Caller, error) {
+	log := s.requestLogger(ctx)
+
+	caller, err := s.authenticate(ctx)
+	if err != nil {
+		log.Error(err, "failed to authenticate request")
+		return "", nil, nil, newRequestError(codes.Unauthenticated, reasonAuthenticationFailed, "request authenticate failure", err)
 	}
 
 	// request authentication has no identities, so error
 	if len(caller.Identities) == 0 {
-		s.log.Error(errors.New("request sent with no identity"), "")
-		return "", false
+		err := newRequestError(codes.Unauthenticated, reasonNoIdentity, "request sent with no identity", nil)
+		log.Error(err, "")
+		return "", nil, caller, err
 	}
 
-	var identities string
+	var identities, node string
 
 	crMetadata := icr.GetMetadata().GetFields()
 	impersonatedIdentity := crMetadata[security.ImpersonatedIdentity].GetStringValue()
 	if impersonatedIdentity != "" {
-		log.Debugf("impersonated identity: %s", impersonatedIdentity)
-		if s.nodeAuthorizer == nil {
-			log.Warnf("impersonation not allowed, as node authorizer (CA_TRUSTED_NODE_ACCOUNTS) is not configured")
-			return "", false
+		clusterID := clusterIDFromContext(ctx)
+		log = log.WithValues("impersonated-identity", impersonatedIdentity, "cluster-id", clusterID)
+		log.V(3).Info("request is impersonating identity")
+		nodeAuthorizer := s.nodeAuthorizerFor(clusterID)
+		if nodeAuthorizer == nil {
+			log.Info("impersonation not allowed, as node authorizer (CA_TRUSTED_NODE_ACCOUNTS) is not configured")
+			return "", nil, caller, newRequestError(codes.PermissionDenied, reasonImpersonationNotAllowed, "impersonation not allowed, as node authorizer is not configured", nil)
 		}
-		if err := s.nodeAuthorizer.authenticateImpersonation(caller.KubernetesInfo, impersonatedIdentity); err != nil {
-			log.Error(fmt.Errorf("failed to validate impersonated identity %v: %v", impersonatedIdentity, err))
-			return identities, false
+		authzCtx, span := tracer.Start(logr.NewContext(ctx, log), "AuthorizeImpersonation")
+		node, err = nodeAuthorizer.authenticateImpersonation(authzCtx, caller.KubernetesInfo, impersonatedIdentity)
+		tracing.EndSpan(span, err)
+		if err != nil {
+			err = fmt.Errorf("failed to validate impersonated identity %v: %v", impersonatedIdentity, err)
+			log.Error(err, "")
+			return identities, nil, caller, newRequestError(codes.PermissionDenied, reasonImpersonationDenied, "caller is not authorized to impersonate the requested identity", err)
 		}
 		identities = impersonatedIdentity
 	} else {
@@ -79,21 +84,29 @@ func (s *Server) authRequest(ctx context.Context, icr *securityapi.IstioCertific
 	}
 
 	// return concatenated list of verified ids
-	log := s.log.WithValues("identities", identities)
+	log = log.WithValues("identities", identities)
 
 	csr, err := pkiutil.ParsePemEncodedCSR([]byte(icr.GetCsr()))
 	if err != nil {
 		log.Error(err, "failed to decode CSR")
-		return identities, false
+		return identities, nil, caller, newRequestError(codes.InvalidArgument, reasonInvalidCSR, "failed to decode CSR", err)
 	}
 
 	if err := csr.CheckSignature(); err != nil {
 		log.Error(err, "CSR failed signature check")
-		return identities, false
+		return identities, nil, caller, newRequestError(codes.InvalidArgument, reasonInvalidCSR, "CSR failed signature check", err)
 	}
 
-	// if the csr contains any other options set, error
-	if len(csr.DNSNames) > 0 || len(csr.IPAddresses) > 0 ||
+	// ensure the csr public key is allowed by the key policy
+	if err := s.keyPolicy.check(csr.PublicKey); err != nil {
+		log.Error(err, "CSR public key is not allowed")
+		return identities, nil, caller, newRequestError(codes.InvalidArgument, reasonKeyNotAllowed, "CSR public key is not allowed: "+err.Error(), nil)
+	}
+
+	// if the csr contains any other options set, error. DNS names and IP
+	// addresses may be permitted by the SAN policy, and are checked once the
+	// identities are known.
+	if (s.sanPolicy == nil && (len(csr.DNSNames) > 0 || len(csr.IPAddresses) > 0)) ||
 		len(csr.Subject.CommonName) > 0 || len(csr.EmailAddresses) > 0 {
 		log.Error(errors.New("forbidden extensions"), "",
 			"dns", csr.DNSNames,
@@ -101,27 +114,82 @@ func (s *Server) authRequest(ctx context.Context, icr *securityapi.IstioCertific
 			"common-name", csr.Subject.CommonName,
 			"emails", csr.EmailAddresses)
 
-		return identities, false
+		return identities, nil, caller, newRequestError(codes.InvalidArgument, reasonForbiddenCSRFields, "CSR contains forbidden DNS names, IP addresses, common name or email addresses", nil)
 	}
 
 	// ensure csr extensions are valid
-	if err := extensions.ValidateCSRExtentions(csr); err != nil {
+	if err := extensions.ValidateCSRExtentions(csr, s.sanPolicy != nil); err != nil {
 		log.Error(err, "forbidden extensions")
-		return identities, false
+		return identities, nil, caller, newRequestError(codes.InvalidArgument, reasonForbiddenCSRExtensions, "CSR contains forbidden extensions", err)
 	}
 
 	if impersonatedIdentity == "" {
-		if !identitiesMatch(caller.Identities, csr.URIs) {
-			log.Error(fmt.Errorf("%v != %v", caller.Identities, csr.URIs), "failed to match URIs with identities")
-			return identities, false
+		matched, ok := s.matchCSRIdentities(caller.Identities, csr.URIs)
+		if !ok {
+			err := fmt.Errorf("%v != %v", caller.Identities, csr.URIs)
+			log.Error(err, "failed to match URIs with identities")
+			return identities, nil, caller, newRequestError(codes.PermissionDenied, reasonIdentityMismatch, "CSR URIs do not match the authenticated identities", err)
+		}
+		identities = matched
+	} else {
+		matched, ok := s.matchCSRIdentities([]string{impersonatedIdentity}, csr.URIs)
+		if !ok {
+			err := fmt.Errorf("%v != %v", impersonatedIdentity, csr.URIs)
+			log.Error(err, "failed to match URIs with impersonated identities")
+			return identities, nil, caller, newRequestError(codes.PermissionDenied, reasonIdentityMismatch, "CSR URIs do not match the impersonated identity", err)
+		}
+		identities = matched
+	}
+
+	if err := s.checkIssuedTrustDomain(csr.URIs); err != nil {
+		log.Error(err, "CSR trust domain is not allowed")
+		return identities, nil, caller, newRequestError(codes.PermissionDenied, reasonTrustDomainNotAllowed, "CSR SPIFFE IDs do not use an allowed trust domain", err)
+	}
+
+	// ensure any requested DNS names and IP addresses are allowed for the
+	// identities by the SAN policy
+	if s.sanPolicy != nil {
+		if err := s.sanPolicy.Check(strings.Split(identities, ","), csr.DNSNames, csr.IPAddresses); err != nil {
+			log.Error(err, "CSR DNS names or IP addresses are not allowed", "dns", csr.DNSNames, "ips", csr.IPAddresses)
+			return identities, nil, caller, newRequestError(codes.PermissionDenied, reasonSANsNotAllowed, "CSR DNS names or IP addresses are not allowed for the identities", err)
+		}
+	}
+
+	if s.admissionPolicy != nil {
+		if err := s.admitRequest(logr.NewContext(ctx, log), icr, csr, identities, caller, node); err != nil {
+			log.Error(err, "certificate request was rejected by admission policy")
+			return identities, nil, caller, err
 		}
-	} else if !identitiesMatch([]string{impersonatedIdentity}, csr.URIs) {
-		log.Error(fmt.Errorf("%v != %v", impersonatedIdentity, csr.URIs), "failed to match URIs with impersonated identities")
-		return identities, false
 	}
 
 	// return positive authn of given csr
-	return identities, true
+	return identities, csr, caller, nil
+}
+
+// authenticate will authenticate the caller of the request using the
+// configured authenticators. If the caller has already been authenticated by
+// an interceptor, the stored caller is returned.
+func (s *Server) authenticate(ctx context.Context) (*security.Caller, error) {
+	if caller, ok := ctx.Value(callerContextKey{}).(*security.Caller); ok {
+		return caller, nil
+	}
+
+	var errs []error
+	for _, authenticator := range s.authenticators {
+		authCtx, span := tracer.Start(ctx, "Authenticate", trace.WithAttributes(attribute.String("authenticator", authenticator.AuthenticatorType())))
+		caller, err := authenticator.Authenticate(security.AuthContext{GrpcContext: authCtx})
+		tracing.EndSpan(span, err)
+		if err == nil {
+			return caller, nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("no authenticators configured")
+	}
+
+	return nil, errors.Join(errs...)
 }
 
 // identitiesMatch will ensure that two list of identities given from the
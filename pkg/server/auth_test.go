@@ -17,11 +17,19 @@ limitations under the License.
 package server
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	securityapi "istio.io/api/security/v1alpha1"
 	"istio.io/istio/pkg/kube"
@@ -36,6 +44,7 @@ import (
 	clienttesting "k8s.io/client-go/features/testing"
 	"k8s.io/klog/v2/ktesting"
 
+	"github.com/cert-manager/istio-csr/pkg/server/internal/sanpolicy"
 	"github.com/cert-manager/istio-csr/test/gen"
 )
 
@@ -263,7 +272,8 @@ func TestAuthRequestImpersonation(t *testing.T) {
 				ValidityDuration: 60 * 30,
 			}
 
-			identities, authed := s.authRequest(t.Context(), icr)
+			identities, _, _, err := s.authRequest(t.Context(), icr)
+			authed := err == nil
 			if identities != test.expIdenties {
 				t.Errorf("unexpected identities response, exp=%s got=%s",
 					test.expIdenties, identities)
@@ -450,12 +460,17 @@ func TestAuthRequest(t *testing.T) {
 				authenticators: test.authns,
 			}
 
-			identities, authed := s.authRequest(t.Context(), test.icr(t))
+			identities, csr, _, err := s.authRequest(t.Context(), test.icr(t))
+			authed := err == nil
 			if identities != test.expIdenties {
 				t.Errorf("unexpected identities response, exp=%s got=%s",
 					test.expIdenties, identities)
 			}
 
+			if authed && csr == nil {
+				t.Error("expected parsed CSR to be returned for an authorized request")
+			}
+
 			if authed != test.expAuth {
 				t.Errorf("unexpected authed response, exp=%t got=%t",
 					test.expAuth, authed)
@@ -463,3 +478,160 @@ func TestAuthRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRequestKeyPolicy(t *testing.T) {
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPolicy, err := newKeyPolicy(KeyPolicyOptions{
+		AllowedAlgorithms: []string{"RSA", "ECDSA"},
+		MinRSAKeySize:     2048,
+		AllowedCurves:     []string{"P-256"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		signer     crypto.Signer
+		expMessage string
+	}{
+		"if the CSR uses the default 2048-bit RSA key, return true": {
+			signer: nil,
+		},
+		"if the CSR uses an allowed curve, return true": {
+			signer: p256,
+		},
+		"if the CSR uses a 1024-bit RSA key, error": {
+			signer:     rsa1024,
+			expMessage: "CSR public key is not allowed: RSA key size 1024 is smaller than the minimum of 2048",
+		},
+		"if the CSR uses a curve which is not allowed, error": {
+			signer:     p224,
+			expMessage: "CSR public key is not allowed: elliptic curve P-224 is not allowed, must be one of P-256",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				log:            ktesting.NewLogger(t, ktesting.DefaultConfig),
+				authenticators: []security.Authenticator{newMockAuthn([]string{"spiffe://foo"}, "")},
+				keyPolicy:      keyPolicy,
+			}
+
+			mods := []gen.CSRModifier{gen.SetCSRIdentities([]string{"spiffe://foo"})}
+			if test.signer != nil {
+				mods = append(mods, gen.SetCSRSigner(test.signer))
+			}
+
+			_, _, _, err := s.authRequest(t.Context(), &securityapi.IstioCertificateRequest{
+				Csr: string(gen.MustCSR(t, mods...)),
+			})
+			if len(test.expMessage) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			st := status.Convert(s.statusError(err))
+			if st.Code() != codes.InvalidArgument || st.Message() != test.expMessage {
+				t.Errorf("unexpected status, exp=%q got=%s %q", test.expMessage, st.Code(), st.Message())
+			}
+		})
+	}
+}
+
+func TestAuthRequestSANPolicy(t *testing.T) {
+	const (
+		gateway = "spiffe://cluster.local/ns/istio-ingress/sa/istio-ingressgateway"
+		other   = "spiffe://cluster.local/ns/default/sa/default"
+	)
+
+	sanPolicy := &sanpolicy.Policy{
+		Rules: []sanpolicy.Rule{{
+			ServiceAccounts: []string{"istio-ingress/istio-ingressgateway"},
+			DNSNames:        []string{"*.example.com"},
+			IPAddresses:     []string{"203.0.113.0/24"},
+		}},
+	}
+	if err := sanPolicy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		sanPolicy *sanpolicy.Policy
+		identity  string
+		mods      []gen.CSRModifier
+		expReason errorReason
+	}{
+		"if no SAN policy, and the CSR has only URI SANs, return true": {
+			identity: gateway,
+		},
+		"if no SAN policy, and the CSR has DNS names, error": {
+			identity:  gateway,
+			mods:      []gen.CSRModifier{gen.SetCSRDNS([]string{"api.example.com"})},
+			expReason: reasonForbiddenCSRFields,
+		},
+		"if the CSR DNS names and IPs are allowed for the identity, return true": {
+			sanPolicy: sanPolicy,
+			identity:  gateway,
+			mods:      []gen.CSRModifier{gen.SetCSRDNS([]string{"api.example.com"}), gen.SetCSRIPs([]string{"203.0.113.1"})},
+		},
+		"if the CSR DNS names are not allowed for the identity, error": {
+			sanPolicy: sanPolicy,
+			identity:  gateway,
+			mods:      []gen.CSRModifier{gen.SetCSRDNS([]string{"api.example.org"})},
+			expReason: reasonSANsNotAllowed,
+		},
+		"if the CSR DNS names are allowed for a different identity, error": {
+			sanPolicy: sanPolicy,
+			identity:  other,
+			mods:      []gen.CSRModifier{gen.SetCSRDNS([]string{"api.example.com"})},
+			expReason: reasonSANsNotAllowed,
+		},
+		"if SAN policy, and the CSR has email addresses, error": {
+			sanPolicy: sanPolicy,
+			identity:  gateway,
+			mods:      []gen.CSRModifier{gen.SetCSREmails([]string{"gateway@example.com"})},
+			expReason: reasonForbiddenCSRFields,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				log:            ktesting.NewLogger(t, ktesting.DefaultConfig),
+				authenticators: []security.Authenticator{newMockAuthn([]string{test.identity}, "")},
+				sanPolicy:      test.sanPolicy,
+			}
+
+			mods := append([]gen.CSRModifier{gen.SetCSRIdentities([]string{test.identity})}, test.mods...)
+			_, _, _, err := s.authRequest(t.Context(), &securityapi.IstioCertificateRequest{
+				Csr: string(gen.MustCSR(t, mods...)),
+			})
+			if len(test.expReason) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var reqErr *requestError
+			if !errors.As(err, &reqErr) || reqErr.reason != test.expReason {
+				t.Errorf("unexpected error reason, exp=%s got=%v", test.expReason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"istio.io/istio/pkg/cluster"
+	"istio.io/istio/pkg/security"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	metricAuthCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "authentication_cache_hits",
+			Help:      "Total number of token authentications served from the authentication cache.",
+		},
+	)
+	metricAuthCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "authentication_cache_misses",
+			Help:      "Total number of token authentications not found in the authentication cache.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricAuthCacheHits, metricAuthCacheMisses)
+}
+
+// cachingAuthenticator caches the callers of successfully authenticated
+// bearer tokens, so that agents reusing the same token do not cause a
+// TokenReview on every request. Entries are keyed by the hash of the token
+// and the cluster the request was sent from, since the same token is reviewed
+// against each cluster by its own authenticator. Entries never outlive either the cache TTL or the expiry of the token. Failed
+// authentications are never cached.
+type cachingAuthenticator struct {
+	security.Authenticator
+
+	ttl   time.Duration
+	clock clock.PassiveClock
+	cache *cache.LRUExpireCache
+}
+
+// authCacheKey is the key of a cached caller.
+type authCacheKey struct {
+	clusterID cluster.ID
+	tokenHash [sha256.Size]byte
+}
+
+// newCachingAuthenticator returns an authenticator which caches the results
+// of the given authenticator for up to ttl, holding at most size entries.
+func newCachingAuthenticator(authenticator security.Authenticator, ttl time.Duration, size int) *cachingAuthenticator {
+	return newCachingAuthenticatorWithClock(authenticator, ttl, size, clock.RealClock{})
+}
+
+func newCachingAuthenticatorWithClock(authenticator security.Authenticator, ttl time.Duration, size int, clock clock.PassiveClock) *cachingAuthenticator {
+	return &cachingAuthenticator{
+		Authenticator: authenticator,
+		ttl:           ttl,
+		clock:         clock,
+		cache:         cache.NewLRUExpireCacheWithClock(size, clock),
+	}
+}
+
+// Authenticate returns the cached caller of the request's bearer token if
+// present, otherwise it authenticates the request and caches the result.
+func (c *cachingAuthenticator) Authenticate(ctx security.AuthContext) (*security.Caller, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return c.Authenticator.Authenticate(ctx)
+	}
+
+	key := authCacheKey{
+		clusterID: clusterIDFromContext(ctx.GrpcContext),
+		tokenHash: sha256.Sum256([]byte(token)),
+	}
+	if caller, ok := c.cache.Get(key); ok {
+		metricAuthCacheHits.Inc()
+		return copyCaller(caller.(*security.Caller)), nil
+	}
+	metricAuthCacheMisses.Inc()
+
+	caller, err := c.Authenticator.Authenticate(ctx)
+	if err != nil || caller == nil {
+		return caller, err
+	}
+
+	// Never cache tokens whose lifetime is unknown.
+	var ttl time.Duration
+	if claims, ok := parseTokenClaims(token); ok && claims.Exp != nil {
+		ttl = min(c.ttl, claims.expiry().Sub(c.clock.Now()))
+	}
+	if ttl > 0 {
+		c.cache.Add(key, copyCaller(caller), ttl)
+	}
+
+	return caller, nil
+}
+
+// copyCaller returns a copy of the caller, so that cached callers cannot be
+// modified by consumers.
+func copyCaller(caller *security.Caller) *security.Caller {
+	c := *caller
+	c.Identities = append([]string(nil), caller.Identities...)
+	return &c
+}
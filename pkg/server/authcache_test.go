@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"istio.io/istio/pkg/security"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+type countingAuthenticator struct {
+	calls  int
+	caller *security.Caller
+	err    error
+}
+
+func (c *countingAuthenticator) Authenticate(security.AuthContext) (*security.Caller, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return copyCaller(c.caller), nil
+}
+
+func (c *countingAuthenticator) AuthenticatorType() string {
+	return "CountingAuthenticator"
+}
+
+func testJWT(t *testing.T, claims string) string {
+	t.Helper()
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." + enc.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func tokenAuthContext(token string) security.AuthContext {
+	md := metadata.Pairs(authorizationMetadataKey, bearerTokenPrefix+token)
+	return security.AuthContext{GrpcContext: metadata.NewIncomingContext(context.Background(), md)}
+}
+
+func Test_cachingAuthenticator(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	caller := &security.Caller{
+		Identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+	}
+	expToken := func(exp time.Time) string {
+		return testJWT(t, fmt.Sprintf(`{"sub":"system:serviceaccount:sandbox:default","exp":%d}`, exp.Unix()))
+	}
+
+	tests := map[string]struct {
+		ctx      security.AuthContext
+		err      error
+		advance  time.Duration
+		expCalls int
+	}{
+		"if no bearer token is present, should always call the authenticator": {
+			ctx:      security.AuthContext{GrpcContext: context.Background()},
+			expCalls: 2,
+		},
+		"if the token has no expiry, should not cache the result": {
+			ctx:      tokenAuthContext(testJWT(t, `{"sub":"foo"}`)),
+			expCalls: 2,
+		},
+		"if the token is not a JWT, should not cache the result": {
+			ctx:      tokenAuthContext("not-a-jwt"),
+			expCalls: 2,
+		},
+		"if authentication fails, should not cache the result": {
+			ctx:      tokenAuthContext(expToken(now.Add(time.Hour))),
+			err:      errors.New("token review failed"),
+			expCalls: 2,
+		},
+		"if the token is authenticated, should serve the second request from the cache": {
+			ctx:      tokenAuthContext(expToken(now.Add(time.Hour))),
+			expCalls: 1,
+		},
+		"if the cache TTL has passed, should authenticate again": {
+			ctx:      tokenAuthContext(expToken(now.Add(time.Hour))),
+			advance:  time.Minute + time.Second,
+			expCalls: 2,
+		},
+		"if the token has expired before the cache TTL, should authenticate again": {
+			ctx:      tokenAuthContext(expToken(now.Add(10 * time.Second))),
+			advance:  11 * time.Second,
+			expCalls: 2,
+		},
+		"if the token has already expired, should not cache the result": {
+			ctx:      tokenAuthContext(expToken(now.Add(-time.Second))),
+			expCalls: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := clocktesting.NewFakePassiveClock(now)
+			inner := &countingAuthenticator{caller: caller, err: test.err}
+			c := newCachingAuthenticatorWithClock(inner, time.Minute, 10, clock)
+
+			for range 2 {
+				got, err := c.Authenticate(test.ctx)
+				if (err != nil) != (test.err != nil) {
+					t.Fatalf("unexpected error, exp=%v got=%v", test.err, err)
+				}
+				if test.err == nil && (len(got.Identities) != 1 || got.Identities[0] != caller.Identities[0]) {
+					t.Errorf("unexpected caller identities: %v", got.Identities)
+				}
+				clock.SetTime(clock.Now().Add(test.advance))
+			}
+
+			if inner.calls != test.expCalls {
+				t.Errorf("unexpected number of authenticator calls, exp=%d got=%d", test.expCalls, inner.calls)
+			}
+		})
+	}
+}
+
+func Test_cachingAuthenticatorIsolatesCallers(t *testing.T) {
+	inner := &countingAuthenticator{caller: &security.Caller{Identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"}}}
+	c := newCachingAuthenticator(inner, time.Minute, 10)
+	ctx := tokenAuthContext(testJWT(t, fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix())))
+
+	first, err := c.Authenticate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Identities[0] = "spiffe://cluster.local/ns/other/sa/default"
+
+	second, err := c.Authenticate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Identities[0] != "spiffe://cluster.local/ns/sandbox/sa/default" {
+		t.Errorf("cached caller was modified: %v", second.Identities)
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected a single authenticator call, got %d", inner.calls)
+	}
+}
+
+func Test_cachingAuthenticatorSeparatesClusters(t *testing.T) {
+	inner := &countingAuthenticator{caller: &security.Caller{Identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"}}}
+	c := newCachingAuthenticator(inner, time.Minute, 10)
+	token := testJWT(t, fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix()))
+
+	clusterCtx := func(clusterID string) security.AuthContext {
+		md := metadata.Pairs(authorizationMetadataKey, bearerTokenPrefix+token, clusterIDMetadataKey, clusterID)
+		return security.AuthContext{GrpcContext: metadata.NewIncomingContext(context.Background(), md)}
+	}
+
+	for _, clusterID := range []string{"cluster-a", "cluster-b", "cluster-a"} {
+		if _, err := c.Authenticate(clusterCtx(clusterID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if inner.calls != 2 {
+		t.Errorf("expected the token to be authenticated once per cluster, got %d authenticator calls", inner.calls)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+
+	"github.com/cert-manager/istio-csr/pkg/server/authz"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/policy"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
+)
+
+// authorizeExternally asks the external policy service to approve the
+// authenticated request. Returns a request error if the request was denied,
+// or the policy service failed and fail-open is not enabled.
+func (s *Server) authorizeExternally(ctx context.Context, icr *securityapi.IstioCertificateRequest, csr *x509.CertificateRequest, identities []string, caller *security.Caller) error {
+	uris := make([]string, len(csr.URIs))
+	for i, uri := range csr.URIs {
+		uris[i] = uri.String()
+	}
+
+	req := &authz.Request{
+		Identities: identities,
+		Caller: authz.Caller{
+			Identities:     caller.Identities,
+			Namespace:      caller.KubernetesInfo.PodNamespace,
+			ServiceAccount: caller.KubernetesInfo.PodServiceAccount,
+			PodName:        caller.KubernetesInfo.PodName,
+			PodUID:         caller.KubernetesInfo.PodUID,
+		},
+		ImpersonatedIdentity: icr.GetMetadata().GetFields()[security.ImpersonatedIdentity].GetStringValue(),
+		ClusterID:            string(clusterIDFromContext(ctx)),
+		RequestedDuration:    (time.Duration(icr.GetValidityDuration()) * time.Second).String(),
+		CSR: authz.CSR{
+			URIs:               uris,
+			DNSNames:           csr.DNSNames,
+			IPAddresses:        ipStrings(csr.IPAddresses),
+			PublicKeyAlgorithm: csr.PublicKeyAlgorithm.String(),
+			PublicKeySize:      policy.KeySize(csr.PublicKey),
+			SignatureAlgorithm: csr.SignatureAlgorithm.String(),
+		},
+	}
+
+	authzCtx, span := tracer.Start(ctx, "ExternalAuthorization")
+	decision, err := s.authorizer.Authorize(authzCtx, req)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return newRequestError(codes.Unavailable, reasonExternalAuthorizationFailed, "external authorization failed", err)
+	}
+
+	if !decision.Allowed {
+		return newRequestError(codes.PermissionDenied, reasonExternalAuthorizationDenied, "certificate request was denied by external authorization", errors.New(decision.Reason))
+	}
+
+	return nil
+}
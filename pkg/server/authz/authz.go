@@ -0,0 +1,241 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package authz provides an optional external authorization hook, which asks
+// a policy service to approve each certificate request before it is signed.
+// The policy service is reached over HTTPS, or over gRPC.
+package authz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	metricDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "external_authorization_decisions",
+			Help:      "Total number of external authorization decisions, by result.",
+		},
+		[]string{"result"},
+	)
+	metricCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "external_authorization_cache_hits",
+			Help:      "Total number of external authorization decisions served from the cache.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricDecisions, metricCacheHits)
+}
+
+// Options configures the external authorization hook.
+type Options struct {
+	// Endpoint is the URL of the policy service. The scheme selects the
+	// transport: https or http POST the request as JSON, grpcs or grpc call
+	// the Authorize method over gRPC with or without TLS. If empty, external
+	// authorization is disabled.
+	Endpoint string
+
+	// CAFile is an optional file containing PEM CA certificates used to
+	// verify the policy service. If empty, the system roots are used.
+	CAFile string
+
+	// Timeout is the maximum duration to wait for a decision.
+	Timeout time.Duration
+
+	// FailOpen allows requests when the policy service cannot be reached or
+	// returns an error. If false, such requests are rejected.
+	FailOpen bool
+
+	// CacheTTL is the duration decisions are cached for. A value of 0
+	// disables the cache.
+	CacheTTL time.Duration
+
+	// CacheSize is the maximum number of cached decisions.
+	CacheSize int
+}
+
+// Request is sent to the policy service to authorize a certificate request.
+type Request struct {
+	// Identities are the identities the certificate will be issued for.
+	Identities []string `json:"identities"`
+
+	// Caller is the authenticated caller of the request.
+	Caller Caller `json:"caller"`
+
+	// ImpersonatedIdentity is the identity requested by a node proxy on
+	// behalf of a workload on its node, if any.
+	ImpersonatedIdentity string `json:"impersonatedIdentity,omitempty"`
+
+	// ClusterID is the ID of the cluster the caller is running in, if sent.
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// RequestedDuration is the certificate duration requested by the caller.
+	RequestedDuration string `json:"requestedDuration"`
+
+	// CSR holds the details of the parsed certificate signing request.
+	CSR CSR `json:"csr"`
+}
+
+// Caller is the authenticated caller of a certificate request.
+type Caller struct {
+	Identities     []string `json:"identities"`
+	Namespace      string   `json:"namespace,omitempty"`
+	ServiceAccount string   `json:"serviceAccount,omitempty"`
+	PodName        string   `json:"podName,omitempty"`
+	PodUID         string   `json:"podUID,omitempty"`
+}
+
+// CSR holds the details of a parsed certificate signing request.
+type CSR struct {
+	URIs        []string `json:"uris"`
+	DNSNames    []string `json:"dnsNames,omitempty"`
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+
+	PublicKeyAlgorithm string `json:"publicKeyAlgorithm"`
+	// PublicKeySize is the size in bits of the public key. For ECDSA keys
+	// this is the size of the curve.
+	PublicKeySize      int    `json:"publicKeySize"`
+	SignatureAlgorithm string `json:"signatureAlgorithm"`
+}
+
+// Decision is the response of the policy service.
+type Decision struct {
+	// Allowed is true if the certificate may be issued.
+	Allowed bool `json:"allowed"`
+
+	// Reason is an optional human readable reason for the decision.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Authorizer authorizes certificate requests.
+type Authorizer interface {
+	Authorize(ctx context.Context, req *Request) (*Decision, error)
+}
+
+// authorizer wraps the transport to the policy service, applying the
+// timeout, fail mode and decision cache.
+type authorizer struct {
+	transport Authorizer
+	opts      Options
+	cache     *cache.LRUExpireCache
+}
+
+// New returns an Authorizer for the configured policy service. Returns nil if
+// no endpoint is configured.
+func New(opts Options) (Authorizer, error) {
+	if len(opts.Endpoint) == 0 {
+		return nil, nil
+	}
+
+	if opts.Timeout <= 0 {
+		return nil, fmt.Errorf("external authorization timeout must be positive, got %s", opts.Timeout)
+	}
+	if opts.CacheTTL > 0 && opts.CacheSize < 1 {
+		return nil, fmt.Errorf("external authorization cache size must be at least 1 when the cache is enabled, got %d", opts.CacheSize)
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external authorization endpoint: %w", err)
+	}
+
+	var transport Authorizer
+	switch endpoint.Scheme {
+	case "https", "http":
+		transport, err = newHTTPAuthorizer(endpoint, opts.CAFile)
+	case "grpcs", "grpc":
+		transport, err = newGRPCAuthorizer(endpoint, opts.CAFile)
+	default:
+		return nil, fmt.Errorf("unsupported external authorization endpoint scheme %q, must be one of https, http, grpcs or grpc", endpoint.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newAuthorizer(transport, opts), nil
+}
+
+func newAuthorizer(transport Authorizer, opts Options) *authorizer {
+	a := &authorizer{
+		transport: transport,
+		opts:      opts,
+	}
+	if opts.CacheTTL > 0 {
+		a.cache = cache.NewLRUExpireCache(opts.CacheSize)
+	}
+	return a
+}
+
+// Authorize returns the decision of the policy service for the request.
+// Decisions are cached by the content of the request. If the policy service
+// fails, the request is allowed in fail-open mode, otherwise the error is
+// returned.
+func (a *authorizer) Authorize(ctx context.Context, req *Request) (*Decision, error) {
+	var key [sha256.Size]byte
+	if a.cache != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode external authorization request: %w", err)
+		}
+		key = sha256.Sum256(data)
+		if decision, ok := a.cache.Get(key); ok {
+			metricCacheHits.Inc()
+			d := *decision.(*Decision)
+			return &d, nil
+		}
+	}
+
+	authzCtx, cancel := context.WithTimeout(ctx, a.opts.Timeout)
+	defer cancel()
+
+	decision, err := a.transport.Authorize(authzCtx, req)
+	if err != nil {
+		metricDecisions.WithLabelValues("error").Inc()
+		if a.opts.FailOpen {
+			logr.FromContextOrDiscard(ctx).Error(err, "external authorization failed, allowing request as fail-open is enabled")
+			return &Decision{Allowed: true, Reason: "external authorization failed open"}, nil
+		}
+		return nil, err
+	}
+
+	if decision.Allowed {
+		metricDecisions.WithLabelValues("allowed").Inc()
+	} else {
+		metricDecisions.WithLabelValues("denied").Inc()
+	}
+
+	if a.cache != nil {
+		d := *decision
+		a.cache.Add(key, &d, a.opts.CacheTTL)
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type fakeAuthorizer struct {
+	calls    int
+	decision *Decision
+	err      error
+	delay    time.Duration
+	lastReq  *Request
+}
+
+func (f *fakeAuthorizer) Authorize(ctx context.Context, req *Request) (*Decision, error) {
+	f.calls++
+	f.lastReq = req
+	if f.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.delay):
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	d := *f.decision
+	return &d, nil
+}
+
+func testRequest() *Request {
+	return &Request{
+		Identities:        []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+		Caller:            Caller{Identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"}, Namespace: "sandbox", ServiceAccount: "default"},
+		RequestedDuration: "1h0m0s",
+		CSR:               CSR{URIs: []string{"spiffe://cluster.local/ns/sandbox/sa/default"}, PublicKeyAlgorithm: "ECDSA", SignatureAlgorithm: "ECDSA-SHA256"},
+	}
+}
+
+func Test_authorizer(t *testing.T) {
+	tests := map[string]struct {
+		transport *fakeAuthorizer
+		opts      Options
+
+		expAllowed bool
+		expErr     bool
+		expCalls   int
+	}{
+		"if allowed and no cache, should call the transport for every request": {
+			transport:  &fakeAuthorizer{decision: &Decision{Allowed: true}},
+			opts:       Options{Timeout: time.Second},
+			expAllowed: true,
+			expCalls:   2,
+		},
+		"if allowed and cache enabled, should call the transport once": {
+			transport:  &fakeAuthorizer{decision: &Decision{Allowed: true}},
+			opts:       Options{Timeout: time.Second, CacheTTL: time.Minute, CacheSize: 10},
+			expAllowed: true,
+			expCalls:   1,
+		},
+		"if denied and cache enabled, should cache the denial": {
+			transport:  &fakeAuthorizer{decision: &Decision{Allowed: false, Reason: "quarantined"}},
+			opts:       Options{Timeout: time.Second, CacheTTL: time.Minute, CacheSize: 10},
+			expAllowed: false,
+			expCalls:   1,
+		},
+		"if the transport fails and fail-closed, should error and not cache": {
+			transport: &fakeAuthorizer{err: errors.New("connection refused")},
+			opts:      Options{Timeout: time.Second, CacheTTL: time.Minute, CacheSize: 10},
+			expErr:    true,
+			expCalls:  2,
+		},
+		"if the transport fails and fail-open, should allow and not cache": {
+			transport:  &fakeAuthorizer{err: errors.New("connection refused")},
+			opts:       Options{Timeout: time.Second, FailOpen: true, CacheTTL: time.Minute, CacheSize: 10},
+			expAllowed: true,
+			expCalls:   2,
+		},
+		"if the transport times out and fail-closed, should error": {
+			transport: &fakeAuthorizer{decision: &Decision{Allowed: true}, delay: time.Second},
+			opts:      Options{Timeout: 10 * time.Millisecond},
+			expErr:    true,
+			expCalls:  2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := newAuthorizer(test.transport, test.opts)
+
+			for range 2 {
+				decision, err := a.Authorize(t.Context(), testRequest())
+				if (err != nil) != test.expErr {
+					t.Fatalf("unexpected error, exp=%t got=%v", test.expErr, err)
+				}
+				if err == nil && decision.Allowed != test.expAllowed {
+					t.Errorf("unexpected decision, exp=%t got=%t", test.expAllowed, decision.Allowed)
+				}
+			}
+
+			if test.transport.calls != test.expCalls {
+				t.Errorf("unexpected number of transport calls, exp=%d got=%d", test.expCalls, test.transport.calls)
+			}
+		})
+	}
+}
+
+func Test_New(t *testing.T) {
+	tests := map[string]struct {
+		opts   Options
+		expNil bool
+		expErr bool
+	}{
+		"if no endpoint, should return nil": {
+			opts:   Options{},
+			expNil: true,
+		},
+		"if https endpoint, should return an authorizer": {
+			opts: Options{Endpoint: "https://policy.example:8443/authorize", Timeout: time.Second},
+		},
+		"if grpc endpoint, should return an authorizer": {
+			opts: Options{Endpoint: "grpc://policy.example:9090", Timeout: time.Second},
+		},
+		"if unsupported scheme, should error": {
+			opts:   Options{Endpoint: "ftp://policy.example", Timeout: time.Second},
+			expErr: true,
+		},
+		"if no timeout, should error": {
+			opts:   Options{Endpoint: "https://policy.example"},
+			expErr: true,
+		},
+		"if cache enabled with no size, should error": {
+			opts:   Options{Endpoint: "https://policy.example", Timeout: time.Second, CacheTTL: time.Minute},
+			expErr: true,
+		},
+		"if CA file does not exist, should error": {
+			opts:   Options{Endpoint: "https://policy.example", Timeout: time.Second, CAFile: "/does/not/exist"},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, err := New(test.opts)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error, exp=%t got=%v", test.expErr, err)
+			}
+			if err == nil && (a == nil) != test.expNil {
+				t.Errorf("unexpected authorizer, exp nil=%t got=%v", test.expNil, a)
+			}
+		})
+	}
+}
+
+func Test_httpAuthorizer(t *testing.T) {
+	var got Request
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if got.Caller.Namespace == "quarantine" {
+			_ = json.NewEncoder(w).Encode(Decision{Allowed: false, Reason: "namespace is quarantined"})
+			return
+		}
+		if got.Caller.Namespace == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Decision{Allowed: true})
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Options{Endpoint: srv.URL + "/authorize", CAFile: caFile, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision, err := a.Authorize(t.Context(), testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decision.Allowed {
+		t.Errorf("expected request to be allowed")
+	}
+	if got.CSR.PublicKeyAlgorithm != "ECDSA" || got.RequestedDuration != "1h0m0s" || got.Caller.ServiceAccount != "default" {
+		t.Errorf("unexpected request sent to policy service: %+v", got)
+	}
+
+	req := testRequest()
+	req.Caller.Namespace = "quarantine"
+	decision, err = a.Authorize(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed || decision.Reason != "namespace is quarantined" {
+		t.Errorf("expected request to be denied, got %+v", decision)
+	}
+
+	req.Caller.Namespace = "broken"
+	if _, err := a.Authorize(t.Context(), req); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+
+	// Without the CA, the policy service should not be trusted.
+	a, err = New(Options{Endpoint: srv.URL + "/authorize", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Authorize(t.Context(), testRequest()); err == nil {
+		t.Error("expected error for untrusted policy service")
+	}
+}
+
+func Test_grpcAuthorizer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &fakeAuthorizer{decision: &Decision{Allowed: false, Reason: "identity not approved"}}
+	srv := grpc.NewServer()
+	RegisterAuthorizerServer(srv, policy)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	a, err := New(Options{Endpoint: "grpc://" + lis.Addr().String(), Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision, err := a.Authorize(t.Context(), testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed || decision.Reason != "identity not approved" {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+	if policy.lastReq == nil || policy.lastReq.Caller.Namespace != "sandbox" || len(policy.lastReq.CSR.URIs) != 1 {
+		t.Errorf("unexpected request received by policy service: %+v", policy.lastReq)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ServiceName is the name of the gRPC service implemented by policy
+	// services.
+	ServiceName = "istiocsr.authorization.v1.Authorizer"
+
+	// AuthorizeMethod is the full name of the gRPC method called to authorize
+	// a request. The request and response are google.protobuf.Struct
+	// messages, holding the same fields as the JSON Request and Decision.
+	AuthorizeMethod = "/" + ServiceName + "/Authorize"
+)
+
+// grpcAuthorizer calls the Authorize method of the policy service over gRPC.
+type grpcAuthorizer struct {
+	conn *grpc.ClientConn
+}
+
+func newGRPCAuthorizer(endpoint *url.URL, caFile string) (*grpcAuthorizer, error) {
+	creds := insecure.NewCredentials()
+	if endpoint.Scheme == "grpcs" {
+		tlsConfig, err := loadTLSConfig(caFile)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(endpoint.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external authorization gRPC client: %w", err)
+	}
+
+	return &grpcAuthorizer{conn: conn}, nil
+}
+
+func (g *grpcAuthorizer) Authorize(ctx context.Context, req *Request) (*Decision, error) {
+	in, err := toStruct(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode external authorization request: %w", err)
+	}
+
+	out := new(structpb.Struct)
+	if err := g.conn.Invoke(ctx, AuthorizeMethod, in, out); err != nil {
+		return nil, fmt.Errorf("external authorization request failed: %w", err)
+	}
+
+	var decision Decision
+	if err := fromStruct(out, &decision); err != nil {
+		return nil, fmt.Errorf("failed to decode external authorization decision: %w", err)
+	}
+
+	return &decision, nil
+}
+
+// RegisterAuthorizerServer registers a policy service implementing the
+// Authorize gRPC method with the gRPC server.
+func RegisterAuthorizerServer(s *grpc.Server, authorizer Authorizer) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Authorizer)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Authorize",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(structpb.Struct)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, in any) (any, error) {
+					var req Request
+					if err := fromStruct(in.(*structpb.Struct), &req); err != nil {
+						return nil, status.Errorf(codes.InvalidArgument, "invalid authorization request: %v", err)
+					}
+
+					decision, err := srv.(Authorizer).Authorize(ctx, &req)
+					if err != nil {
+						return nil, err
+					}
+
+					return toStruct(decision)
+				}
+
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: AuthorizeMethod}, handler)
+			},
+		}},
+	}, authorizer)
+}
+
+// toStruct converts v to a Struct, using its JSON encoding.
+func toStruct(v any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(structpb.Struct)
+	if err := protojson.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// fromStruct decodes the Struct into v, using its JSON encoding.
+func fromStruct(s *structpb.Struct, v any) error {
+	data, err := protojson.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
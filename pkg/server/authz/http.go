@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// maxResponseBytes is the maximum size of a decision read from the policy
+// service.
+const maxResponseBytes = 1 << 20
+
+// httpAuthorizer POSTs requests as JSON to the policy service, which must
+// respond with 200 and a JSON decision.
+type httpAuthorizer struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHTTPAuthorizer(endpoint *url.URL, caFile string) (*httpAuthorizer, error) {
+	tlsConfig, err := loadTLSConfig(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &httpAuthorizer{
+		endpoint: endpoint.String(),
+		client:   &http.Client{Transport: transport},
+	}, nil
+}
+
+func (h *httpAuthorizer) Authorize(ctx context.Context, req *Request) (*Decision, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode external authorization request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build external authorization request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("external authorization request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("external authorization request returned unexpected status %q", resp.Status)
+	}
+
+	var decision Decision
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("failed to decode external authorization decision: %w", err)
+	}
+
+	return &decision, nil
+}
+
+// loadTLSConfig returns the TLS config used to connect to the policy service,
+// trusting the CAs in caFile if set, otherwise the system roots.
+func loadTLSConfig(caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caFile) == 0 {
+		return tlsConfig, nil
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read external authorization CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in external authorization CA file %q", caFile)
+	}
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
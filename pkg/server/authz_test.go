@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	pkiutil "istio.io/istio/security/pkg/pki/util"
+	"k8s.io/klog/v2/ktesting"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+	cmfake "github.com/cert-manager/istio-csr/pkg/certmanager/fake"
+	"github.com/cert-manager/istio-csr/pkg/server/authz"
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+type fakeExternalAuthorizer struct {
+	decision *authz.Decision
+	err      error
+	req      *authz.Request
+}
+
+func (f *fakeExternalAuthorizer) Authorize(_ context.Context, req *authz.Request) (*authz.Decision, error) {
+	f.req = req
+	return f.decision, f.err
+}
+
+func Test_CreateCertificateExternalAuthorization(t *testing.T) {
+	const spiffeDomain = "spiffe://foo"
+
+	tests := map[string]struct {
+		authorizer authz.Authorizer
+
+		expCode   codes.Code
+		expSigned bool
+	}{
+		"if no external authorizer is configured, should sign": {
+			authorizer: nil,
+			expCode:    codes.OK,
+			expSigned:  true,
+		},
+		"if the external authorizer allows the request, should sign": {
+			authorizer: &fakeExternalAuthorizer{decision: &authz.Decision{Allowed: true}},
+			expCode:    codes.OK,
+			expSigned:  true,
+		},
+		"if the external authorizer denies the request, should return PermissionDenied and not sign": {
+			authorizer: &fakeExternalAuthorizer{decision: &authz.Decision{Allowed: false, Reason: "quarantined"}},
+			expCode:    codes.PermissionDenied,
+			expSigned:  false,
+		},
+		"if the external authorizer fails, should return Unavailable and not sign": {
+			authorizer: &fakeExternalAuthorizer{err: errors.New("connection refused")},
+			expCode:    codes.Unavailable,
+			expSigned:  false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var signed bool
+			s := &Server{
+				opts: Options{
+					MaximumClientCertificateDuration: time.Hour,
+				},
+				authenticators: []security.Authenticator{
+					newMockAuthn([]string{spiffeDomain}, ""),
+				},
+				log: ktesting.NewLogger(t, ktesting.DefaultConfig),
+				cm: cmfake.New().WithSign(func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					signed = true
+					return certmanager.Bundle{}, errors.New("generic error")
+				}),
+				authorizer: test.authorizer,
+			}
+
+			icr := &securityapi.IstioCertificateRequest{
+				Csr: string(gen.MustCSR(t,
+					gen.SetCSRIdentities([]string{spiffeDomain}),
+				)),
+				ValidityDuration: 600,
+			}
+
+			_, err := s.CreateCertificate(t.Context(), icr)
+			assert.Equal(t, test.expSigned, signed)
+			if test.expCode != codes.OK {
+				assert.Equal(t, test.expCode, status.Code(err), "%v", err)
+			}
+
+			if fake, ok := test.authorizer.(*fakeExternalAuthorizer); ok {
+				if assert.NotNil(t, fake.req) {
+					assert.Equal(t, []string{spiffeDomain}, fake.req.Identities)
+					assert.Equal(t, []string{spiffeDomain}, fake.req.CSR.URIs)
+					assert.Equal(t, "10m0s", fake.req.RequestedDuration)
+				}
+			}
+		})
+	}
+}
+
+func Test_authorizeExternallyCSR(t *testing.T) {
+	const gateway = "spiffe://cluster.local/ns/istio-ingress/sa/istio-ingressgateway"
+
+	csr, err := pkiutil.ParsePemEncodedCSR(gen.MustCSR(t,
+		gen.SetCSRIdentities([]string{gateway}),
+		gen.SetCSRDNS([]string{"api.example.com"}),
+		gen.SetCSRIPs([]string{"203.0.113.1"}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeExternalAuthorizer{decision: &authz.Decision{Allowed: true}}
+	s := &Server{authorizer: fake}
+
+	if err := s.authorizeExternally(t.Context(), &securityapi.IstioCertificateRequest{ValidityDuration: 600}, csr,
+		[]string{gateway}, &security.Caller{Identities: []string{gateway}}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, authz.CSR{
+		URIs:               []string{gateway},
+		DNSNames:           []string{"api.example.com"},
+		IPAddresses:        []string{"203.0.113.1"},
+		PublicKeyAlgorithm: "RSA",
+		PublicKeySize:      2048,
+		SignatureAlgorithm: "SHA256-RSA",
+	}, fake.req.CSR)
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/yaml"
+
+	"github.com/cert-manager/istio-csr/pkg/filewatch"
+	"github.com/cert-manager/istio-csr/pkg/kubewatch"
+	"github.com/cert-manager/istio-csr/pkg/server/audit"
+)
+
+const (
+	// denylistConfigMapKey is the key of the denylist ConfigMap holding the
+	// denylist.
+	denylistConfigMapKey = "denylist.yaml"
+)
+
+var (
+	metricDenylistBlocked = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "denylist_blocked_requests",
+			Help:      "Total number of certificate requests blocked by the identity denylist, by the type of the matching entry.",
+		},
+		[]string{"match"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricDenylistBlocked)
+}
+
+// DenylistOptions configures the identity denylist. Certificates are never
+// issued for denylisted identities. At most one of File or ConfigMapName may
+// be set.
+type DenylistOptions struct {
+	// File is an optional path to a denylist file, which is reloaded when
+	// changed.
+	File string
+
+	// ConfigMapNamespace and ConfigMapName are of an optional denylist
+	// ConfigMap, which is watched for changes. The denylist is read from the
+	// "denylist.yaml" key.
+	ConfigMapNamespace string
+	ConfigMapName      string
+}
+
+// denylistFile is the format of the denylist.
+type denylistFile struct {
+	// SPIFFEIDs are denylisted SPIFFE IDs.
+	SPIFFEIDs []string `json:"spiffeIDs,omitempty"`
+
+	// Namespaces are namespaces whose workloads are denylisted.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ServiceAccounts are denylisted service accounts, in the form
+	// "<namespace>/<name>".
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// denylistEntries is a parsed denylist.
+type denylistEntries struct {
+	spiffeIDs       sets.Set[string]
+	namespaces      sets.Set[string]
+	serviceAccounts sets.Set[types.NamespacedName]
+}
+
+// parseDenylist decodes and validates the given denylist.
+func parseDenylist(data []byte) (*denylistEntries, error) {
+	var file denylistFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode denylist: %w", err)
+	}
+
+	entries := &denylistEntries{
+		spiffeIDs:       sets.New[string](),
+		namespaces:      sets.New(file.Namespaces...),
+		serviceAccounts: sets.New[types.NamespacedName](),
+	}
+
+	var errs []error
+	for _, id := range file.SPIFFEIDs {
+		if u, err := url.Parse(id); err != nil || u.Scheme != "spiffe" || len(u.Host) == 0 {
+			errs = append(errs, fmt.Errorf("invalid SPIFFE ID %q", id))
+			continue
+		}
+		entries.spiffeIDs.Insert(id)
+	}
+
+	for _, sa := range file.ServiceAccounts {
+		ns, name, ok := strings.Cut(sa, "/")
+		if !ok || len(ns) == 0 || len(name) == 0 {
+			errs = append(errs, fmt.Errorf("invalid service account %q, expected the form <namespace>/<name>", sa))
+			continue
+		}
+		entries.serviceAccounts.Insert(types.NamespacedName{Namespace: ns, Name: name})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// match returns the type of the entry matching the identity, and whether the
+// identity is denylisted.
+func (d *denylistEntries) match(identity string) (string, bool) {
+	if d.spiffeIDs.Contains(identity) {
+		return "spiffe_id", true
+	}
+
+	id, err := spiffe.ParseIdentity(identity)
+	if err != nil {
+		return "", false
+	}
+
+	if d.namespaces.Contains(id.Namespace) {
+		return "namespace", true
+	}
+
+	if d.serviceAccounts.Contains(types.NamespacedName{Namespace: id.Namespace, Name: id.ServiceAccount}) {
+		return "service_account", true
+	}
+
+	return "", false
+}
+
+// denylist holds the live identity denylist, read from either a file or a
+// ConfigMap.
+type denylist struct {
+	log    logr.Logger
+	opts   DenylistOptions
+	client client.WithWatch
+
+	data    []byte
+	synced  atomic.Bool
+	entries atomic.Pointer[denylistEntries]
+}
+
+// newDenylist returns a new denylist. If a file is configured, it is read
+// immediately. A ConfigMap is read once started.
+func newDenylist(log logr.Logger, k8sClient client.WithWatch, opts DenylistOptions) (*denylist, error) {
+	d := &denylist{
+		log:    log.WithName("denylist"),
+		opts:   opts,
+		client: k8sClient,
+	}
+
+	if len(opts.File) > 0 {
+		d.log = d.log.WithValues("file", opts.File)
+		if err := d.loadFile(); err != nil {
+			return nil, err
+		}
+	} else {
+		d.log = d.log.WithValues("config-map-name", opts.ConfigMapName, "config-map-namespace", opts.ConfigMapNamespace)
+	}
+
+	return d, nil
+}
+
+// check returns the identity, and the type of the matching entry, of the
+// first denylisted identity. Returns an error if the denylist has not yet been
+// read.
+func (d *denylist) check(identities []string) (string, string, error) {
+	if !d.synced.Load() {
+		return "", "", errors.New("denylist has not been read")
+	}
+
+	entries := d.entries.Load()
+	for _, identity := range identities {
+		if match, ok := entries.match(identity); ok {
+			return identity, match, nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// update parses and sets the live denylist. If the denylist is invalid, the
+// previous denylist remains in use.
+func (d *denylist) update(data []byte) error {
+	entries, err := parseDenylist(data)
+	if err != nil {
+		return err
+	}
+
+	d.data = data
+	d.entries.Store(entries)
+	d.synced.Store(true)
+
+	d.log.Info("updated denylist", "spiffe-ids", entries.spiffeIDs.Len(), "namespaces", entries.namespaces.Len(), "service-accounts", entries.serviceAccounts.Len())
+
+	return nil
+}
+
+// Start watches the denylist source for changes until the context is
+// cancelled.
+func (d *denylist) Start(ctx context.Context) {
+	if len(d.opts.File) > 0 {
+		d.watchFile(ctx)
+	} else {
+		d.watchConfigMap(ctx)
+	}
+}
+
+// loadFile reads the denylist file, updating the live denylist if changed.
+func (d *denylist) loadFile() error {
+	data, err := os.ReadFile(d.opts.File)
+	if err != nil {
+		return fmt.Errorf("failed to read denylist file %q: %w", d.opts.File, err)
+	}
+
+	if d.synced.Load() && bytes.Equal(data, d.data) {
+		return nil
+	}
+
+	if err := d.update(data); err != nil {
+		return fmt.Errorf("invalid denylist file %q: %w", d.opts.File, err)
+	}
+
+	return nil
+}
+
+// watchFile reloads the denylist file when it changes.
+func (d *denylist) watchFile(ctx context.Context) {
+	watcher, err := filewatch.New(d.log, d.opts.File)
+	if err != nil {
+		d.log.Error(err, "failed to watch denylist file, denylist will not be reloaded")
+		return
+	}
+
+	// Pick up any changes made since the denylist was first read.
+	d.reloadFile()
+
+	watcher.Run(ctx, d.reloadFile)
+}
+
+// reloadFile reloads the denylist file, logging any error.
+func (d *denylist) reloadFile() {
+	if err := d.loadFile(); err != nil {
+		d.log.Error(err, "failed to reload denylist file, continuing to use the previous denylist")
+	}
+}
+
+// watchConfigMap watches the denylist ConfigMap. A missing or deleted
+// ConfigMap is an empty denylist.
+func (d *denylist) watchConfigMap(ctx context.Context) {
+	kubewatch.Run(ctx, d.log, "denylist ConfigMap", func(ctx context.Context) (watch.Interface, error) {
+		watcher, err := d.client.Watch(ctx, &corev1.ConfigMapList{}, &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", d.opts.ConfigMapName),
+			Namespace:     d.opts.ConfigMapNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// The watch sends an Added event if the ConfigMap exists. Read it
+		// directly so that a missing ConfigMap is known to be empty.
+		var cm corev1.ConfigMap
+		err = d.client.Get(ctx, types.NamespacedName{Namespace: d.opts.ConfigMapNamespace, Name: d.opts.ConfigMapName}, &cm)
+		switch {
+		case apierrors.IsNotFound(err):
+			d.updateConfigMap(nil)
+		case err != nil:
+			d.log.Error(err, "Failed to get denylist ConfigMap")
+		}
+
+		return watcher, nil
+	}, func(event watch.Event) {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			if cm, ok := event.Object.(*corev1.ConfigMap); ok {
+				d.updateConfigMap(cm)
+			}
+
+		case watch.Deleted:
+			d.log.Info("denylist ConfigMap deleted, using an empty denylist")
+			d.updateConfigMap(nil)
+
+		case watch.Error:
+			d.log.Error(apierrors.FromObject(event.Object), "Got an error event when watching the denylist ConfigMap")
+		}
+	})
+}
+
+// updateConfigMap updates the live denylist from the ConfigMap. A nil
+// ConfigMap is an empty denylist.
+func (d *denylist) updateConfigMap(cm *corev1.ConfigMap) {
+	var data []byte
+	if cm != nil {
+		data = []byte(cm.Data[denylistConfigMapKey])
+	}
+
+	if d.synced.Load() && bytes.Equal(data, d.data) {
+		return
+	}
+
+	if err := d.update(data); err != nil {
+		d.log.Error(err, "Invalid denylist ConfigMap, continuing to use the previous denylist")
+	}
+}
+
+// checkDenylist rejects the request if any of the identities the certificate
+// is requested for, or of the authenticated caller, is denylisted. Blocked
+// requests are marked as denylisted on the audit record.
+func (s *Server) checkDenylist(record *audit.Record, identities []string, callerIdentities []string) error {
+	identity, match, err := s.denylist.check(s.denylistCandidates(slices.Concat(identities, callerIdentities)))
+	if err != nil {
+		return newRequestError(codes.Unavailable, reasonDenylistUnavailable, "identity denylist is not available", err)
+	}
+
+	if len(identity) == 0 {
+		return nil
+	}
+
+	metricDenylistBlocked.WithLabelValues(match).Inc()
+	record.Denylisted = true
+	return newRequestError(codes.PermissionDenied, reasonIdentityDenylisted, "identity is denylisted",
+		fmt.Errorf("identity %q matches a denylisted %s", identity, strings.ReplaceAll(match, "_", " ")))
+}
+
+// denylistCandidates returns the identities to check against the denylist.
+// Identities using an accepted trust domain alias are also checked in the
+// trust domain, so that denylisted SPIFFE IDs cannot be requested using an
+// alias.
+func (s *Server) denylistCandidates(identities []string) []string {
+	aliases := s.acceptedTrustDomainAliases()
+	if len(aliases) == 0 {
+		return identities
+	}
+
+	candidates := slices.Clone(identities)
+	for _, id := range identities {
+		uri, err := url.Parse(id)
+		if err != nil {
+			continue
+		}
+		if canonical := canonicalIdentity(uri, s.trustDomain, aliases); !slices.Contains(candidates, canonical) {
+			candidates = append(candidates, canonical)
+		}
+	}
+	return candidates
+}
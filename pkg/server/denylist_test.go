@@ -0,0 +1,289 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/ktesting"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cert-manager/istio-csr/pkg/server/audit"
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_denylistCheck(t *testing.T) {
+	tests := map[string]struct {
+		denylist   string
+		identities []string
+
+		expIdentity string
+		expMatch    string
+		expErr      bool
+	}{
+		"if the denylist is empty, should not match": {
+			denylist:   ``,
+			identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+		},
+		"if the SPIFFE ID is denylisted, should match": {
+			denylist:    "spiffeIDs: [spiffe://cluster.local/ns/sandbox/sa/default]\n",
+			identities:  []string{"spiffe://cluster.local/ns/other/sa/default", "spiffe://cluster.local/ns/sandbox/sa/default"},
+			expIdentity: "spiffe://cluster.local/ns/sandbox/sa/default",
+			expMatch:    "spiffe_id",
+		},
+		"if the namespace is denylisted, should match": {
+			denylist:    "namespaces: [sandbox]\n",
+			identities:  []string{"spiffe://cluster.local/ns/sandbox/sa/httpbin"},
+			expIdentity: "spiffe://cluster.local/ns/sandbox/sa/httpbin",
+			expMatch:    "namespace",
+		},
+		"if the service account is denylisted, should match": {
+			denylist:    "serviceAccounts: [sandbox/httpbin]\n",
+			identities:  []string{"spiffe://cluster.local/ns/sandbox/sa/httpbin"},
+			expIdentity: "spiffe://cluster.local/ns/sandbox/sa/httpbin",
+			expMatch:    "service_account",
+		},
+		"if a service account of another namespace is denylisted, should not match": {
+			denylist:   "serviceAccounts: [other/httpbin]\n",
+			identities: []string{"spiffe://cluster.local/ns/sandbox/sa/httpbin"},
+		},
+		"if the denylist has an invalid service account, should error": {
+			denylist: "serviceAccounts: [httpbin]\n",
+			expErr:   true,
+		},
+		"if the denylist has an invalid SPIFFE ID, should error": {
+			denylist: "spiffeIDs: [cluster.local/ns/sandbox/sa/httpbin]\n",
+			expErr:   true,
+		},
+		"if the denylist has an unknown field, should error": {
+			denylist: "identities: [spiffe://cluster.local/ns/sandbox/sa/httpbin]\n",
+			expErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &denylist{log: logr.Discard()}
+			err := d.update([]byte(test.denylist))
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			if test.expErr {
+				return
+			}
+
+			identity, match, err := d.check(test.identities)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expIdentity, identity)
+			assert.Equal(t, test.expMatch, match)
+		})
+	}
+}
+
+func Test_denylistNotSynced(t *testing.T) {
+	d := &denylist{log: logr.Discard()}
+	if _, _, err := d.check([]string{"spiffe://cluster.local/ns/sandbox/sa/default"}); err == nil {
+		t.Error("expected error before the denylist has been read")
+	}
+}
+
+func Test_denylistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.yaml")
+	// Write the file atomically, as the kubelet does for mounted ConfigMaps.
+	write := func(data string) {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	denied := func(d *denylist, identity string) bool {
+		got, _, err := d.check([]string{identity})
+		return err == nil && got == identity
+	}
+
+	write("namespaces: [sandbox]\n")
+	d, err := newDenylist(ktesting.NewLogger(t, ktesting.DefaultConfig), nil, DenylistOptions{File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, denied(d, "spiffe://cluster.local/ns/sandbox/sa/default"))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(done)
+	}()
+
+	write("namespaces: [other]\n")
+	assert.Eventually(t, func() bool {
+		return !denied(d, "spiffe://cluster.local/ns/sandbox/sa/default") && denied(d, "spiffe://cluster.local/ns/other/sa/default")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid denylist should keep the previous denylist.
+	write("serviceAccounts: [invalid]\n")
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, denied(d, "spiffe://cluster.local/ns/other/sa/default"))
+
+	cancel()
+	<-done
+
+	write("serviceAccounts: [invalid]\n")
+	if _, err := newDenylist(logr.Discard(), nil, DenylistOptions{File: path}); err == nil {
+		t.Error("expected error for an invalid denylist file")
+	}
+}
+
+func Test_denylistConfigMap(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	k8sClient := fake.NewClientBuilder().Build()
+	d, err := newDenylist(logr.Discard(), k8sClient, DenylistOptions{ConfigMapNamespace: "istio-csr", ConfigMapName: "denylist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(done)
+	}()
+
+	denied := func(identity string) bool {
+		got, _, err := d.check([]string{identity})
+		return err == nil && got == identity
+	}
+
+	// A missing ConfigMap is an empty denylist.
+	assert.Eventually(t, d.synced.Load, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, denied("spiffe://cluster.local/ns/sandbox/sa/default"))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-csr", Name: "denylist"},
+		Data:       map[string]string{denylistConfigMapKey: "serviceAccounts: [sandbox/default]\n"},
+	}
+	if err := k8sClient.Create(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool { return denied("spiffe://cluster.local/ns/sandbox/sa/default") }, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid denylist should keep the previous denylist.
+	cm.Data[denylistConfigMapKey] = "serviceAccounts: [invalid]\n"
+	if err := k8sClient.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, denied("spiffe://cluster.local/ns/sandbox/sa/default"))
+
+	if err := k8sClient.Delete(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool { return !denied("spiffe://cluster.local/ns/sandbox/sa/default") }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func Test_CreateCertificateDenylist(t *testing.T) {
+	const identity = "spiffe://cluster.local/ns/sandbox/sa/httpbin"
+
+	tests := map[string]struct {
+		denylist *string
+		// identity requested, and authenticated, if not the default.
+		identity string
+
+		expCode       codes.Code
+		expDenylisted bool
+	}{
+		"if the SPIFFE ID is denylisted and requested using a trust domain alias, should return PermissionDenied": {
+			denylist:      ptr("spiffeIDs: [\"" + identity + "\"]\n"),
+			identity:      "spiffe://alias.local/ns/sandbox/sa/httpbin",
+			expCode:       codes.PermissionDenied,
+			expDenylisted: true,
+		},
+		"if the identity is denylisted, should return PermissionDenied and audit the block": {
+			denylist:      ptr("namespaces: [sandbox]\n"),
+			expCode:       codes.PermissionDenied,
+			expDenylisted: true,
+		},
+		"if the denylist has not been read, should return Unavailable": {
+			denylist: nil,
+			expCode:  codes.Unavailable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &denylist{log: logr.Discard()}
+			if test.denylist != nil {
+				if err := d.update([]byte(*test.denylist)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			requested := identity
+			if len(test.identity) > 0 {
+				requested = test.identity
+			}
+
+			sink := new(recordingSink)
+			s := &Server{
+				opts: Options{
+					MaximumClientCertificateDuration: time.Hour,
+				},
+				authenticators: []security.Authenticator{
+					newMockAuthn([]string{requested}, ""),
+				},
+				log:                ktesting.NewLogger(t, ktesting.DefaultConfig),
+				trustDomain:        "cluster.local",
+				trustDomainAliases: []string{"alias.local"},
+				denylist:           d,
+				auditSink:          sink,
+			}
+
+			icr := &securityapi.IstioCertificateRequest{
+				Csr: string(gen.MustCSR(t,
+					gen.SetCSRIdentities([]string{requested}),
+				)),
+				ValidityDuration: 600,
+			}
+
+			_, err := s.CreateCertificate(t.Context(), icr)
+			assert.Equal(t, test.expCode, status.Code(err), "%v", err)
+
+			if assert.Len(t, sink.records, 1) {
+				assert.Equal(t, audit.OutcomeRejected, sink.records[0].Outcome)
+				assert.Equal(t, test.expDenylisted, sink.records[0].Denylisted)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
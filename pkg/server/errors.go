@@ -0,0 +1,156 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+)
+
+const (
+	// errorDomain is the domain of the google.rpc.ErrorInfo details returned
+	// to clients when a certificate request is rejected.
+	errorDomain = "istio-csr.cert-manager.io"
+)
+
+// errorReason is the reason a certificate request was rejected. It is
+// returned to clients in the google.rpc.ErrorInfo details of the response
+// status.
+type errorReason string
+
+const (
+	reasonAuthenticationFailed        errorReason = "AUTHENTICATION_FAILED"
+	reasonNoIdentity                  errorReason = "NO_IDENTITY"
+	reasonImpersonationNotAllowed     errorReason = "IMPERSONATION_NOT_ALLOWED"
+	reasonImpersonationDenied         errorReason = "IMPERSONATION_DENIED"
+	reasonInvalidCSR                  errorReason = "INVALID_CSR"
+	reasonForbiddenCSRFields          errorReason = "FORBIDDEN_CSR_FIELDS"
+	reasonForbiddenCSRExtensions      errorReason = "FORBIDDEN_CSR_EXTENSIONS"
+	reasonKeyNotAllowed               errorReason = "KEY_NOT_ALLOWED"
+	reasonSANsNotAllowed              errorReason = "SANS_NOT_ALLOWED"
+	reasonExtKeyUsageNotAllowed       errorReason = "EXT_KEY_USAGE_NOT_ALLOWED"
+	reasonIdentityMismatch            errorReason = "IDENTITY_MISMATCH"
+	reasonTrustDomainNotAllowed       errorReason = "TRUST_DOMAIN_NOT_ALLOWED"
+	reasonAdmissionPolicyRejected     errorReason = "ADMISSION_POLICY_REJECTED"
+	reasonIdentityDenylisted          errorReason = "IDENTITY_DENYLISTED"
+	reasonDenylistUnavailable         errorReason = "DENYLIST_UNAVAILABLE"
+	reasonInvalidNamespaceDuration    errorReason = "INVALID_NAMESPACE_DURATION"
+	reasonNamespacesUnavailable       errorReason = "NAMESPACES_UNAVAILABLE"
+	reasonUnknownCertSigner           errorReason = "UNKNOWN_CERT_SIGNER"
+	reasonRateLimited                 errorReason = "RATE_LIMITED"
+	reasonSigningQueueFull            errorReason = "SIGNING_QUEUE_FULL"
+	reasonIssuerUnavailable           errorReason = "ISSUER_UNAVAILABLE"
+	reasonCertificateRequestDenied    errorReason = "CERTIFICATE_REQUEST_DENIED"
+	reasonExternalAuthorizationDenied errorReason = "EXTERNAL_AUTHORIZATION_DENIED"
+	reasonExternalAuthorizationFailed errorReason = "EXTERNAL_AUTHORIZATION_FAILED"
+	reasonSigningTimeout              errorReason = "SIGNING_TIMEOUT"
+	reasonSigningFailed               errorReason = "SIGNING_FAILED"
+	reasonInvalidIssuedCertificate    errorReason = "INVALID_ISSUED_CERTIFICATE"
+	reasonIssuedCertificateMismatch   errorReason = "ISSUED_CERTIFICATE_MISMATCH"
+)
+
+// requestError is an error which rejects a certificate request. It holds the
+// gRPC code and reason returned to the client, a message which is safe to
+// return to any client, and the underlying error which may contain sensitive
+// detail such as token validation errors or identities.
+type requestError struct {
+	code    codes.Code
+	reason  errorReason
+	message string
+	err     error
+}
+
+// newRequestError returns a new request error. err may be nil if there is no
+// further detail than the message.
+func newRequestError(code codes.Code, reason errorReason, message string, err error) *requestError {
+	return &requestError{
+		code:    code,
+		reason:  reason,
+		message: message,
+		err:     err,
+	}
+}
+
+// Error returns the message of the error, including the underlying error.
+func (e *requestError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// signRequestError returns the request error for a certificate request which
+// could not be signed.
+func signRequestError(err error) *requestError {
+	switch {
+	case errors.Is(err, certmanager.ErrNoActiveIssuer):
+		return newRequestError(codes.Unavailable, reasonIssuerUnavailable, "no issuer is available to sign certificate requests", err)
+	case errors.Is(err, certmanager.ErrCertificateRequestDenied):
+		return newRequestError(codes.PermissionDenied, reasonCertificateRequestDenied, "certificate request was denied", err)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return newRequestError(status.FromContextError(err).Code(), reasonSigningTimeout, "timed out waiting for certificate request to be signed", err)
+	default:
+		return newRequestError(codes.Internal, reasonSigningFailed, "failed to sign certificate request", err)
+	}
+}
+
+// statusError converts the error into a gRPC status error returned to the
+// client. Request errors return their code, along with an ErrorInfo detail
+// holding the reason of the error. The underlying error is only included if
+// verbose error details are enabled. Any other error returns Internal.
+func (s *Server) statusError(err error) error {
+	var rerr *requestError
+	if !errors.As(err, &rerr) {
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	message := rerr.message
+	var metadata map[string]string
+	if s.opts.VerboseErrorDetails && rerr.err != nil {
+		message = rerr.Error()
+		metadata = map[string]string{"error": rerr.err.Error()}
+	}
+
+	return statusWithReason(rerr.code, rerr.reason, message, metadata)
+}
+
+// statusWithReason returns a gRPC status error with the given code and
+// message, and an ErrorInfo detail with the given reason and metadata.
+func statusWithReason(code codes.Code, reason errorReason, message string, metadata map[string]string) error {
+	st := status.New(code, message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(reason),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+)
+
+func Test_statusError(t *testing.T) {
+	tests := map[string]struct {
+		err     error
+		verbose bool
+
+		expCode    codes.Code
+		expMessage string
+		expInfo    *errdetails.ErrorInfo
+	}{
+		"if error is not a request error, should return Internal": {
+			err:        errors.New("secret"),
+			expCode:    codes.Internal,
+			expMessage: "internal error",
+		},
+		"if not verbose, should not return the underlying error": {
+			err:        newRequestError(codes.Unauthenticated, reasonAuthenticationFailed, "request authenticate failure", errors.New("token expired")),
+			expCode:    codes.Unauthenticated,
+			expMessage: "request authenticate failure",
+			expInfo:    &errdetails.ErrorInfo{Reason: "AUTHENTICATION_FAILED", Domain: errorDomain},
+		},
+		"if verbose, should return the underlying error": {
+			err:        newRequestError(codes.Unauthenticated, reasonAuthenticationFailed, "request authenticate failure", errors.New("token expired")),
+			verbose:    true,
+			expCode:    codes.Unauthenticated,
+			expMessage: "request authenticate failure: token expired",
+			expInfo: &errdetails.ErrorInfo{
+				Reason:   "AUTHENTICATION_FAILED",
+				Domain:   errorDomain,
+				Metadata: map[string]string{"error": "token expired"},
+			},
+		},
+		"if verbose but no underlying error, should return the message": {
+			err:        newRequestError(codes.InvalidArgument, reasonForbiddenCSRFields, "CSR contains forbidden fields", nil),
+			verbose:    true,
+			expCode:    codes.InvalidArgument,
+			expMessage: "CSR contains forbidden fields",
+			expInfo:    &errdetails.ErrorInfo{Reason: "FORBIDDEN_CSR_FIELDS", Domain: errorDomain},
+		},
+		"if request error is wrapped, should return the request error": {
+			err:        fmt.Errorf("wrapped: %w", newRequestError(codes.PermissionDenied, reasonIdentityMismatch, "mismatch", nil)),
+			expCode:    codes.PermissionDenied,
+			expMessage: "mismatch",
+			expInfo:    &errdetails.ErrorInfo{Reason: "IDENTITY_MISMATCH", Domain: errorDomain},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{opts: Options{VerboseErrorDetails: test.verbose}}
+
+			st, ok := status.FromError(s.statusError(test.err))
+			if !assert.True(t, ok, "expected gRPC status error") {
+				return
+			}
+
+			assert.Equal(t, test.expCode, st.Code())
+			assert.Equal(t, test.expMessage, st.Message())
+
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if i, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = i
+				}
+			}
+
+			if test.expInfo == nil {
+				assert.Nil(t, info)
+			} else if !proto.Equal(test.expInfo, info) {
+				t.Errorf("unexpected ErrorInfo, exp=%v got=%v", test.expInfo, info)
+			}
+		})
+	}
+}
+
+func Test_signRequestError(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		expCode   codes.Code
+		expReason errorReason
+	}{
+		"no active issuer should return Unavailable": {
+			err:       certmanager.ErrNoActiveIssuer,
+			expCode:   codes.Unavailable,
+			expReason: reasonIssuerUnavailable,
+		},
+		"denied request should return PermissionDenied": {
+			err:       fmt.Errorf("failed to wait: %w", certmanager.ErrCertificateRequestDenied),
+			expCode:   codes.PermissionDenied,
+			expReason: reasonCertificateRequestDenied,
+		},
+		"timed out request should return DeadlineExceeded": {
+			err:       fmt.Errorf("failed to wait: %w", context.DeadlineExceeded),
+			expCode:   codes.DeadlineExceeded,
+			expReason: reasonSigningTimeout,
+		},
+		"failed request should return Internal": {
+			err:       fmt.Errorf("failed to wait: %w", certmanager.ErrCertificateRequestFailed),
+			expCode:   codes.Internal,
+			expReason: reasonSigningFailed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rerr := signRequestError(test.err)
+			assert.Equal(t, test.expCode, rerr.code)
+			assert.Equal(t, test.expReason, rerr.reason)
+			assert.ErrorIs(t, rerr, test.err)
+		})
+	}
+}
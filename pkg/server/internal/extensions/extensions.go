@@ -35,9 +35,13 @@ const (
 	// GeneralNames ::= SEQUENCE SIZE (1..MAX) OF GeneralName
 	//
 	// GeneralName ::= CHOICE {
+	//      dNSName                         [2]     IA5String,
 	//      uniformResourceIdentifier       [6]     IA5String,
+	//      iPAddress                       [7]     OCTET STRING,
 	// }
+	asn1TagDNS = 2
 	asn1TagURI = 6
+	asn1TagIP  = 7
 )
 
 var (
@@ -57,8 +61,10 @@ var (
 
 // ValidateCSRExtentions validates the given certificate signing request
 // contains only valid extensions, including URI sans, key usages, and extended
-// key usages. Any other extensions will error.
-func ValidateCSRExtentions(csr *x509.CertificateRequest) error {
+// key usages. Any other extensions will error. If allowDNSAndIPSANs is true,
+// DNS and IP SANs are also permitted; their values must be validated by the
+// caller.
+func ValidateCSRExtentions(csr *x509.CertificateRequest, allowDNSAndIPSANs bool) error {
 	var el []error
 
 	if len(csr.ExtraExtensions) > 0 {
@@ -68,7 +74,7 @@ func ValidateCSRExtentions(csr *x509.CertificateRequest) error {
 	for _, extension := range csr.Extensions {
 		switch {
 		case extension.Id.Equal(oidExtensionSubjectAltName):
-			el = append(el, validateSubjectAltNameExtension(extension))
+			el = append(el, validateSubjectAltNameExtension(extension, allowDNSAndIPSANs))
 
 		case extension.Id.Equal(oidExtensionKeyUsage):
 			el = append(el, validateKeyUsageExtension(extension.Value))
@@ -132,9 +138,45 @@ func validateExtendedKeyUsageExtension(extension pkix.Extension) error {
 	return utilerrors.NewAggregate(el)
 }
 
+// ExtKeyUsages returns the client and server auth extended key usages
+// requested by the given certificate signing request, in that order. Returns
+// nil if the CSR does not contain an extended key usage extension. The CSR is
+// expected to have been validated by ValidateCSRExtentions.
+func ExtKeyUsages(csr *x509.CertificateRequest) ([]x509.ExtKeyUsage, error) {
+	for _, extension := range csr.Extensions {
+		if !extension.Id.Equal(oidExtensionExtendedKeyUsage) {
+			continue
+		}
+
+		var asn1ExtendedUsages []asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(extension.Value, &asn1ExtendedUsages); err != nil {
+			return nil, fmt.Errorf("failed to parse extended key usages: %s", err)
+		}
+
+		var clientAuth, serverAuth bool
+		for _, usage := range asn1ExtendedUsages {
+			clientAuth = clientAuth || usage.Equal(oidExtKeyUsageClientAuth)
+			serverAuth = serverAuth || usage.Equal(oidExtKeyUsageServerAuth)
+		}
+
+		usages := []x509.ExtKeyUsage{}
+		if clientAuth {
+			usages = append(usages, x509.ExtKeyUsageClientAuth)
+		}
+		if serverAuth {
+			usages = append(usages, x509.ExtKeyUsageServerAuth)
+		}
+
+		return usages, nil
+	}
+
+	return nil, nil
+}
+
 // validateSubjectAltNameExtension validates that the passed extension is a
-// correctly encoded URI SAN, and is no other SAN type
-func validateSubjectAltNameExtension(ext pkix.Extension) error {
+// correctly encoded URI SAN, and is no other SAN type. If allowDNSAndIP is
+// true, DNS and IP SANs are also permitted.
+func validateSubjectAltNameExtension(ext pkix.Extension, allowDNSAndIP bool) error {
 	if !ext.Id.Equal(oidExtensionSubjectAltName) {
 		return fmt.Errorf("extension is not a SAN type: %s", ext.Id)
 	}
@@ -162,8 +204,12 @@ func validateSubjectAltNameExtension(ext pkix.Extension) error {
 			return err
 		}
 
-		// Only URI SANs are permitted for istio certificates
-		if rawValue.Tag != asn1TagURI {
+		// Only URI SANs are permitted for istio certificates, unless DNS and IP
+		// SANs have been allowed by policy.
+		switch {
+		case rawValue.Tag == asn1TagURI:
+		case allowDNSAndIP && (rawValue.Tag == asn1TagDNS || rawValue.Tag == asn1TagIP):
+		default:
 			return fmt.Errorf("non uri san extension given: %s", rawValue.Bytes)
 		}
 	}
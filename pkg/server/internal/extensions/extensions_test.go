@@ -76,7 +76,9 @@ func TestValidateCSRExtentions(t *testing.T) {
 		uris   []string
 		ips    []string
 		usages []cmapi.KeyUsage
-		expErr bool
+
+		allowDNSAndIP bool
+		expErr        bool
 	}{
 		"if single URI name exists, shouldn't error": {
 			uris:   []string{"spiffe://foo.bar"},
@@ -148,6 +150,20 @@ func TestValidateCSRExtentions(t *testing.T) {
 			},
 			expErr: true,
 		},
+		"if multiple URI names exist, dns, ips, and DNS and IP SANs allowed, shouldn't error": {
+			uris:          []string{"spiffe://foo.bar", "spiffe://bar.foo"},
+			dns:           []string{"foo.bar"},
+			ips:           []string{"1.2.3.4"},
+			allowDNSAndIP: true,
+			expErr:        false,
+		},
+		"if multiple URI names exist, emails, and DNS and IP SANs allowed, should error": {
+			uris:          []string{"spiffe://foo.bar", "spiffe://bar.foo"},
+			dns:           []string{"foo.bar"},
+			emails:        []string{"hello@example.com"},
+			allowDNSAndIP: true,
+			expErr:        true,
+		},
 		"if multiple URI names exist, and subset allowed usages, shouldn't error": {
 			uris: []string{"spiffe://foo.bar", "spiffe://bar.foo"},
 			usages: []cmapi.KeyUsage{
@@ -195,7 +211,7 @@ func TestValidateCSRExtentions(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			err = ValidateCSRExtentions(csr)
+			err = ValidateCSRExtentions(csr, test.allowDNSAndIP)
 			if (err != nil) != test.expErr {
 				t.Errorf("unexpected error, exp=%t got=%v",
 					test.expErr, err)
@@ -393,3 +409,69 @@ func asn1BitLength(bitString []byte) int {
 
 	return 0
 }
+
+func TestExtKeyUsages(t *testing.T) {
+	sk, err := pkiutil.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		usages    []cmapi.KeyUsage
+		expUsages []x509.ExtKeyUsage
+	}{
+		"if no usages requested, should return nil": {
+			usages:    nil,
+			expUsages: nil,
+		},
+		"if only key usages requested, should return nil": {
+			usages:    []cmapi.KeyUsage{cmapi.UsageDigitalSignature},
+			expUsages: nil,
+		},
+		"if client auth requested, should return client auth": {
+			usages:    []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageClientAuth},
+			expUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		"if server auth requested, should return server auth": {
+			usages:    []cmapi.KeyUsage{cmapi.UsageServerAuth},
+			expUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		},
+		"if server and client auth requested, should return client and server auth": {
+			usages:    []cmapi.KeyUsage{cmapi.UsageServerAuth, cmapi.UsageClientAuth},
+			expUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr, err := pkiutil.GenerateCSR(&cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					URIs:   []string{"spiffe://foo.bar"},
+					Usages: test.usages,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			csrDER, err := pkiutil.EncodeCSR(csr, sk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			csr, err = x509.ParseCertificateRequest(csrDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			usages, err := ExtKeyUsages(csr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(usages) != fmt.Sprint(test.expUsages) || (usages == nil) != (test.expUsages == nil) {
+				t.Errorf("unexpected usages, exp=%v got=%v", test.expUsages, usages)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+
+	"github.com/cert-manager/istio-csr/pkg/filewatch"
+)
+
+// Engine evaluates the admission policies of a policy file against
+// certificate requests. The policies are reloaded when the file changes,
+// including when the file is a mounted ConfigMap which has been updated.
+type Engine struct {
+	log      logr.Logger
+	filepath string
+
+	data     []byte
+	programs atomic.Pointer[[]program]
+}
+
+// New loads and compiles the admission policy file at the given path.
+func New(log logr.Logger, filepath string) (*Engine, error) {
+	e := &Engine{
+		log:      log.WithName("admission-policy").WithValues("file", filepath),
+		filepath: filepath,
+	}
+
+	if _, err := e.load(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Policies returns the names of the currently loaded policies, in evaluation
+// order.
+func (e *Engine) Policies() []string {
+	programs := *e.programs.Load()
+	names := make([]string, len(programs))
+	for i, p := range programs {
+		names[i] = p.policy.Name
+	}
+	return names
+}
+
+// Evaluate evaluates the loaded policies in order against the input. Returns
+// the rejection of the first policy which does not admit the request, or nil
+// if the request is admitted.
+func (e *Engine) Evaluate(ctx context.Context, input Input) *Rejection {
+	return evaluate(ctx, *e.programs.Load(), input)
+}
+
+// Start watches the policy file for changes, reloading the policies, until
+// the context is cancelled. If the changed file is invalid, the previous
+// policies remain in use.
+func (e *Engine) Start(ctx context.Context) error {
+	watcher, err := filewatch.New(e.log, e.filepath)
+	if err != nil {
+		return err
+	}
+
+	// Pick up any changes made since the policies were first loaded.
+	e.reload()
+
+	watcher.Run(ctx, e.reload)
+
+	return nil
+}
+
+// reload loads the policy file, logging the result.
+func (e *Engine) reload() {
+	updated, err := e.load()
+	if err != nil {
+		e.log.Error(err, "failed to reload admission policy file, continuing to use the previous policies")
+		return
+	}
+
+	if !updated {
+		e.log.V(3).Info("no admission policy changes on file")
+		return
+	}
+
+	e.log.Info("reloaded admission policies", "policies", e.Policies())
+}
+
+// load reads and compiles the policy file, and if changed from the current
+// state, sets the compiled policies as the live policies. Returns true if the
+// policies were updated.
+func (e *Engine) load() (bool, error) {
+	data, err := os.ReadFile(e.filepath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read admission policy file %q: %w", e.filepath, err)
+	}
+
+	if e.programs.Load() != nil && bytes.Equal(data, e.data) {
+		return false, nil
+	}
+
+	programs, err := compile(data)
+	if err != nil {
+		return false, fmt.Errorf("invalid admission policy file %q: %w", e.filepath, err)
+	}
+
+	e.data = data
+	e.programs.Store(&programs)
+
+	return true, nil
+}
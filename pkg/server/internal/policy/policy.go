@@ -0,0 +1,286 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// costLimit is the maximum runtime cost of evaluating a single policy
+	// expression, bounding the time spent on a request by an expensive or
+	// runaway expression.
+	costLimit = 1000000
+)
+
+// File is the admission policy file. Policies are evaluated in order against
+// every authenticated certificate request. A request is admitted only if all
+// policies evaluate to true.
+type File struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy is a single CEL admission policy.
+type Policy struct {
+	// Name is the name of the policy, reported when it rejects a request.
+	Name string `json:"name"`
+
+	// Expression is a CEL expression which must evaluate to true for the
+	// request to be admitted. The expression has access to the "caller" and
+	// "csr" variables, e.g.
+	// `caller.namespace != "default" && csr.keySize >= 256`.
+	Expression string `json:"expression"`
+
+	// Message is an optional message returned when the policy rejects a
+	// request. Defaults to the expression.
+	Message string `json:"message,omitempty"`
+}
+
+// Input is the request attributes that policies are evaluated against.
+type Input struct {
+	// Caller is the caller of the request.
+	Caller Caller
+
+	// CSR is the parsed certificate signing request.
+	CSR *x509.CertificateRequest
+
+	// RequestedDuration is the certificate duration requested by the caller.
+	RequestedDuration time.Duration
+}
+
+// Caller is the authenticated caller of a request, exposed to policies as the
+// "caller" variable.
+type Caller struct {
+	// Identities are the SPIFFE IDs the certificate is requested for.
+	Identities []string
+
+	// Namespace and ServiceAccount are of the workload the certificate is
+	// requested for.
+	Namespace      string
+	ServiceAccount string
+
+	// PodName is the name of the calling pod, if known.
+	PodName string
+
+	// Node is the node of the calling node proxy, when the request
+	// impersonates a workload.
+	Node string
+
+	// Impersonator is the identities of the node proxy impersonating the
+	// workload. Empty if the request does not impersonate.
+	Impersonator []string
+
+	// ClusterID is the ID of the cluster the caller is running in.
+	ClusterID string
+}
+
+// Rejection describes why a request was rejected by a policy.
+type Rejection struct {
+	// Policy is the name of the policy which rejected the request.
+	Policy string
+
+	// Message is the message of the rejecting policy.
+	Message string
+
+	// Err is set if the policy failed to evaluate. Requests are rejected by
+	// policies which fail to evaluate.
+	Err error
+}
+
+// program is a compiled policy.
+type program struct {
+	policy  Policy
+	program cel.Program
+}
+
+// newEnv returns the CEL environment policies are compiled in.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("caller", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("csr", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+}
+
+// compile decodes, validates, and compiles the given admission policy file
+// contents.
+func compile(data []byte) ([]program, error) {
+	file := new(File)
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CEL environment: %w", err)
+	}
+
+	var errs []error
+	names := make(map[string]struct{}, len(file.Policies))
+	programs := make([]program, 0, len(file.Policies))
+	for i, policy := range file.Policies {
+		if len(policy.Name) == 0 {
+			errs = append(errs, fmt.Errorf("policies[%d]: name is required", i))
+			continue
+		}
+		if _, ok := names[policy.Name]; ok {
+			errs = append(errs, fmt.Errorf("policies[%d]: duplicate policy name %q", i, policy.Name))
+			continue
+		}
+		names[policy.Name] = struct{}{}
+
+		ast, issues := env.Compile(policy.Expression)
+		if issues.Err() != nil {
+			errs = append(errs, fmt.Errorf("policies[%d] %q: failed to compile expression: %w", i, policy.Name, issues.Err()))
+			continue
+		}
+
+		if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+			errs = append(errs, fmt.Errorf("policies[%d] %q: expression must evaluate to a bool, got %s", i, policy.Name, ast.OutputType()))
+			continue
+		}
+
+		prg, err := env.Program(ast, cel.CostLimit(costLimit), cel.InterruptCheckFrequency(100))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policies[%d] %q: failed to build program: %w", i, policy.Name, err))
+			continue
+		}
+
+		if len(policy.Message) == 0 {
+			policy.Message = fmt.Sprintf("failed expression: %s", policy.Expression)
+		}
+
+		programs = append(programs, program{policy: policy, program: prg})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return programs, nil
+}
+
+// evaluate evaluates the programs in order against the input. Returns the
+// rejection of the first policy which does not admit the request, or nil if
+// all policies admit it.
+func evaluate(ctx context.Context, programs []program, input Input) *Rejection {
+	if len(programs) == 0 {
+		return nil
+	}
+
+	vars := map[string]any{
+		"caller": callerVariable(input.Caller),
+		"csr":    csrVariable(input.CSR, input.RequestedDuration),
+	}
+
+	for _, p := range programs {
+		out, _, err := p.program.ContextEval(ctx, vars)
+		if err != nil {
+			return &Rejection{Policy: p.policy.Name, Message: p.policy.Message, Err: fmt.Errorf("failed to evaluate policy: %w", err)}
+		}
+
+		admitted, ok := out.Value().(bool)
+		if !ok {
+			return &Rejection{Policy: p.policy.Name, Message: p.policy.Message, Err: fmt.Errorf("policy evaluated to %s, expected bool", out.Type())}
+		}
+
+		if !admitted {
+			return &Rejection{Policy: p.policy.Name, Message: p.policy.Message}
+		}
+	}
+
+	return nil
+}
+
+// callerVariable returns the "caller" variable of the given caller.
+func callerVariable(caller Caller) map[string]any {
+	return map[string]any{
+		"identities":     nonNil(caller.Identities),
+		"namespace":      caller.Namespace,
+		"serviceAccount": caller.ServiceAccount,
+		"podName":        caller.PodName,
+		"node":           caller.Node,
+		"impersonator":   nonNil(caller.Impersonator),
+		"clusterID":      caller.ClusterID,
+	}
+}
+
+// csrVariable returns the "csr" variable of the given CSR.
+func csrVariable(csr *x509.CertificateRequest, requestedDuration time.Duration) map[string]any {
+	uris := make([]string, len(csr.URIs))
+	for i, uri := range csr.URIs {
+		uris[i] = uri.String()
+	}
+
+	ips := make([]string, len(csr.IPAddresses))
+	for i, ip := range csr.IPAddresses {
+		ips[i] = ip.String()
+	}
+
+	extensions := make([]string, len(csr.Extensions))
+	for i, extension := range csr.Extensions {
+		extensions[i] = extension.Id.String()
+	}
+
+	return map[string]any{
+		"uris":               uris,
+		"dnsNames":           nonNil(csr.DNSNames),
+		"ipAddresses":        ips,
+		"emailAddresses":     nonNil(csr.EmailAddresses),
+		"commonName":         csr.Subject.CommonName,
+		"keyType":            csr.PublicKeyAlgorithm.String(),
+		"keySize":            KeySize(csr.PublicKey),
+		"signatureAlgorithm": csr.SignatureAlgorithm.String(),
+		"extensions":         extensions,
+		"requestedDuration":  requestedDuration,
+	}
+}
+
+// KeySize returns the size in bits of the given public key. For ECDSA keys
+// this is the size of the curve. Returns 0 for unknown key types.
+func KeySize(pub any) int {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return ed25519.PublicKeySize * 8
+	default:
+		return 0
+	}
+}
+
+// nonNil returns an empty slice if s is nil, so that policies may call size()
+// on all list attributes.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func Test_compile(t *testing.T) {
+	tests := map[string]struct {
+		policies string
+		expNames []string
+		expErr   bool
+	}{
+		"an empty file should compile to no policies": {
+			policies: ``,
+			expNames: []string{},
+		},
+		"valid policies should compile in order": {
+			policies: `policies:
+- name: no-default-service-account
+  expression: caller.serviceAccount != "default"
+- name: strong-keys
+  expression: csr.keyType != "RSA" || csr.keySize >= 3072
+`,
+			expNames: []string{"no-default-service-account", "strong-keys"},
+		},
+		"a policy without a name should error": {
+			policies: `policies:
+- expression: "true"
+`,
+			expErr: true,
+		},
+		"duplicate policy names should error": {
+			policies: `policies:
+- name: a
+  expression: "true"
+- name: a
+  expression: "false"
+`,
+			expErr: true,
+		},
+		"an expression which does not compile should error": {
+			policies: `policies:
+- name: a
+  expression: caller.namespace ==
+`,
+			expErr: true,
+		},
+		"an expression with an unknown variable should error": {
+			policies: `policies:
+- name: a
+  expression: pod.namespace == "default"
+`,
+			expErr: true,
+		},
+		"an expression which does not evaluate to a bool should error": {
+			policies: `policies:
+- name: a
+  expression: "1 + 1"
+`,
+			expErr: true,
+		},
+		"unknown fields should error": {
+			policies: `policies:
+- name: a
+  expr: "true"
+`,
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			programs, err := compile([]byte(test.policies))
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			if test.expErr {
+				return
+			}
+
+			names := make([]string, len(programs))
+			for i, p := range programs {
+				names[i] = p.policy.Name
+			}
+			assert.Equal(t, test.expNames, names)
+		})
+	}
+}
+
+func Test_evaluate(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecCSR := &x509.CertificateRequest{
+		URIs:               []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/sandbox/sa/default"}},
+		PublicKeyAlgorithm: x509.ECDSA,
+		PublicKey:          &ecKey.PublicKey,
+		SignatureAlgorithm: x509.ECDSAWithSHA256,
+	}
+	rsaCSR := &x509.CertificateRequest{
+		URIs:               []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/sandbox/sa/default"}},
+		PublicKeyAlgorithm: x509.RSA,
+		PublicKey:          &rsaKey.PublicKey,
+		SignatureAlgorithm: x509.SHA256WithRSA,
+	}
+
+	caller := Caller{
+		Identities:     []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+		Namespace:      "sandbox",
+		ServiceAccount: "default",
+		PodName:        "httpbin-abc",
+		ClusterID:      "Kubernetes",
+	}
+	impersonated := caller
+	impersonated.Node = "node-1"
+	impersonated.Impersonator = []string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}
+
+	tests := map[string]struct {
+		policies string
+		input    Input
+
+		expRejection *Rejection
+		expErr       bool
+	}{
+		"no policies should admit": {
+			policies: ``,
+			input:    Input{Caller: caller, CSR: ecCSR, RequestedDuration: time.Hour},
+		},
+		"all policies evaluating to true should admit": {
+			policies: `policies:
+- name: namespace
+  expression: caller.namespace == "sandbox" && caller.identities.all(id, id.startsWith("spiffe://cluster.local/"))
+- name: key
+  expression: csr.keyType == "ECDSA" && csr.keySize == 256 && csr.uris.size() == 1
+- name: duration
+  expression: csr.requestedDuration <= duration("24h")
+- name: not-impersonated
+  expression: caller.impersonator.size() == 0 && caller.node == ""
+`,
+			input: Input{Caller: caller, CSR: ecCSR, RequestedDuration: time.Hour},
+		},
+		"the first policy evaluating to false should reject, with its message": {
+			policies: `policies:
+- name: allowed
+  expression: "true"
+- name: no-default-service-account
+  expression: caller.serviceAccount != "default"
+  message: workloads must not use the default service account
+- name: also-rejects
+  expression: "false"
+`,
+			input: Input{Caller: caller, CSR: ecCSR, RequestedDuration: time.Hour},
+			expRejection: &Rejection{
+				Policy:  "no-default-service-account",
+				Message: "workloads must not use the default service account",
+			},
+		},
+		"a rejecting policy without a message should report the expression": {
+			policies: `policies:
+- name: strong-keys
+  expression: csr.keyType != "RSA" || csr.keySize >= 3072
+`,
+			input: Input{Caller: caller, CSR: rsaCSR, RequestedDuration: time.Hour},
+			expRejection: &Rejection{
+				Policy:  "strong-keys",
+				Message: `failed expression: csr.keyType != "RSA" || csr.keySize >= 3072`,
+			},
+		},
+		"a requested duration above the limit should reject": {
+			policies: `policies:
+- name: duration
+  expression: csr.requestedDuration <= duration("24h")
+`,
+			input:        Input{Caller: caller, CSR: ecCSR, RequestedDuration: 48 * time.Hour},
+			expRejection: &Rejection{Policy: "duration", Message: `failed expression: csr.requestedDuration <= duration("24h")`},
+		},
+		"impersonated requests should expose the node and impersonator": {
+			policies: `policies:
+- name: ztunnel-only
+  expression: caller.impersonator.exists(id, id.endsWith("/sa/ztunnel")) && caller.node == "node-1"
+`,
+			input: Input{Caller: impersonated, CSR: ecCSR, RequestedDuration: time.Hour},
+		},
+		"a policy which fails to evaluate should reject with an error": {
+			policies: `policies:
+- name: missing-attribute
+  expression: caller.doesNotExist == "foo"
+`,
+			input:        Input{Caller: caller, CSR: ecCSR, RequestedDuration: time.Hour},
+			expRejection: &Rejection{Policy: "missing-attribute", Message: `failed expression: caller.doesNotExist == "foo"`},
+			expErr:       true,
+		},
+		"a dyn expression which does not evaluate to a bool should reject with an error": {
+			policies: `policies:
+- name: not-bool
+  expression: caller.namespace
+`,
+			input:        Input{Caller: caller, CSR: ecCSR, RequestedDuration: time.Hour},
+			expRejection: &Rejection{Policy: "not-bool", Message: "failed expression: caller.namespace"},
+			expErr:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			programs, err := compile([]byte(test.policies))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rejection := evaluate(t.Context(), programs, test.input)
+			if test.expRejection == nil {
+				assert.Nil(t, rejection)
+				return
+			}
+
+			if assert.NotNil(t, rejection) {
+				assert.Equal(t, test.expRejection.Policy, rejection.Policy)
+				assert.Equal(t, test.expRejection.Message, rejection.Message)
+				assert.Equal(t, test.expErr, rejection.Err != nil, "%v", rejection.Err)
+			}
+		})
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	// Write the file atomically, as the kubelet does for mounted ConfigMaps.
+	write := func(policies string) {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(policies), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`policies:
+- name: deny-all
+  expression: "false"
+`)
+
+	e, err := New(ktesting.NewLogger(t, ktesting.DefaultConfig), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"deny-all"}, e.Policies())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	errCh := make(chan error)
+	go func() { errCh <- e.Start(ctx) }()
+
+	input := Input{CSR: &x509.CertificateRequest{}}
+	assert.NotNil(t, e.Evaluate(t.Context(), input))
+
+	// An updated file should be reloaded.
+	write(`policies:
+- name: allow-all
+  expression: "true"
+`)
+	assert.Eventually(t, func() bool {
+		return e.Evaluate(t.Context(), input) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"allow-all"}, e.Policies())
+
+	// An invalid file should keep the previous policies.
+	write(`policies:
+- name: broken
+  expression: "true" &&
+`)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"allow-all"}, e.Policies())
+
+	cancel()
+	assert.NoError(t, <-errCh)
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(ktesting.NewLogger(t, ktesting.DefaultConfig), filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing policy file")
+	}
+
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte("policies:\n- name: a\n  expression: \"1\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(ktesting.NewLogger(t, ktesting.DefaultConfig), path); err == nil {
+		t.Error("expected error for invalid policy file")
+	}
+}
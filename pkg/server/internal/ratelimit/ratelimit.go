@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// sweepInterval is the minimum interval between sweeps of idle limiters.
+	sweepInterval = time.Minute
+)
+
+// Limiter is a set of token bucket rate limiters, keyed by an arbitrary
+// string such as an identity or namespace. Limiters whose bucket has refilled
+// are periodically evicted, since they are equivalent to a new limiter. This
+// bounds memory to the set of recently active keys.
+type Limiter struct {
+	limit rate.Limit
+	burst int
+
+	lock      sync.Mutex
+	limiters  map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+// New returns a new Limiter which allows qps requests per second for each
+// key, with bursts of up to burst requests.
+func New(qps float64, burst int) *Limiter {
+	return &Limiter{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Reserve reserves a token for the given key at the given time. The caller
+// should check the reservation's delay, and cancel the reservation if it does
+// not wish to wait.
+func (l *Limiter) Reserve(key string, now time.Time) *rate.Reservation {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.sweep(now)
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = limiter
+	}
+
+	return limiter.ReserveN(now, 1)
+}
+
+// Len returns the number of keys currently being tracked.
+func (l *Limiter) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.limiters)
+}
+
+// sweep evicts limiters whose bucket is full. Must be called with the lock
+// held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= float64(l.burst) {
+			delete(l.limiters, key)
+		}
+	}
+}
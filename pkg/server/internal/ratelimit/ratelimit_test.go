@@ -0,0 +1,96 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserve(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		qps   float64
+		burst int
+
+		reserve   func(l *Limiter) time.Duration
+		expDelay  time.Duration
+		expLength int
+	}{
+		"a request within the burst should not be delayed": {
+			qps: 1, burst: 2,
+			reserve: func(l *Limiter) time.Duration {
+				l.Reserve("foo", now)
+				return l.Reserve("foo", now).DelayFrom(now)
+			},
+			expDelay:  0,
+			expLength: 1,
+		},
+		"a request over the burst should be delayed": {
+			qps: 1, burst: 2,
+			reserve: func(l *Limiter) time.Duration {
+				l.Reserve("foo", now)
+				l.Reserve("foo", now)
+				return l.Reserve("foo", now).DelayFrom(now)
+			},
+			expDelay:  time.Second,
+			expLength: 1,
+		},
+		"a cancelled reservation should return its token": {
+			qps: 1, burst: 1,
+			reserve: func(l *Limiter) time.Duration {
+				l.Reserve("foo", now)
+				l.Reserve("foo", now).CancelAt(now)
+				return l.Reserve("foo", now).DelayFrom(now)
+			},
+			expDelay:  time.Second,
+			expLength: 1,
+		},
+		"requests for different keys should not share a bucket": {
+			qps: 1, burst: 1,
+			reserve: func(l *Limiter) time.Duration {
+				l.Reserve("foo", now)
+				return l.Reserve("bar", now).DelayFrom(now)
+			},
+			expDelay:  0,
+			expLength: 2,
+		},
+		"limiters with a full bucket should be evicted": {
+			qps: 1, burst: 1,
+			reserve: func(l *Limiter) time.Duration {
+				l.Reserve("foo", now)
+				l.Reserve("bar", now.Add(time.Minute-time.Second/2))
+				return l.Reserve("bar", now.Add(time.Minute)).DelayFrom(now.Add(time.Minute))
+			},
+			expDelay:  time.Second / 2,
+			expLength: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			l := New(test.qps, test.burst)
+			l.lastSweep = now
+
+			assert.Equal(t, test.expDelay, test.reserve(l))
+			assert.Equal(t, test.expLength, l.Len())
+		})
+	}
+}
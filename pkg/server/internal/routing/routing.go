@@ -0,0 +1,194 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"istio.io/istio/pkg/spiffe"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// defaultIssuerGroup is the group set on a rule's issuerRef if none is
+	// given.
+	defaultIssuerGroup = "cert-manager.io"
+)
+
+// Policy is a list of rules which route workload identities to the issuer
+// that should sign their certificates. Rules are evaluated in order, and the
+// first matching rule wins.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule matches a set of workload identities to an issuer. A rule must define
+// at least one of Namespaces or Identities. If both are defined, an identity
+// must match both to match the rule.
+type Rule struct {
+	// Name is an optional name of the rule, used for logging.
+	Name string `json:"name,omitempty"`
+
+	// Namespaces is a list of namespaces whose workloads match this rule.
+	// Entries may be glob patterns, e.g. "tenant-*".
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Identities is a list of SPIFFE ID glob patterns that match this rule,
+	// e.g. "spiffe://cluster.local/ns/payments/sa/*". Note that "*" does not
+	// match across "/" separators.
+	Identities []string `json:"identities,omitempty"`
+
+	// IssuerRef is the issuer used to sign certificates for matching
+	// identities.
+	IssuerRef cmmeta.IssuerReference `json:"issuerRef"`
+}
+
+// Load reads and validates the routing policy at the given file path.
+func Load(filepath string) (*Policy, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuer routing policy file %q: %w", filepath, err)
+	}
+
+	policy := new(Policy)
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to decode issuer routing policy file %q: %w", filepath, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid issuer routing policy file %q: %w", filepath, err)
+	}
+
+	return policy, nil
+}
+
+// Validate ensures that all rules of the policy are well formed, defaulting
+// the issuerRef group where it is not set.
+func (p *Policy) Validate() error {
+	var errs []error
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		if len(rule.Namespaces) == 0 && len(rule.Identities) == 0 {
+			errs = append(errs, fmt.Errorf("rule[%d]: at least one of namespaces or identities must be set", i))
+		}
+
+		for _, pattern := range slices.Concat(rule.Namespaces, rule.Identities) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("rule[%d]: invalid pattern %q: %w", i, pattern, err))
+			}
+		}
+
+		if rule.IssuerRef.Name == "" {
+			errs = append(errs, fmt.Errorf("rule[%d]: issuerRef.name is required", i))
+		}
+
+		if rule.IssuerRef.Kind == "" {
+			errs = append(errs, fmt.Errorf("rule[%d]: issuerRef.kind is required", i))
+		}
+
+		if rule.IssuerRef.Group == "" {
+			rule.IssuerRef.Group = defaultIssuerGroup
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IssuerRefFor returns the issuer of the first rule that matches all of the
+// given identities. Returns nil if no rule matches, in which case the default
+// issuer should be used.
+func (p *Policy) IssuerRefFor(identities []string) (*cmmeta.IssuerReference, string) {
+	if p == nil || len(identities) == 0 {
+		return nil, ""
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		matched := true
+		for _, identity := range identities {
+			if !rule.matches(identity) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return &rule.IssuerRef, rule.Name
+		}
+	}
+
+	return nil, ""
+}
+
+// ParseIssuerRef parses an issuer reference in the form "<kind>.<group>/<name>",
+// e.g. "ClusterIssuer.cert-manager.io/payments-ca". If the group is omitted,
+// i.e. "<kind>/<name>", the group defaults to "cert-manager.io".
+func ParseIssuerRef(ref string) (cmmeta.IssuerReference, error) {
+	kindGroup, name, ok := strings.Cut(ref, "/")
+	if !ok || len(kindGroup) == 0 || len(name) == 0 {
+		return cmmeta.IssuerReference{}, fmt.Errorf("invalid issuer reference %q, expected the form <kind>.<group>/<name>", ref)
+	}
+
+	kind, group, ok := strings.Cut(kindGroup, ".")
+	if !ok {
+		group = defaultIssuerGroup
+	}
+	if len(kind) == 0 || len(group) == 0 {
+		return cmmeta.IssuerReference{}, fmt.Errorf("invalid issuer reference %q, expected the form <kind>.<group>/<name>", ref)
+	}
+
+	return cmmeta.IssuerReference{
+		Name:  name,
+		Kind:  kind,
+		Group: group,
+	}, nil
+}
+
+// matches returns true if the given identity matches the rule.
+func (r *Rule) matches(identity string) bool {
+	if len(r.Namespaces) > 0 {
+		id, err := spiffe.ParseIdentity(identity)
+		if err != nil || !matchAny(r.Namespaces, id.Namespace) {
+			return false
+		}
+	}
+
+	if len(r.Identities) > 0 && !matchAny(r.Identities, identity) {
+		return false
+	}
+
+	return true
+}
+
+// matchAny returns true if the value matches any of the given glob patterns.
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+
+	return false
+}
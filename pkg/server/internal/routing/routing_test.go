@@ -0,0 +1,247 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		policy    string
+		expPolicy *Policy
+		expErr    bool
+	}{
+		"a valid policy should be loaded, with the issuer group defaulted": {
+			policy: `rules:
+- name: payments
+  namespaces: ["payments"]
+  issuerRef:
+    name: payments-ca
+    kind: ClusterIssuer
+- identities: ["spiffe://cluster.local/ns/tenant-*/sa/*"]
+  issuerRef:
+    name: tenant-ca
+    kind: Issuer
+    group: example.io
+`,
+			expPolicy: &Policy{
+				Rules: []Rule{
+					{
+						Name:       "payments",
+						Namespaces: []string{"payments"},
+						IssuerRef:  cmmeta.IssuerReference{Name: "payments-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+					},
+					{
+						Identities: []string{"spiffe://cluster.local/ns/tenant-*/sa/*"},
+						IssuerRef:  cmmeta.IssuerReference{Name: "tenant-ca", Kind: "Issuer", Group: "example.io"},
+					},
+				},
+			},
+		},
+		"an unknown field should error": {
+			policy: `rules:
+- namespaces: ["payments"]
+  issuer:
+    name: payments-ca
+`,
+			expErr: true,
+		},
+		"a rule with no selectors should error": {
+			policy: `rules:
+- issuerRef:
+    name: payments-ca
+    kind: ClusterIssuer
+`,
+			expErr: true,
+		},
+		"a rule with an invalid pattern should error": {
+			policy: `rules:
+- namespaces: ["payments-["]
+  issuerRef:
+    name: payments-ca
+    kind: ClusterIssuer
+`,
+			expErr: true,
+		},
+		"a rule with no issuer name or kind should error": {
+			policy: `rules:
+- namespaces: ["payments"]
+  issuerRef:
+    group: cert-manager.io
+`,
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(test.policy), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			policy, err := Load(path)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expPolicy, policy)
+		})
+	}
+}
+
+func TestIssuerRefFor(t *testing.T) {
+	paymentsRef := cmmeta.IssuerReference{Name: "payments-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"}
+	tenantRef := cmmeta.IssuerReference{Name: "tenant-ca", Kind: "Issuer", Group: "cert-manager.io"}
+	gatewayRef := cmmeta.IssuerReference{Name: "gateway-ca", Kind: "Issuer", Group: "cert-manager.io"}
+
+	policy := &Policy{
+		Rules: []Rule{
+			{
+				Name:       "payments-gateway",
+				Namespaces: []string{"payments"},
+				Identities: []string{"spiffe://cluster.local/ns/payments/sa/gateway"},
+				IssuerRef:  gatewayRef,
+			},
+			{
+				Name:       "payments",
+				Namespaces: []string{"payments"},
+				IssuerRef:  paymentsRef,
+			},
+			{
+				Name:       "tenants",
+				Identities: []string{"spiffe://cluster.local/ns/tenant-*/sa/*"},
+				IssuerRef:  tenantRef,
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		policy     *Policy
+		identities []string
+		expRef     *cmmeta.IssuerReference
+		expRule    string
+	}{
+		"a nil policy should return no issuer": {
+			policy:     nil,
+			identities: []string{"spiffe://cluster.local/ns/payments/sa/api"},
+			expRef:     nil,
+		},
+		"no identities should return no issuer": {
+			policy:     policy,
+			identities: nil,
+			expRef:     nil,
+		},
+		"an identity matching no rule should return no issuer": {
+			policy:     policy,
+			identities: []string{"spiffe://cluster.local/ns/default/sa/api"},
+			expRef:     nil,
+		},
+		"an identity matching the namespace should return the namespace issuer": {
+			policy:     policy,
+			identities: []string{"spiffe://cluster.local/ns/payments/sa/api"},
+			expRef:     &paymentsRef,
+			expRule:    "payments",
+		},
+		"the first matching rule should win": {
+			policy:     policy,
+			identities: []string{"spiffe://cluster.local/ns/payments/sa/gateway"},
+			expRef:     &gatewayRef,
+			expRule:    "payments-gateway",
+		},
+		"an identity matching a SPIFFE ID pattern should return the issuer": {
+			policy:     policy,
+			identities: []string{"spiffe://cluster.local/ns/tenant-a/sa/api"},
+			expRef:     &tenantRef,
+			expRule:    "tenants",
+		},
+		"a non-SPIFFE identity should not match a namespace rule": {
+			policy:     policy,
+			identities: []string{"payments"},
+			expRef:     nil,
+		},
+		"identities which all match a rule should return the issuer": {
+			policy: policy,
+			identities: []string{
+				"spiffe://cluster.local/ns/payments/sa/api",
+				"spiffe://cluster.local/ns/payments/sa/worker",
+			},
+			expRef:  &paymentsRef,
+			expRule: "payments",
+		},
+		"identities which don't all match a rule should return no issuer": {
+			policy: policy,
+			identities: []string{
+				"spiffe://cluster.local/ns/payments/sa/api",
+				"spiffe://cluster.local/ns/default/sa/api",
+			},
+			expRef: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, rule := test.policy.IssuerRefFor(test.identities)
+			assert.Equal(t, test.expRef, ref)
+			assert.Equal(t, test.expRule, rule)
+		})
+	}
+}
+
+func TestParseIssuerRef(t *testing.T) {
+	tests := map[string]struct {
+		ref    string
+		expRef cmmeta.IssuerReference
+		expErr bool
+	}{
+		"a reference with a kind, group and name should parse": {
+			ref:    "ClusterIssuer.example.io/payments-ca",
+			expRef: cmmeta.IssuerReference{Name: "payments-ca", Kind: "ClusterIssuer", Group: "example.io"},
+		},
+		"a reference with no group should default the group": {
+			ref:    "Issuer/payments-ca",
+			expRef: cmmeta.IssuerReference{Name: "payments-ca", Kind: "Issuer", Group: "cert-manager.io"},
+		},
+		"a reference with no name should error": {
+			ref:    "ClusterIssuer.cert-manager.io/",
+			expErr: true,
+		},
+		"a reference with no kind should error": {
+			ref:    ".cert-manager.io/payments-ca",
+			expErr: true,
+		},
+		"a reference with an empty group should error": {
+			ref:    "ClusterIssuer./payments-ca",
+			expErr: true,
+		},
+		"a reference with no separator should error": {
+			ref:    "payments-ca",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseIssuerRef(test.ref)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expRef, ref)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sanpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+
+	"istio.io/istio/pkg/spiffe"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy is a list of rules which allow the workloads of specific service
+// accounts to request certificates with DNS and IP SANs, alongside their
+// SPIFFE ID. Requests from workloads matching no rule may not request DNS or
+// IP SANs.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule allows the workloads of a set of service accounts to request DNS names
+// and IP addresses.
+type Rule struct {
+	// Name is an optional name of the rule, used for logging.
+	Name string `json:"name,omitempty"`
+
+	// ServiceAccounts are the service accounts whose workloads match this
+	// rule, in the form "<namespace>/<name>".
+	ServiceAccounts []string `json:"serviceAccounts"`
+
+	// DNSNames are the DNS names which matching workloads may request.
+	// Entries are either exact DNS names, or a wildcard "*.<domain>", which
+	// matches any single label under the domain.
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// IPAddresses are the IP addresses which matching workloads may request.
+	// Entries are either single IP addresses, or CIDR ranges.
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+
+	serviceAccounts map[string]struct{}
+	prefixes        []netip.Prefix
+}
+
+// Load reads and validates the SAN policy at the given file path.
+func Load(filepath string) (*Policy, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SAN policy file %q: %w", filepath, err)
+	}
+
+	policy := new(Policy)
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to decode SAN policy file %q: %w", filepath, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SAN policy file %q: %w", filepath, err)
+	}
+
+	return policy, nil
+}
+
+// Validate ensures that all rules of the policy are well formed.
+func (p *Policy) Validate() error {
+	var errs []error
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		if len(rule.ServiceAccounts) == 0 {
+			errs = append(errs, fmt.Errorf("rule[%d]: at least one service account must be set", i))
+		}
+
+		if len(rule.DNSNames) == 0 && len(rule.IPAddresses) == 0 {
+			errs = append(errs, fmt.Errorf("rule[%d]: at least one of dnsNames or ipAddresses must be set", i))
+		}
+
+		rule.serviceAccounts = make(map[string]struct{}, len(rule.ServiceAccounts))
+		for _, sa := range rule.ServiceAccounts {
+			ns, name, ok := strings.Cut(sa, "/")
+			if !ok || len(ns) == 0 || len(name) == 0 {
+				errs = append(errs, fmt.Errorf("rule[%d]: invalid service account %q, expected the form <namespace>/<name>", i, sa))
+				continue
+			}
+			rule.serviceAccounts[sa] = struct{}{}
+		}
+
+		for j, dnsName := range rule.DNSNames {
+			dnsName = strings.ToLower(dnsName)
+			if msgs := validateDNSPattern(dnsName); len(msgs) > 0 {
+				errs = append(errs, fmt.Errorf("rule[%d]: invalid DNS name %q: %s", i, dnsName, strings.Join(msgs, ", ")))
+			}
+			rule.DNSNames[j] = dnsName
+		}
+
+		rule.prefixes = nil
+		for _, ip := range rule.IPAddresses {
+			prefix, err := parsePrefix(ip)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule[%d]: invalid IP address %q: %w", i, ip, err))
+				continue
+			}
+			rule.prefixes = append(rule.prefixes, prefix)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Check returns an error if any of the DNS names or IP addresses are not
+// allowed for the given identities. A name or address is allowed if it is
+// allowed by a rule matching all identities. A nil Policy allows no DNS names
+// or IP addresses.
+func (p *Policy) Check(identities []string, dnsNames []string, ips []net.IP) error {
+	if len(dnsNames) == 0 && len(ips) == 0 {
+		return nil
+	}
+
+	var rules []*Rule
+	if p != nil {
+		for i := range p.Rules {
+			if p.Rules[i].matches(identities) {
+				rules = append(rules, &p.Rules[i])
+			}
+		}
+	}
+
+	var errs []error
+	for _, dnsName := range dnsNames {
+		if !anyRule(rules, func(r *Rule) bool { return r.allowsDNSName(dnsName) }) {
+			errs = append(errs, fmt.Errorf("DNS name %q is not allowed", dnsName))
+		}
+	}
+
+	for _, ip := range ips {
+		if !anyRule(rules, func(r *Rule) bool { return r.allowsIP(ip) }) {
+			errs = append(errs, fmt.Errorf("IP address %q is not allowed", ip))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// matches returns true if all of the identities are of a service account of
+// the rule.
+func (r *Rule) matches(identities []string) bool {
+	if len(identities) == 0 {
+		return false
+	}
+
+	for _, identity := range identities {
+		id, err := spiffe.ParseIdentity(identity)
+		if err != nil {
+			return false
+		}
+		if _, ok := r.serviceAccounts[id.Namespace+"/"+id.ServiceAccount]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allowsDNSName returns true if the DNS name is allowed by the rule. Wildcard
+// patterns match exactly one label. A requested wildcard DNS name is only
+// allowed if the rule contains the same wildcard.
+func (r *Rule) allowsDNSName(dnsName string) bool {
+	dnsName = strings.ToLower(dnsName)
+	if len(validation.IsDNS1123Subdomain(dnsName)) > 0 && len(validation.IsWildcardDNS1123Subdomain(dnsName)) > 0 {
+		return false
+	}
+
+	for _, pattern := range r.DNSNames {
+		if pattern == dnsName {
+			return true
+		}
+
+		domain, ok := strings.CutPrefix(pattern, "*.")
+		if !ok {
+			continue
+		}
+
+		label, rest, ok := strings.Cut(dnsName, ".")
+		if ok && rest == domain && len(label) > 0 && !strings.Contains(label, "*") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsIP returns true if the IP address is allowed by the rule.
+func (r *Rule) allowsIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range r.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateDNSPattern returns validation errors of a DNS name or wildcard DNS
+// name pattern.
+func validateDNSPattern(pattern string) []string {
+	if strings.HasPrefix(pattern, "*.") {
+		return validation.IsWildcardDNS1123Subdomain(pattern)
+	}
+	return validation.IsDNS1123Subdomain(pattern)
+}
+
+// parsePrefix parses an IP address or CIDR range.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// anyRule returns true if the function returns true for any of the rules.
+func anyRule(rules []*Rule, fn func(*Rule) bool) bool {
+	for _, rule := range rules {
+		if fn(rule) {
+			return true
+		}
+	}
+	return false
+}
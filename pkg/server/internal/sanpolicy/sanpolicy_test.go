@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sanpolicy
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		policy string
+		expErr bool
+	}{
+		"a valid policy should be loaded": {
+			policy: `rules:
+- name: ingress
+  serviceAccounts: ["istio-ingress/istio-ingressgateway"]
+  dnsNames: ["api.example.com", "*.apps.example.com"]
+  ipAddresses: ["203.0.113.0/24", "2001:db8::1"]
+- serviceAccounts: ["istio-egress/istio-egressgateway"]
+  ipAddresses: ["198.51.100.7"]
+`,
+		},
+		"an unknown field should error": {
+			policy: `rules:
+- serviceAccounts: ["istio-ingress/istio-ingressgateway"]
+  hostnames: ["api.example.com"]
+`,
+			expErr: true,
+		},
+		"a rule with no service accounts should error": {
+			policy: `rules:
+- dnsNames: ["api.example.com"]
+`,
+			expErr: true,
+		},
+		"a rule with an invalid service account should error": {
+			policy: `rules:
+- serviceAccounts: ["istio-ingressgateway"]
+  dnsNames: ["api.example.com"]
+`,
+			expErr: true,
+		},
+		"a rule with no DNS names or IP addresses should error": {
+			policy: `rules:
+- serviceAccounts: ["istio-ingress/istio-ingressgateway"]
+`,
+			expErr: true,
+		},
+		"a rule with an invalid DNS name should error": {
+			policy: `rules:
+- serviceAccounts: ["istio-ingress/istio-ingressgateway"]
+  dnsNames: ["api.*.example.com"]
+`,
+			expErr: true,
+		},
+		"a rule with an invalid IP address should error": {
+			policy: `rules:
+- serviceAccounts: ["istio-ingress/istio-ingressgateway"]
+  ipAddresses: ["203.0.113.0/33"]
+`,
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(test.policy), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			policy, err := Load(path)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expErr, policy == nil)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	const (
+		ingress = "spiffe://cluster.local/ns/istio-ingress/sa/istio-ingressgateway"
+		egress  = "spiffe://cluster.local/ns/istio-egress/sa/istio-egressgateway"
+		other   = "spiffe://cluster.local/ns/default/sa/default"
+	)
+
+	policy := &Policy{
+		Rules: []Rule{
+			{
+				Name:            "ingress",
+				ServiceAccounts: []string{"istio-ingress/istio-ingressgateway"},
+				DNSNames:        []string{"API.example.com", "*.apps.example.com"},
+				IPAddresses:     []string{"203.0.113.0/24", "2001:db8::1"},
+			},
+			{
+				Name:            "egress",
+				ServiceAccounts: []string{"istio-egress/istio-egressgateway"},
+				IPAddresses:     []string{"198.51.100.7"},
+			},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		policy     *Policy
+		identities []string
+		dnsNames   []string
+		ips        []string
+		expErr     bool
+	}{
+		"if no DNS names or IP addresses, should allow any identity": {
+			policy:     policy,
+			identities: []string{other},
+		},
+		"if nil policy and no DNS names or IP addresses, should allow": {
+			identities: []string{other},
+		},
+		"if nil policy and DNS names, should error": {
+			identities: []string{ingress},
+			dnsNames:   []string{"api.example.com"},
+			expErr:     true,
+		},
+		"if exact DNS name is allowed, should allow regardless of case": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"api.EXAMPLE.com"},
+		},
+		"if DNS name matches wildcard, should allow": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"foo.apps.example.com"},
+		},
+		"if DNS name is the wildcard itself, should allow": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"*.apps.example.com"},
+		},
+		"if DNS name is multiple labels under wildcard, should error": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"foo.bar.apps.example.com"},
+			expErr:     true,
+		},
+		"if DNS name is the wildcard domain itself, should error": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"apps.example.com"},
+			expErr:     true,
+		},
+		"if DNS name is a partial wildcard, should error": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"f*.apps.example.com"},
+			expErr:     true,
+		},
+		"if one of the DNS names is not allowed, should error": {
+			policy:     policy,
+			identities: []string{ingress},
+			dnsNames:   []string{"api.example.com", "evil.example.com"},
+			expErr:     true,
+		},
+		"if DNS name is allowed for a different service account, should error": {
+			policy:     policy,
+			identities: []string{other},
+			dnsNames:   []string{"api.example.com"},
+			expErr:     true,
+		},
+		"if IP address is in an allowed range, should allow": {
+			policy:     policy,
+			identities: []string{ingress},
+			ips:        []string{"203.0.113.10", "2001:db8::1"},
+		},
+		"if IP address is not in an allowed range, should error": {
+			policy:     policy,
+			identities: []string{ingress},
+			ips:        []string{"203.0.114.10"},
+			expErr:     true,
+		},
+		"if IP address is allowed but DNS names are not allowed for the rule, should error": {
+			policy:     policy,
+			identities: []string{egress},
+			dnsNames:   []string{"api.example.com"},
+			ips:        []string{"198.51.100.7"},
+			expErr:     true,
+		},
+		"if identities match different rules, should error": {
+			policy:     policy,
+			identities: []string{ingress, egress},
+			ips:        []string{"198.51.100.7"},
+			expErr:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ips []net.IP
+			for _, ip := range test.ips {
+				ips = append(ips, net.ParseIP(ip))
+			}
+
+			err := test.policy.Check(test.identities, test.dnsNames, ips)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	keyAlgorithmRSA     = "RSA"
+	keyAlgorithmECDSA   = "ECDSA"
+	keyAlgorithmEd25519 = "Ed25519"
+)
+
+// supportedCurves are the names of the elliptic curves that may be allowed
+// for ECDSA keys.
+var supportedCurves = []string{"P-224", "P-256", "P-384", "P-521"}
+
+// KeyPolicyOptions configures which public keys workload CSRs may use. If no
+// options are set, any public key is allowed.
+type KeyPolicyOptions struct {
+	// AllowedAlgorithms are the public key algorithms CSRs may use. Valid
+	// values are "RSA", "ECDSA" and "Ed25519". If empty, all algorithms are
+	// allowed.
+	AllowedAlgorithms []string
+
+	// MinRSAKeySize is the minimum size in bits of RSA keys. If zero, RSA
+	// keys of any size are allowed.
+	MinRSAKeySize int
+
+	// AllowedCurves are the elliptic curves ECDSA keys may use, e.g. "P-256".
+	// If empty, all curves are allowed.
+	AllowedCurves []string
+}
+
+// keyPolicy enforces the key policy on CSR public keys. A nil keyPolicy
+// allows any key.
+type keyPolicy struct {
+	algorithms    []string
+	minRSAKeySize int
+	curves        []string
+}
+
+// newKeyPolicy validates the options and returns the key policy they
+// configure. Returns a nil keyPolicy, allowing any key, if no options are
+// set.
+func newKeyPolicy(opts KeyPolicyOptions) (*keyPolicy, error) {
+	for _, alg := range opts.AllowedAlgorithms {
+		if !slices.Contains([]string{keyAlgorithmRSA, keyAlgorithmECDSA, keyAlgorithmEd25519}, alg) {
+			return nil, fmt.Errorf("unknown key algorithm %q, must be one of %q, %q or %q", alg, keyAlgorithmRSA, keyAlgorithmECDSA, keyAlgorithmEd25519)
+		}
+	}
+
+	for _, curve := range opts.AllowedCurves {
+		if !slices.Contains(supportedCurves, curve) {
+			return nil, fmt.Errorf("unknown elliptic curve %q, must be one of %s", curve, strings.Join(supportedCurves, ", "))
+		}
+	}
+
+	if opts.MinRSAKeySize < 0 {
+		return nil, fmt.Errorf("minimum RSA key size must not be negative, got %d", opts.MinRSAKeySize)
+	}
+
+	if len(opts.AllowedAlgorithms) == 0 && opts.MinRSAKeySize == 0 && len(opts.AllowedCurves) == 0 {
+		return nil, nil
+	}
+
+	return &keyPolicy{
+		algorithms:    opts.AllowedAlgorithms,
+		minRSAKeySize: opts.MinRSAKeySize,
+		curves:        opts.AllowedCurves,
+	}, nil
+}
+
+// check returns an error describing why the public key is not allowed by the
+// policy, or nil if it is allowed.
+func (p *keyPolicy) check(pub any) error {
+	if p == nil {
+		return nil
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if err := p.checkAlgorithm(keyAlgorithmRSA); err != nil {
+			return err
+		}
+		if size := pub.N.BitLen(); size < p.minRSAKeySize {
+			return fmt.Errorf("RSA key size %d is smaller than the minimum of %d", size, p.minRSAKeySize)
+		}
+
+	case *ecdsa.PublicKey:
+		if err := p.checkAlgorithm(keyAlgorithmECDSA); err != nil {
+			return err
+		}
+		curve := pub.Curve.Params().Name
+		if len(p.curves) > 0 && !slices.Contains(p.curves, curve) {
+			return fmt.Errorf("elliptic curve %s is not allowed, must be one of %s", curve, strings.Join(p.curves, ", "))
+		}
+
+	case ed25519.PublicKey:
+		if err := p.checkAlgorithm(keyAlgorithmEd25519); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	return nil
+}
+
+// checkAlgorithm returns an error if the key algorithm is not allowed.
+func (p *keyPolicy) checkAlgorithm(alg string) error {
+	if len(p.algorithms) > 0 && !slices.Contains(p.algorithms, alg) {
+		return fmt.Errorf("key algorithm %s is not allowed, must be one of %s", alg, strings.Join(p.algorithms, ", "))
+	}
+	return nil
+}
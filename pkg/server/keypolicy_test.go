@@ -0,0 +1,165 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newKeyPolicy(t *testing.T) {
+	tests := map[string]struct {
+		opts   KeyPolicyOptions
+		expErr bool
+	}{
+		"if no options, should not error": {
+			opts: KeyPolicyOptions{},
+		},
+		"if valid options, should not error": {
+			opts: KeyPolicyOptions{
+				AllowedAlgorithms: []string{"RSA", "ECDSA", "Ed25519"},
+				MinRSAKeySize:     2048,
+				AllowedCurves:     []string{"P-256", "P-384"},
+			},
+		},
+		"if unknown algorithm, should error": {
+			opts:   KeyPolicyOptions{AllowedAlgorithms: []string{"DSA"}},
+			expErr: true,
+		},
+		"if unknown curve, should error": {
+			opts:   KeyPolicyOptions{AllowedCurves: []string{"secp256k1"}},
+			expErr: true,
+		},
+		"if negative minimum RSA key size, should error": {
+			opts:   KeyPolicyOptions{MinRSAKeySize: -1},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := newKeyPolicy(test.opts)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_keyPolicyCheck(t *testing.T) {
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa2048, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strictOpts := KeyPolicyOptions{
+		AllowedAlgorithms: []string{"RSA", "ECDSA", "Ed25519"},
+		MinRSAKeySize:     2048,
+		AllowedCurves:     []string{"P-256", "P-384", "P-521"},
+	}
+
+	tests := map[string]struct {
+		opts   *KeyPolicyOptions
+		pub    any
+		expErr string
+	}{
+		"if no policy, should allow any key": {
+			opts: nil,
+			pub:  &rsa1024.PublicKey,
+		},
+		"if RSA key meets the minimum size, should allow": {
+			opts: &strictOpts,
+			pub:  &rsa2048.PublicKey,
+		},
+		"if RSA key is smaller than the minimum size, should reject": {
+			opts:   &strictOpts,
+			pub:    &rsa1024.PublicKey,
+			expErr: "RSA key size 1024 is smaller than the minimum of 2048",
+		},
+		"if ECDSA key uses an allowed curve, should allow": {
+			opts: &strictOpts,
+			pub:  &p256.PublicKey,
+		},
+		"if ECDSA key uses a curve which is not allowed, should reject": {
+			opts:   &strictOpts,
+			pub:    &p224.PublicKey,
+			expErr: "elliptic curve P-224 is not allowed, must be one of P-256, P-384, P-521",
+		},
+		"if no curves are configured, should allow any curve": {
+			opts: &KeyPolicyOptions{AllowedAlgorithms: []string{"ECDSA"}},
+			pub:  &p224.PublicKey,
+		},
+		"if no options are configured, should allow any key": {
+			opts: &KeyPolicyOptions{},
+			pub:  &rsa1024.PublicKey,
+		},
+		"if Ed25519 key is allowed, should allow": {
+			opts: &strictOpts,
+			pub:  ed,
+		},
+		"if algorithm is not allowed, should reject": {
+			opts:   &KeyPolicyOptions{AllowedAlgorithms: []string{"ECDSA"}},
+			pub:    ed,
+			expErr: "key algorithm Ed25519 is not allowed, must be one of ECDSA",
+		},
+		"if key type is unsupported, should reject": {
+			opts:   &strictOpts,
+			pub:    "not a key",
+			expErr: "unsupported public key type string",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var policy *keyPolicy
+			if test.opts != nil {
+				var err error
+				policy, err = newKeyPolicy(*test.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := policy.check(test.pub)
+			if len(test.expErr) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// requestIDHeader is the gRPC response header holding the ID of the
+	// request, so that client logs can be correlated with istio-csr logs.
+	requestIDHeader = "x-request-id"
+)
+
+// loggingInterceptor is a unary gRPC interceptor which stores a request-scoped
+// logger in the request context. The logger is tagged with a unique request
+// ID and the peer address of the caller, so that all log lines of a single
+// request can be correlated. The request ID is returned to the client as a
+// response header.
+func (s *Server) loggingInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	requestID := string(uuid.NewUUID())
+
+	log := s.log.WithValues("request-id", requestID)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		log = log.WithValues("peer", p.Addr.String())
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID)); err != nil {
+		log.V(4).Info("failed to set request ID response header", "error", err)
+	}
+
+	return handler(logr.NewContext(ctx, log), req)
+}
+
+// requestLogger returns the request-scoped logger stored in the context. If
+// none is stored, the server's logger is returned.
+func (s *Server) requestLogger(ctx context.Context) logr.Logger {
+	if log, err := logr.FromContext(ctx); err == nil {
+		return log
+	}
+	return s.log
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/peer"
+)
+
+func TestLoggingInterceptor(t *testing.T) {
+	var lines []string
+	s := &Server{
+		log: funcr.New(func(_, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{}),
+	}
+
+	ctx := peer.NewContext(t.Context(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+	})
+
+	var requestIDs []string
+	for range 2 {
+		_, err := s.loggingInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+			_, err := logr.FromContext(ctx)
+			assert.NoError(t, err, "expected request logger to be stored in context")
+
+			s.requestLogger(ctx).Info("test")
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	}
+
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+
+	for _, line := range lines {
+		assert.Contains(t, line, `"peer"="10.0.0.1:1234"`)
+
+		_, after, ok := strings.Cut(line, `"request-id"="`)
+		if assert.True(t, ok, "expected request-id in log line: %s", line) {
+			requestID, _, _ := strings.Cut(after, `"`)
+			assert.NotEmpty(t, requestID)
+			requestIDs = append(requestIDs, requestID)
+		}
+	}
+
+	assert.NotEqual(t, requestIDs[0], requestIDs[1], "expected unique request IDs")
+}
+
+func TestRequestLogger(t *testing.T) {
+	var lines []string
+	s := &Server{
+		log: funcr.New(func(_, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{}),
+	}
+
+	s.requestLogger(t.Context()).Info("test")
+	assert.Equal(t, []string{`"level"=0 "msg"="test"`}, lines, "expected server logger if context has no logger")
+}
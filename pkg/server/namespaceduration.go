@@ -0,0 +1,232 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"istio.io/istio/pkg/spiffe"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// namespaceDurationAnnotationPrefix is the prefix of the namespace
+	// annotations configuring the duration of certificates issued to
+	// workloads in that namespace.
+	namespaceDurationAnnotationPrefix = "istio.cert-manager.io/"
+
+	// annotationMaxCertificateDuration is the namespace annotation holding the
+	// maximum duration of certificates issued to workloads in the namespace.
+	// The global maximum still applies if it is lower.
+	annotationMaxCertificateDuration = namespaceDurationAnnotationPrefix + "max-certificate-duration"
+
+	// annotationMinCertificateDuration is the namespace annotation holding the
+	// minimum duration of certificates issued to workloads in the namespace.
+	// Requests for shorter durations are extended to the minimum.
+	annotationMinCertificateDuration = namespaceDurationAnnotationPrefix + "min-certificate-duration"
+
+	// annotationDefaultCertificateDuration is the namespace annotation
+	// holding the duration of certificates issued to workloads in the
+	// namespace which do not request a duration.
+	annotationDefaultCertificateDuration = namespaceDurationAnnotationPrefix + "default-certificate-duration"
+)
+
+// durationLimits are the certificate duration limits of a namespace. Zero
+// values are unset.
+type durationLimits struct {
+	max, min, def time.Duration
+}
+
+// parseDurationLimits parses the certificate duration limits from the
+// annotations of a namespace.
+func parseDurationLimits(annotations map[string]string) (durationLimits, error) {
+	var limits durationLimits
+	var errs []error
+	for annotation, limit := range map[string]*time.Duration{
+		annotationMaxCertificateDuration:     &limits.max,
+		annotationMinCertificateDuration:     &limits.min,
+		annotationDefaultCertificateDuration: &limits.def,
+	} {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			errs = append(errs, fmt.Errorf("invalid duration %q for annotation %q, must be a positive duration", value, annotation))
+			continue
+		}
+		*limit = duration
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return durationLimits{}, err
+	}
+
+	if limits.max > 0 && limits.min > limits.max {
+		return durationLimits{}, fmt.Errorf("minimum certificate duration %s is larger than the maximum of %s", limits.min, limits.max)
+	}
+
+	return limits, nil
+}
+
+// merge returns the strictest combination of both limits: the lowest maximum
+// and default, and the highest minimum.
+func (l durationLimits) merge(o durationLimits) durationLimits {
+	lowest := func(a, b time.Duration) time.Duration {
+		if a == 0 || b == 0 {
+			return max(a, b)
+		}
+		return min(a, b)
+	}
+
+	return durationLimits{
+		max: lowest(l.max, o.max),
+		min: max(l.min, o.min),
+		def: lowest(l.def, o.def),
+	}
+}
+
+// grant returns the certificate duration granted to a request for the given
+// duration, along with the effective maximum duration, which is the lowest of
+// the global and namespace maximums. A request without a duration is granted
+// the namespace default, if set. The minimum is applied before the maximum, so
+// the maximum always wins.
+func (l durationLimits) grant(requested, globalMax time.Duration) (time.Duration, time.Duration) {
+	maxDuration := globalMax
+	if l.max > 0 {
+		maxDuration = min(maxDuration, l.max)
+	}
+
+	duration := requested
+	if duration <= 0 {
+		duration = l.def
+	}
+	if l.min > 0 {
+		duration = max(duration, l.min)
+	}
+
+	return min(duration, maxDuration), maxDuration
+}
+
+// namespaceDurations reads the certificate duration limits of namespaces from
+// their annotations, using a cached Namespace informer.
+type namespaceDurations struct {
+	informer cache.SharedIndexInformer
+}
+
+// newNamespaceDurations returns a new namespaceDurations watching all
+// namespaces. Only the duration annotations of namespaces are cached.
+func newNamespaceDurations(client kubernetes.Interface) *namespaceDurations {
+	informer := coreinformers.NewNamespaceInformer(client, 0, cache.Indexers{})
+
+	_ = informer.SetTransform(func(obj any) (any, error) {
+		ns, ok := obj.(*corev1.Namespace)
+		if !ok {
+			return obj, nil
+		}
+
+		annotations := make(map[string]string)
+		for k, v := range ns.Annotations {
+			if strings.HasPrefix(k, namespaceDurationAnnotationPrefix) {
+				annotations[k] = v
+			}
+		}
+
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            ns.Name,
+				ResourceVersion: ns.ResourceVersion,
+				Annotations:     annotations,
+			},
+		}, nil
+	})
+
+	return &namespaceDurations{
+		informer: informer,
+	}
+}
+
+// Start runs the Namespace informer until the context is cancelled.
+func (n *namespaceDurations) Start(ctx context.Context) {
+	n.informer.RunWithContext(ctx)
+}
+
+// synced returns true once the Namespace informer has synced.
+func (n *namespaceDurations) synced() bool {
+	return n.informer.HasSynced()
+}
+
+// limits returns the strictest duration limits of the namespaces of the given
+// identities. Namespaces which do not exist have no limits.
+func (n *namespaceDurations) limits(identities []string) (durationLimits, error) {
+	var limits durationLimits
+	for _, identity := range identities {
+		id, err := spiffe.ParseIdentity(identity)
+		if err != nil {
+			continue
+		}
+
+		obj, exists, err := n.informer.GetIndexer().GetByKey(id.Namespace)
+		if err != nil {
+			return durationLimits{}, fmt.Errorf("failed to get namespace %q: %w", id.Namespace, err)
+		}
+		if !exists {
+			continue
+		}
+
+		nsLimits, err := parseDurationLimits(obj.(*corev1.Namespace).Annotations)
+		if err != nil {
+			return durationLimits{}, fmt.Errorf("namespace %q: %w", id.Namespace, err)
+		}
+		limits = limits.merge(nsLimits)
+	}
+
+	return limits, nil
+}
+
+// certificateDuration returns the duration of the certificate to issue for
+// the requested duration, along with the effective maximum duration. If
+// namespace duration annotations are enabled, the limits of the namespaces of
+// the identities are applied.
+func (s *Server) certificateDuration(identities []string, requested time.Duration) (time.Duration, time.Duration, error) {
+	var limits durationLimits
+	if s.namespaceDurations != nil {
+		if !s.namespaceDurations.synced() {
+			return 0, 0, newRequestError(codes.Unavailable, reasonNamespacesUnavailable, "namespace certificate duration limits are not yet available, retry later", nil)
+		}
+
+		var err error
+		limits, err = s.namespaceDurations.limits(identities)
+		if err != nil {
+			return 0, 0, newRequestError(codes.FailedPrecondition, reasonInvalidNamespaceDuration, "invalid namespace certificate duration annotations", err)
+		}
+	}
+
+	duration, maxDuration := limits.grant(requested, s.opts.MaximumClientCertificateDuration)
+	return duration, maxDuration, nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func Test_parseDurationLimits(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		expLimits   durationLimits
+		expErr      bool
+	}{
+		"if no annotations, should return no limits": {
+			annotations: nil,
+		},
+		"if unrelated annotations, should return no limits": {
+			annotations: map[string]string{"foo": "bar"},
+		},
+		"if all annotations set, should return limits": {
+			annotations: map[string]string{
+				annotationMaxCertificateDuration:     "1h",
+				annotationMinCertificateDuration:     "10m",
+				annotationDefaultCertificateDuration: "30m",
+			},
+			expLimits: durationLimits{max: time.Hour, min: 10 * time.Minute, def: 30 * time.Minute},
+		},
+		"if invalid duration, should error": {
+			annotations: map[string]string{annotationMaxCertificateDuration: "1 hour"},
+			expErr:      true,
+		},
+		"if non-positive duration, should error": {
+			annotations: map[string]string{annotationMinCertificateDuration: "0s"},
+			expErr:      true,
+		},
+		"if minimum is larger than maximum, should error": {
+			annotations: map[string]string{
+				annotationMaxCertificateDuration: "15m",
+				annotationMinCertificateDuration: "1h",
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limits, err := parseDurationLimits(test.annotations)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expLimits, limits)
+		})
+	}
+}
+
+func Test_durationLimitsGrant(t *testing.T) {
+	tests := map[string]struct {
+		limits    durationLimits
+		requested time.Duration
+		globalMax time.Duration
+
+		expDuration    time.Duration
+		expMaxDuration time.Duration
+	}{
+		"if no limits, should cap at the global maximum": {
+			requested:      24 * time.Hour,
+			globalMax:      time.Hour,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if no limits and request is below the global maximum, should grant request": {
+			requested:      30 * time.Minute,
+			globalMax:      time.Hour,
+			expDuration:    30 * time.Minute,
+			expMaxDuration: time.Hour,
+		},
+		"if namespace maximum is lower than global, should cap at namespace maximum": {
+			limits:         durationLimits{max: 15 * time.Minute},
+			requested:      time.Hour,
+			globalMax:      24 * time.Hour,
+			expDuration:    15 * time.Minute,
+			expMaxDuration: 15 * time.Minute,
+		},
+		"if namespace maximum is higher than global, should cap at global maximum": {
+			limits:         durationLimits{max: 24 * time.Hour},
+			requested:      24 * time.Hour,
+			globalMax:      time.Hour,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if request is below the namespace minimum, should grant the minimum": {
+			limits:         durationLimits{min: 30 * time.Minute},
+			requested:      time.Minute,
+			globalMax:      time.Hour,
+			expDuration:    30 * time.Minute,
+			expMaxDuration: time.Hour,
+		},
+		"if namespace minimum is above the global maximum, should cap at global maximum": {
+			limits:         durationLimits{min: 2 * time.Hour},
+			requested:      time.Minute,
+			globalMax:      time.Hour,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if no duration requested, should grant the namespace default": {
+			limits:         durationLimits{def: 20 * time.Minute},
+			globalMax:      time.Hour,
+			expDuration:    20 * time.Minute,
+			expMaxDuration: time.Hour,
+		},
+		"if duration requested, should ignore the namespace default": {
+			limits:         durationLimits{def: 20 * time.Minute},
+			requested:      40 * time.Minute,
+			globalMax:      time.Hour,
+			expDuration:    40 * time.Minute,
+			expMaxDuration: time.Hour,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			duration, maxDuration := test.limits.grant(test.requested, test.globalMax)
+			assert.Equal(t, test.expDuration, duration, "duration")
+			assert.Equal(t, test.expMaxDuration, maxDuration, "max duration")
+		})
+	}
+}
+
+func Test_certificateDuration(t *testing.T) {
+	namespace := func(name string, annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	nsDurations := newNamespaceDurations(fake.NewClientset(
+		namespace("pci", map[string]string{
+			annotationMaxCertificateDuration: "15m",
+			"unrelated":                      "foo",
+		}),
+		namespace("long", map[string]string{annotationMaxCertificateDuration: "24h", annotationMinCertificateDuration: "2h"}),
+		namespace("default", nil),
+		namespace("invalid", map[string]string{annotationMaxCertificateDuration: "forever"}),
+	))
+	go nsDurations.Start(t.Context())
+	if !cache.WaitForCacheSync(t.Context().Done(), nsDurations.synced) {
+		t.Fatal("namespace informer did not sync")
+	}
+
+	tests := map[string]struct {
+		identities []string
+		requested  time.Duration
+
+		expDuration    time.Duration
+		expMaxDuration time.Duration
+		expCode        codes.Code
+	}{
+		"if namespace has no annotations, should apply the global maximum": {
+			identities:     []string{"spiffe://cluster.local/ns/default/sa/foo"},
+			requested:      24 * time.Hour,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if namespace does not exist, should apply the global maximum": {
+			identities:     []string{"spiffe://cluster.local/ns/missing/sa/foo"},
+			requested:      24 * time.Hour,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if namespace has a lower maximum, should apply the namespace maximum": {
+			identities:     []string{"spiffe://cluster.local/ns/pci/sa/foo"},
+			requested:      time.Hour,
+			expDuration:    15 * time.Minute,
+			expMaxDuration: 15 * time.Minute,
+		},
+		"if namespace has a higher maximum and minimum, should apply the global maximum": {
+			identities:     []string{"spiffe://cluster.local/ns/long/sa/foo"},
+			requested:      time.Minute,
+			expDuration:    time.Hour,
+			expMaxDuration: time.Hour,
+		},
+		"if identities span namespaces, should apply the strictest limits": {
+			identities:     []string{"spiffe://cluster.local/ns/default/sa/foo", "spiffe://cluster.local/ns/pci/sa/foo"},
+			requested:      time.Hour,
+			expDuration:    15 * time.Minute,
+			expMaxDuration: 15 * time.Minute,
+		},
+		"if namespace has invalid annotations, should error": {
+			identities: []string{"spiffe://cluster.local/ns/invalid/sa/foo"},
+			requested:  time.Hour,
+			expCode:    codes.FailedPrecondition,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				opts:               Options{MaximumClientCertificateDuration: time.Hour},
+				namespaceDurations: nsDurations,
+			}
+
+			duration, maxDuration, err := s.certificateDuration(test.identities, test.requested)
+			if test.expCode == codes.OK {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expCode, status.Code(s.statusError(err)))
+			}
+			assert.Equal(t, test.expDuration, duration, "duration")
+			assert.Equal(t, test.expMaxDuration, maxDuration, "max duration")
+		})
+	}
+
+	t.Run("if namespace informer has not synced, should return unavailable", func(t *testing.T) {
+		s := &Server{
+			opts:               Options{MaximumClientCertificateDuration: time.Hour},
+			namespaceDurations: newNamespaceDurations(fake.NewClientset()),
+		}
+
+		_, _, err := s.certificateDuration([]string{"spiffe://cluster.local/ns/pci/sa/foo"}, time.Hour)
+		assert.Equal(t, codes.Unavailable, status.Code(s.statusError(err)))
+	})
+
+	t.Run("if namespace informer has synced, should only cache duration annotations", func(t *testing.T) {
+		obj, exists, err := nsDurations.informer.GetIndexer().GetByKey("pci")
+		if assert.NoError(t, err) && assert.True(t, exists) {
+			assert.Equal(t, map[string]string{annotationMaxCertificateDuration: "15m"}, obj.(*corev1.Namespace).Annotations)
+		}
+	})
+}
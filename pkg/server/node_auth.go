@@ -17,11 +17,13 @@ limitations under the License.
 package server
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/go-logr/logr"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/kube/kclient"
-	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/pkg/util/sets"
@@ -46,15 +48,53 @@ type ClusterNodeAuthorizer struct {
 }
 
 func NewClusterNodeAuthorizer(client kube.Client, trustedNodeAccounts sets.Set[types.NamespacedName]) *ClusterNodeAuthorizer {
-	pods := kclient.NewFiltered[*v1.Pod](client, kclient.Filter{
-		ObjectFilter:    client.ObjectFilter(),
-		ObjectTransform: kube.StripPodUnusedFields,
-	})
+	pods := newNodeAuthPodClient(client)
 
 	stopCh := make(chan struct{})
 	client.RunAndWait(stopCh)
 	kube.WaitForCacheSync("nodeAuth", stopCh, pods.HasSynced)
 
+	return newClusterNodeAuthorizerForPods(pods, trustedNodeAccounts)
+}
+
+// startClusterNodeAuthorizer returns a ClusterNodeAuthorizer whose pod
+// informer runs until stop is closed. Returns an error if the pod informer
+// does not sync within the timeout, or before stop is closed, so that an
+// unreachable cluster cannot block the caller indefinitely.
+func startClusterNodeAuthorizer(client kube.Client, trustedNodeAccounts sets.Set[types.NamespacedName], stop <-chan struct{}, syncTimeout time.Duration) (*ClusterNodeAuthorizer, error) {
+	pods := newNodeAuthPodClient(client)
+
+	go client.RunAndWait(stop)
+
+	syncCtx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-syncCtx.Done():
+		}
+	}()
+
+	if !kube.WaitForCacheSync("nodeAuth", syncCtx.Done(), pods.HasSynced) {
+		return nil, fmt.Errorf("pod informer did not sync within %s", syncTimeout)
+	}
+
+	return newClusterNodeAuthorizerForPods(pods, trustedNodeAccounts), nil
+}
+
+// newNodeAuthPodClient returns the pod client used by the node authorizer,
+// dropping pod fields which are not needed.
+func newNodeAuthPodClient(client kube.Client) kclient.Client[*v1.Pod] {
+	return kclient.NewFiltered[*v1.Pod](client, kclient.Filter{
+		ObjectFilter:    client.ObjectFilter(),
+		ObjectTransform: kube.StripPodUnusedFields,
+	})
+}
+
+// newClusterNodeAuthorizerForPods returns a ClusterNodeAuthorizer using the
+// synced pod client.
+func newClusterNodeAuthorizerForPods(pods kclient.Client[*v1.Pod], trustedNodeAccounts sets.Set[types.NamespacedName]) *ClusterNodeAuthorizer {
 	// Add an Index on the pods, storing the service account and node. This allows us to later efficiently query.
 	index := kclient.CreateIndex[ca.SaNode, *v1.Pod](pods, "podSANode", func(pod *v1.Pod) []ca.SaNode {
 		if len(pod.Spec.NodeName) == 0 {
@@ -79,38 +119,34 @@ func NewClusterNodeAuthorizer(client kube.Client, trustedNodeAccounts sets.Set[t
 	}
 }
 
-// authenticateImpersonation will verify the caller is authorized to impersonate the requested identity
-func (na *ClusterNodeAuthorizer) authenticateImpersonation(caller security.KubernetesInfo, requestedIdentityString string) error {
-	callerSa := types.NamespacedName{
-		Namespace: caller.PodNamespace,
-		Name:      caller.PodServiceAccount,
-	}
-
+// authenticateImpersonation will verify the caller is authorized to impersonate the requested identity.
+// Returns the node of the caller.
+func (na *ClusterNodeAuthorizer) authenticateImpersonation(ctx context.Context, caller security.KubernetesInfo, requestedIdentityString string) (string, error) {
 	// First, make sure the caller is allowed to impersonate, in general
-	if _, f := na.trustedNodeAccounts[callerSa]; !f {
-		return fmt.Errorf("caller (%v) is not allowed to impersonate", caller)
+	if !na.isTrustedNodeAccount(caller) {
+		return "", fmt.Errorf("caller (%v) is not allowed to impersonate", caller)
 	}
 
 	// Next, make sure the identity they want to impersonate is valid, in general
 	requestedIdentity, err := spiffe.ParseIdentity(requestedIdentityString)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Finally, we validate the requested identity is running on the same node the caller is on
 	callerPod := na.pods.Get(caller.PodName, caller.PodNamespace)
 	if callerPod == nil {
-		return fmt.Errorf("pod %v/%v not found", caller.PodNamespace, caller.PodName)
+		return "", fmt.Errorf("pod %v/%v not found", caller.PodNamespace, caller.PodName)
 	}
 	// Make sure UID is still valid for our current state
 	if callerPod.UID != types.UID(caller.PodUID) {
 		// This would only happen if a pod is re-created with the same name, and the CSR client is not in sync on which is current;
 		// this is fine and should be eventually consistent. Client is expected to retry in this case.
-		return fmt.Errorf("pod found, but UID does not match: %v vs %v", callerPod.UID, caller.PodUID)
+		return "", fmt.Errorf("pod found, but UID does not match: %v vs %v", callerPod.UID, caller.PodUID)
 	}
 	if callerPod.Spec.ServiceAccountName != caller.PodServiceAccount {
 		// This should never happen, but just in case add an additional check
-		return fmt.Errorf("pod found, but ServiceAccount does not match: %v vs %v", callerPod.Spec.ServiceAccountName, caller.PodServiceAccount)
+		return "", fmt.Errorf("pod found, but ServiceAccount does not match: %v vs %v", callerPod.Spec.ServiceAccountName, caller.PodServiceAccount)
 	}
 	// We want to find out if there is any pod running with the requested identity on the callers node.
 	// The indexer (previously setup) creates a lookup table for a {Node, SA} pair, which we can lookup
@@ -124,8 +160,18 @@ func (na *ClusterNodeAuthorizer) authenticateImpersonation(caller security.Kuber
 	// We don't care what pods are part of the index, only that there is at least one. If there is one,
 	// it is appropriate for the caller to request this identity.
 	if len(res) == 0 {
-		return fmt.Errorf("no instances of %q found on node %q", k.ServiceAccount, k.Node)
+		return "", fmt.Errorf("no instances of %q found on node %q", k.ServiceAccount, k.Node)
 	}
-	log.Debugf("Node caller %v impersonated %v", caller, requestedIdentityString)
-	return nil
+	logr.FromContextOrDiscard(ctx).V(3).Info("node caller impersonated identity",
+		"caller-pod", caller.PodNamespace+"/"+caller.PodName, "node", callerPod.Spec.NodeName, "impersonated-identity", requestedIdentityString)
+	return callerPod.Spec.NodeName, nil
+}
+
+// isTrustedNodeAccount returns true if the caller's service account is a
+// trusted node account, which is allowed to impersonate other identities.
+func (na *ClusterNodeAuthorizer) isTrustedNodeAccount(caller security.KubernetesInfo) bool {
+	return na.trustedNodeAccounts.Contains(types.NamespacedName{
+		Namespace: caller.PodNamespace,
+		Name:      caller.PodServiceAccount,
+	})
 }
@@ -163,7 +163,7 @@ func TestAuthImpersonation(t *testing.T) {
 			c.RunAndWait(testUtil.NewStop(t))
 			kube.WaitForCacheSync("test", testUtil.NewStop(t), na.pods.HasSynced)
 
-			err := na.authenticateImpersonation(test.caller, test.requestedIdentityString)
+			_, err := na.authenticateImpersonation(t.Context(), test.caller, test.requestedIdentityString)
 
 			errS, _ := status.FromError(err)
 			expErrS, _ := status.FromError(test.expErr)
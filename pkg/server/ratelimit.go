@@ -0,0 +1,228 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/cert-manager/istio-csr/pkg/server/internal/ratelimit"
+)
+
+const (
+	rateLimitScopeIdentity  = "identity"
+	rateLimitScopeNamespace = "namespace"
+)
+
+var (
+	metricRateLimitedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "rate_limited_certificate_requests",
+			Help:      "Total number of workload certificate requests rejected by the rate limiter. Scope is the limit which was exceeded, identity or namespace.",
+		}, []string{"scope"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricRateLimitedRequests)
+}
+
+// callerContextKey is the context key of the authenticated caller of a
+// request.
+type callerContextKey struct{}
+
+// RateLimitOptions configures token bucket rate limiting of incoming CSR
+// requests.
+type RateLimitOptions struct {
+	// IdentityQPS is the sustained number of requests per second allowed for a
+	// single workload identity. Zero disables per-identity rate limiting.
+	IdentityQPS float64
+
+	// IdentityBurst is the maximum burst of requests allowed for a single
+	// workload identity.
+	IdentityBurst int
+
+	// NamespaceQPS is the sustained number of requests per second allowed for
+	// all workload identities in a single namespace. Zero disables
+	// per-namespace rate limiting.
+	NamespaceQPS float64
+
+	// NamespaceBurst is the maximum burst of requests allowed for all
+	// workload identities in a single namespace.
+	NamespaceBurst int
+}
+
+// rateLimiters holds the rate limiters of each scope. A nil limiter is
+// disabled.
+type rateLimiters struct {
+	identity  *ratelimit.Limiter
+	namespace *ratelimit.Limiter
+}
+
+// newRateLimiters returns the rate limiters for the given options.
+func newRateLimiters(opts RateLimitOptions) (rateLimiters, error) {
+	var limiters rateLimiters
+
+	if opts.IdentityQPS < 0 || opts.NamespaceQPS < 0 {
+		return limiters, fmt.Errorf("rate limit qps must not be negative")
+	}
+
+	if opts.IdentityQPS > 0 {
+		if opts.IdentityBurst < 1 {
+			return limiters, fmt.Errorf("identity rate limit burst must be at least 1, got %d", opts.IdentityBurst)
+		}
+		limiters.identity = ratelimit.New(opts.IdentityQPS, opts.IdentityBurst)
+	}
+
+	if opts.NamespaceQPS > 0 {
+		if opts.NamespaceBurst < 1 {
+			return limiters, fmt.Errorf("namespace rate limit burst must be at least 1, got %d", opts.NamespaceBurst)
+		}
+		limiters.namespace = ratelimit.New(opts.NamespaceQPS, opts.NamespaceBurst)
+	}
+
+	return limiters, nil
+}
+
+// enabled returns true if any rate limiter is enabled.
+func (r rateLimiters) enabled() bool {
+	return r.identity != nil || r.namespace != nil
+}
+
+// rateLimitInterceptor is a unary gRPC interceptor which rate limits requests
+// by the authenticated identity of the caller, and the namespace of that
+// identity. Requests which exceed a limit are rejected with ResourceExhausted,
+// along with the delay after which the client may retry. The authenticated
+// caller is stored in the request context so the request is only
+// authenticated once.
+func (s *Server) rateLimitInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	caller, err := s.authenticate(ctx)
+	if err != nil {
+		// Unauthenticated requests are rejected by the handler.
+		return handler(ctx, req)
+	}
+	ctx = context.WithValue(ctx, callerContextKey{}, caller)
+
+	icr, _ := req.(*securityapi.IstioCertificateRequest)
+	retryAfter, scope, ok := s.reserveRateLimit(s.rateLimitIdentities(ctx, caller, icr), time.Now())
+	if !ok {
+		metricRateLimitedRequests.WithLabelValues(scope).Inc()
+		s.requestLogger(ctx).V(2).Info("rate limited certificate request", "identities", caller.Identities, "scope", scope, "retry-after", retryAfter)
+
+		record := newAuditRecord(icr, time.Now())
+		auditCaller(record, icr, caller)
+		record.Reason = fmt.Sprintf("%s rate limit exceeded", scope)
+		s.writeAuditRecord(record)
+
+		st, err := status.New(codes.ResourceExhausted, fmt.Sprintf("%s rate limit exceeded, retry after %s", scope, retryAfter)).
+			WithDetails(
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+				&errdetails.ErrorInfo{Reason: string(reasonRateLimited), Domain: errorDomain, Metadata: map[string]string{"scope": scope}},
+			)
+		if err != nil {
+			return nil, status.Errorf(codes.ResourceExhausted, "%s rate limit exceeded, retry after %s", scope, retryAfter)
+		}
+		return nil, st.Err()
+	}
+
+	return handler(ctx, req)
+}
+
+// rateLimitIdentities returns the identities which the request should be
+// rate limited by. Trusted node accounts are rate limited by the identity
+// they are impersonating, so that a single node proxy does not share a limit
+// with every workload on its node. The impersonation is validated first, so
+// that a node proxy cannot evade its limit by requesting arbitrary
+// identities.
+func (s *Server) rateLimitIdentities(ctx context.Context, caller *security.Caller, icr *securityapi.IstioCertificateRequest) []string {
+	impersonatedIdentity := icr.GetMetadata().GetFields()[security.ImpersonatedIdentity].GetStringValue()
+	if len(impersonatedIdentity) == 0 {
+		return caller.Identities
+	}
+
+	nodeAuthorizer := s.nodeAuthorizerFor(clusterIDFromContext(ctx))
+	if nodeAuthorizer == nil {
+		return caller.Identities
+	}
+
+	if _, err := nodeAuthorizer.authenticateImpersonation(ctx, caller.KubernetesInfo, impersonatedIdentity); err != nil {
+		// The request is rejected by the handler, but is still counted
+		// against the caller's own identity.
+		return caller.Identities
+	}
+
+	return []string{impersonatedIdentity}
+}
+
+// reserveRateLimit reserves a token for each identity, and each namespace of
+// those identities. If any limit is exceeded, all reservations are cancelled,
+// and the delay until the request may be retried and the scope of the
+// exceeded limit are returned.
+func (s *Server) reserveRateLimit(identities []string, now time.Time) (time.Duration, string, bool) {
+	var reservations []*rate.Reservation
+	reserve := func(limiter *ratelimit.Limiter, key string) time.Duration {
+		r := limiter.Reserve(key, now)
+		reservations = append(reservations, r)
+		return r.DelayFrom(now)
+	}
+
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+
+	namespaces := sets.New[string]()
+	for _, identity := range identities {
+		if s.rateLimiters.identity != nil {
+			if delay := reserve(s.rateLimiters.identity, identity); delay > 0 {
+				cancel()
+				return delay, rateLimitScopeIdentity, false
+			}
+		}
+
+		if id, err := spiffe.ParseIdentity(identity); err == nil {
+			namespaces.Insert(id.Namespace)
+		}
+	}
+
+	if s.rateLimiters.namespace != nil {
+		for namespace := range namespaces {
+			if delay := reserve(s.rateLimiters.namespace, namespace); delay > 0 {
+				cancel()
+				return delay, rateLimitScopeNamespace, false
+			}
+		}
+	}
+
+	return 0, "", true
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/security"
+	testUtil "istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/util/sets"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientfeatures "k8s.io/client-go/features"
+	clienttesting "k8s.io/client-go/features/testing"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestNewRateLimiters(t *testing.T) {
+	tests := map[string]struct {
+		opts       RateLimitOptions
+		expEnabled bool
+		expErr     bool
+	}{
+		"no qps should disable rate limiting": {
+			opts:       RateLimitOptions{IdentityBurst: 5, NamespaceBurst: 50},
+			expEnabled: false,
+		},
+		"identity qps should enable rate limiting": {
+			opts:       RateLimitOptions{IdentityQPS: 1, IdentityBurst: 5},
+			expEnabled: true,
+		},
+		"namespace qps should enable rate limiting": {
+			opts:       RateLimitOptions{NamespaceQPS: 1, NamespaceBurst: 5},
+			expEnabled: true,
+		},
+		"negative qps should error": {
+			opts:   RateLimitOptions{IdentityQPS: -1, IdentityBurst: 5},
+			expErr: true,
+		},
+		"a burst less than 1 should error": {
+			opts:   RateLimitOptions{NamespaceQPS: 1, NamespaceBurst: 0},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiters, err := newRateLimiters(test.opts)
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+			assert.Equal(t, test.expEnabled, limiters.enabled())
+		})
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	ztunnelCaller := security.KubernetesInfo{
+		PodName:           "ztunnel-a",
+		PodNamespace:      "istio-system",
+		PodUID:            "12345",
+		PodServiceAccount: "ztunnel",
+	}
+	pods := []pod{
+		{name: ztunnelCaller.PodName, namespace: ztunnelCaller.PodNamespace, account: ztunnelCaller.PodServiceAccount, uid: ztunnelCaller.PodUID, node: "zt-node"},
+		{name: "pod-a", namespace: "foo", account: "a", uid: "1", node: "zt-node"},
+		{name: "pod-b", namespace: "foo", account: "b", uid: "2", node: "zt-node"},
+	}
+
+	type request struct {
+		authn                security.Authenticator
+		impersonatedIdentity string
+		expCode              codes.Code
+	}
+
+	tests := map[string]struct {
+		opts     RateLimitOptions
+		requests []request
+	}{
+		"requests within the identity burst should be allowed": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 2},
+			requests: []request{
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.OK},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.OK},
+			},
+		},
+		"requests over the identity burst should be rejected": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1},
+			requests: []request{
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.OK},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.ResourceExhausted},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/b"}, ""), expCode: codes.OK},
+			},
+		},
+		"requests over the namespace burst should be rejected": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1, NamespaceQPS: 0.001, NamespaceBurst: 2},
+			requests: []request{
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.OK},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/b"}, ""), expCode: codes.OK},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/c"}, ""), expCode: codes.ResourceExhausted},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/bar/sa/a"}, ""), expCode: codes.OK},
+			},
+		},
+		"a request rejected by the namespace limit should not consume the identity limit": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1, NamespaceQPS: 0.001, NamespaceBurst: 1},
+			requests: []request{
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""), expCode: codes.OK},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/b"}, ""), expCode: codes.ResourceExhausted},
+				{authn: newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/b"}, ""), expCode: codes.ResourceExhausted},
+			},
+		},
+		"unauthenticated requests should not be rate limited by the interceptor": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1},
+			requests: []request{
+				{authn: newMockAuthn(nil, "not authenticated"), expCode: codes.OK},
+				{authn: newMockAuthn(nil, "not authenticated"), expCode: codes.OK},
+			},
+		},
+		"trusted node accounts should be rate limited by the impersonated identity": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1},
+			requests: []request{
+				{
+					authn:                newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}, &ztunnelCaller),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/a",
+					expCode:              codes.OK,
+				},
+				{
+					authn:                newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}, &ztunnelCaller),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/b",
+					expCode:              codes.OK,
+				},
+				{
+					authn:                newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}, &ztunnelCaller),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/b",
+					expCode:              codes.ResourceExhausted,
+				},
+			},
+		},
+		"trusted node accounts impersonating identities not on their node should be rate limited by their own identity": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1},
+			requests: []request{
+				{
+					authn:                newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}, &ztunnelCaller),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/c",
+					expCode:              codes.OK,
+				},
+				{
+					authn:                newMockAuthnImpersonate([]string{"spiffe://cluster.local/ns/istio-system/sa/ztunnel"}, &ztunnelCaller),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/d",
+					expCode:              codes.ResourceExhausted,
+				},
+			},
+		},
+		"untrusted callers should be rate limited by their own identity when impersonating": {
+			opts: RateLimitOptions{IdentityQPS: 0.001, IdentityBurst: 1},
+			requests: []request{
+				{
+					authn:                newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/b",
+					expCode:              codes.OK,
+				},
+				{
+					authn:                newMockAuthn([]string{"spiffe://cluster.local/ns/foo/sa/a"}, ""),
+					impersonatedIdentity: "spiffe://cluster.local/ns/foo/sa/c",
+					expCode:              codes.ResourceExhausted,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiters, err := newRateLimiters(test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var objects []runtime.Object
+			for _, p := range pods {
+				objects = append(objects, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: p.namespace, UID: types.UID(p.uid)},
+					Spec:       v1.PodSpec{ServiceAccountName: p.account, NodeName: p.node},
+				})
+			}
+			clienttesting.SetFeatureDuringTest(t, clientfeatures.WatchListClient, false)
+			c := kube.NewFakeClient(objects...)
+			na := NewClusterNodeAuthorizer(c, sets.New(types.NamespacedName{Namespace: "istio-system", Name: "ztunnel"}))
+			c.RunAndWait(testUtil.NewStop(t))
+			kube.WaitForCacheSync("test", testUtil.NewStop(t), na.pods.HasSynced)
+
+			s := &Server{
+				log:            ktesting.NewLogger(t, ktesting.DefaultConfig),
+				rateLimiters:   limiters,
+				nodeAuthorizer: na,
+			}
+
+			for i, req := range test.requests {
+				s.authenticators = []security.Authenticator{req.authn}
+
+				icr := &securityapi.IstioCertificateRequest{
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						security.ImpersonatedIdentity: structpb.NewStringValue(req.impersonatedIdentity),
+					}},
+				}
+
+				_, err := s.rateLimitInterceptor(t.Context(), icr, nil, func(ctx context.Context, _ any) (any, error) {
+					if caller, err := s.authenticate(ctx); err == nil {
+						assert.Equal(t, caller, ctx.Value(callerContextKey{}), "expected authenticated caller to be stored in context")
+					}
+					return nil, nil
+				})
+
+				st := status.Convert(err)
+				assert.Equal(t, req.expCode, st.Code(), "request %d: %v", i, err)
+
+				if req.expCode == codes.ResourceExhausted {
+					if assert.Len(t, st.Details(), 2, "request %d", i) {
+						retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+						if assert.True(t, ok, "request %d: expected RetryInfo details", i) {
+							assert.Positive(t, retryInfo.GetRetryDelay().AsDuration(), "request %d", i)
+						}
+
+						errorInfo, ok := st.Details()[1].(*errdetails.ErrorInfo)
+						if assert.True(t, ok, "request %d: expected ErrorInfo details", i) {
+							assert.Equal(t, string(reasonRateLimited), errorInfo.GetReason(), "request %d", i)
+						}
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/metadata"
+	"istio.io/istio/pkg/cluster"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/cert-manager/istio-csr/pkg/kubewatch"
+)
+
+const (
+	// multiClusterSecretLabel is the label of istio remote secrets, as
+	// created by istioctl create-remote-secret. Each key of a remote secret is
+	// the ID of a cluster, and its value a kubeconfig for that cluster.
+	multiClusterSecretLabel = "istio/multiCluster"
+
+	// clusterIDMetadataKey is the gRPC metadata key holding the ID of the
+	// cluster the istio agent is running in.
+	clusterIDMetadataKey = "clusterid"
+
+	// remoteClusterSyncTimeout is the maximum duration to wait for the pod
+	// informer of a remote cluster to sync, before giving up on the cluster.
+	remoteClusterSyncTimeout = time.Minute
+)
+
+var (
+	metricRemoteClusters = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "remote_clusters",
+			Help:      "Number of remote clusters whose workloads may request certificates.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricRemoteClusters)
+}
+
+// MultiClusterOptions configures authenticating workloads running in remote
+// clusters.
+type MultiClusterOptions struct {
+	// RemoteSecretNamespace is the namespace of istio remote secrets, which
+	// hold kubeconfigs for remote clusters. If empty, only workloads in the
+	// local cluster are authenticated.
+	RemoteSecretNamespace string
+
+	// InsecureKubeconfigOptions are kubeconfig options which remote secrets
+	// may use, and are otherwise rejected since they read files from, or run
+	// commands in, the istio-csr container. Options are "exec",
+	// "tokenFile", "clientCertificate", "clientKey", "certificateAuthority",
+	// or the name of an auth provider.
+	InsecureKubeconfigOptions []string
+}
+
+// remoteCluster is a remote cluster discovered from a remote secret.
+type remoteCluster struct {
+	client         kube.Client
+	nodeAuthorizer *ClusterNodeAuthorizer
+
+	secret     types.NamespacedName
+	kubeconfig []byte
+
+	// stop is closed when the cluster is removed, stopping its informers.
+	stop chan struct{}
+}
+
+// shutdown stops the informers of the cluster, and shuts down its client.
+func (c *remoteCluster) shutdown() {
+	close(c.stop)
+	c.client.Shutdown()
+}
+
+// clusterBuild is a remote cluster which is being built. Its stop channel is
+// closed if the build is superseded or removed before it completes.
+type clusterBuild struct {
+	secret     types.NamespacedName
+	kubeconfig []byte
+	stop       chan struct{}
+}
+
+// newRemoteClusterFunc builds the client and node authorizer of a remote
+// cluster from its kubeconfig. Any informers run until stop is closed.
+type newRemoteClusterFunc func(clusterID cluster.ID, kubeconfig []byte, stop <-chan struct{}) (*remoteCluster, error)
+
+// remoteClusters watches istio remote secrets, and holds the clients and node
+// authorizers of the remote clusters they configure. Tokens of workloads in
+// remote clusters are reviewed against their own cluster, chosen by the
+// cluster ID sent by the istio agent.
+type remoteClusters struct {
+	log            logr.Logger
+	namespace      string
+	localClusterID cluster.ID
+	client         client.WithWatch
+	newCluster     newRemoteClusterFunc
+
+	lock     sync.RWMutex
+	clusters map[cluster.ID]*remoteCluster
+	builds   map[cluster.ID]*clusterBuild
+
+	// building tracks running builds, so that shutdown may wait for them.
+	building sync.WaitGroup
+}
+
+func newRemoteClusters(log logr.Logger, k8sClient client.WithWatch, opts Options, newCluster newRemoteClusterFunc) *remoteClusters {
+	return &remoteClusters{
+		log:            log.WithName("remote-clusters").WithValues("namespace", opts.MultiCluster.RemoteSecretNamespace),
+		namespace:      opts.MultiCluster.RemoteSecretNamespace,
+		localClusterID: cluster.ID(opts.ClusterID),
+		client:         k8sClient,
+		newCluster:     newCluster,
+		clusters:       make(map[cluster.ID]*remoteCluster),
+		builds:         make(map[cluster.ID]*clusterBuild),
+	}
+}
+
+// newRemoteClusterBuilder returns a newRemoteClusterFunc which builds istio
+// kube clients, and node authorizers if any node accounts are trusted.
+// Kubeconfigs using options which are not in insecureOptions are rejected.
+func newRemoteClusterBuilder(trustedNodeAccounts sets.Set[types.NamespacedName], insecureOptions sets.Set[string]) newRemoteClusterFunc {
+	return func(clusterID cluster.ID, kubeconfig []byte, stop <-chan struct{}) (*remoteCluster, error) {
+		config, err := clientcmd.Load(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+		}
+
+		if err := sanitizeKubeconfig(config, insecureOptions); err != nil {
+			return nil, fmt.Errorf("kubeconfig is not allowed: %w", err)
+		}
+
+		kubeClient, err := kube.NewClient(clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}), clusterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed creating kube client: %w", err)
+		}
+
+		remote := &remoteCluster{client: kubeClient}
+		if trustedNodeAccounts.Len() > 0 {
+			remote.nodeAuthorizer, err = startClusterNodeAuthorizer(kubeClient, trustedNodeAccounts, stop, remoteClusterSyncTimeout)
+			if err != nil {
+				kubeClient.Shutdown()
+				return nil, fmt.Errorf("failed to start node authorizer: %w", err)
+			}
+		}
+
+		return remote, nil
+	}
+}
+
+// sanitizeKubeconfig returns an error if the kubeconfig uses options which
+// read files from, or run commands in, the istio-csr container, unless they
+// are in insecureOptions. Anyone able to create a remote secret could
+// otherwise run arbitrary commands in istio-csr. This follows istio's
+// secret controller.
+func sanitizeKubeconfig(config *clientcmdapi.Config, insecureOptions sets.Set[string]) error {
+	for name, authInfo := range config.AuthInfos {
+		if authInfo.AuthProvider != nil && !insecureOptions.Contains(authInfo.AuthProvider.Name) {
+			return fmt.Errorf("user %q: auth provider %q is not allowed", name, authInfo.AuthProvider.Name)
+		}
+		if authInfo.Exec != nil && !insecureOptions.Contains("exec") {
+			return fmt.Errorf("user %q: exec is not allowed", name)
+		}
+		if len(authInfo.TokenFile) > 0 && !insecureOptions.Contains("tokenFile") {
+			return fmt.Errorf("user %q: tokenFile is not allowed", name)
+		}
+		if len(authInfo.ClientCertificate) > 0 && !insecureOptions.Contains("clientCertificate") {
+			return fmt.Errorf("user %q: client-certificate is not allowed", name)
+		}
+		if len(authInfo.ClientKey) > 0 && !insecureOptions.Contains("clientKey") {
+			return fmt.Errorf("user %q: client-key is not allowed", name)
+		}
+	}
+
+	for name, cluster := range config.Clusters {
+		if len(cluster.CertificateAuthority) > 0 && !insecureOptions.Contains("certificateAuthority") {
+			return fmt.Errorf("cluster %q: certificate-authority is not allowed", name)
+		}
+	}
+
+	return nil
+}
+
+// kubeClient returns the Kubernetes client of the remote cluster, or nil if
+// the cluster is unknown. It is used by the Kubernetes JWT authenticator to
+// review tokens sent from remote clusters.
+func (r *remoteClusters) kubeClient(clusterID cluster.ID) kubernetes.Interface {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if remote, ok := r.clusters[clusterID]; ok {
+		return remote.client.Kube()
+	}
+	return nil
+}
+
+// nodeAuthorizer returns the node authorizer of the remote cluster, or nil if
+// the cluster is unknown or impersonation is not enabled.
+func (r *remoteClusters) nodeAuthorizer(clusterID cluster.ID) *ClusterNodeAuthorizer {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if remote, ok := r.clusters[clusterID]; ok {
+		return remote.nodeAuthorizer
+	}
+	return nil
+}
+
+// Start watches remote secrets until the context is cancelled, after which
+// all remote cluster clients are shut down.
+func (r *remoteClusters) Start(ctx context.Context) {
+	defer r.shutdown()
+
+	kubewatch.Run(ctx, r.log, "remote secrets", func(ctx context.Context) (watch.Interface, error) {
+		return r.client.Watch(ctx, &corev1.SecretList{}, &client.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{multiClusterSecretLabel: "true"}),
+			Namespace:     r.namespace,
+		})
+	}, func(event watch.Event) {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			if secret, ok := event.Object.(*corev1.Secret); ok {
+				r.updateSecret(secret)
+			}
+
+		case watch.Deleted:
+			if secret, ok := event.Object.(*corev1.Secret); ok {
+				r.deleteSecret(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name})
+			}
+
+		case watch.Error:
+			r.log.Error(apierrors.FromObject(event.Object), "Got an error event when watching remote secrets")
+		}
+	})
+}
+
+// updateSecret adds, updates or removes the remote clusters of the secret.
+func (r *remoteClusters) updateSecret(secret *corev1.Secret) {
+	key := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}
+	log := r.log.WithValues("secret", key.String())
+
+	clusterIDs := make([]string, 0, len(secret.Data))
+	for clusterID := range secret.Data {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+
+	seen := make(map[cluster.ID]struct{}, len(clusterIDs))
+	for _, id := range clusterIDs {
+		clusterID := cluster.ID(id)
+		kubeconfig := secret.Data[id]
+		log := log.WithValues("cluster-id", clusterID)
+
+		if clusterID == r.localClusterID {
+			log.Info("Ignoring remote secret entry for the local cluster")
+			continue
+		}
+		seen[clusterID] = struct{}{}
+
+		r.lock.Lock()
+		build, building := r.builds[clusterID]
+		existing, exists := r.clusters[clusterID]
+		switch {
+		case building && build.secret != key:
+			log.Error(nil, "Ignoring remote cluster already configured by another secret", "existing-secret", build.secret.String())
+			r.lock.Unlock()
+			continue
+		case !building && exists && existing.secret != key:
+			log.Error(nil, "Ignoring remote cluster already configured by another secret", "existing-secret", existing.secret.String())
+			r.lock.Unlock()
+			continue
+		case building && bytes.Equal(build.kubeconfig, kubeconfig),
+			!building && exists && bytes.Equal(existing.kubeconfig, kubeconfig):
+			r.lock.Unlock()
+			continue
+		}
+
+		// Supersede any build of a previous kubeconfig.
+		if building {
+			close(build.stop)
+		}
+		build = &clusterBuild{secret: key, kubeconfig: kubeconfig, stop: make(chan struct{})}
+		r.builds[clusterID] = build
+		r.building.Add(1)
+		r.lock.Unlock()
+
+		// Building a cluster may block while its node informer syncs, so is
+		// done in the background so that other secrets are not blocked by an
+		// unreachable cluster.
+		go r.buildCluster(log, clusterID, build)
+	}
+
+	r.removeClusters(key, func(clusterID cluster.ID) bool {
+		_, ok := seen[clusterID]
+		return !ok
+	})
+}
+
+// buildCluster builds the remote cluster, and adds it in place of any
+// existing cluster with the same ID. The cluster is discarded if the build
+// has been superseded or removed in the meantime.
+func (r *remoteClusters) buildCluster(log logr.Logger, clusterID cluster.ID, build *clusterBuild) {
+	defer r.building.Done()
+
+	remote, err := r.newCluster(clusterID, build.kubeconfig, build.stop)
+
+	r.lock.Lock()
+	if r.builds[clusterID] != build {
+		r.lock.Unlock()
+		if err == nil {
+			remote.client.Shutdown()
+		}
+		return
+	}
+	delete(r.builds, clusterID)
+
+	if err != nil {
+		r.lock.Unlock()
+		close(build.stop)
+		log.Error(err, "Failed to build remote cluster client")
+		return
+	}
+
+	remote.secret = build.secret
+	remote.kubeconfig = build.kubeconfig
+	remote.stop = build.stop
+
+	existing := r.clusters[clusterID]
+	r.clusters[clusterID] = remote
+	metricRemoteClusters.Set(float64(len(r.clusters)))
+	r.lock.Unlock()
+
+	if existing != nil {
+		existing.shutdown()
+		log.Info("Updated remote cluster")
+	} else {
+		log.Info("Added remote cluster")
+	}
+}
+
+// deleteSecret removes all remote clusters of the secret.
+func (r *remoteClusters) deleteSecret(key types.NamespacedName) {
+	r.removeClusters(key, func(cluster.ID) bool { return true })
+}
+
+// removeClusters removes the remote clusters of the secret which match
+// remove, shutting down their clients and stopping any builds.
+func (r *remoteClusters) removeClusters(key types.NamespacedName, remove func(cluster.ID) bool) {
+	r.lock.Lock()
+	for clusterID, build := range r.builds {
+		if build.secret == key && remove(clusterID) {
+			delete(r.builds, clusterID)
+			close(build.stop)
+		}
+	}
+
+	var removed []*remoteCluster
+	for clusterID, remote := range r.clusters {
+		if remote.secret == key && remove(clusterID) {
+			delete(r.clusters, clusterID)
+			removed = append(removed, remote)
+			r.log.Info("Removed remote cluster", "secret", key.String(), "cluster-id", clusterID)
+		}
+	}
+	metricRemoteClusters.Set(float64(len(r.clusters)))
+	r.lock.Unlock()
+
+	for _, remote := range removed {
+		remote.shutdown()
+	}
+}
+
+// shutdown removes all remote clusters, shutting down their clients, and
+// waits for any builds to stop.
+func (r *remoteClusters) shutdown() {
+	r.lock.Lock()
+	clusters := r.clusters
+	r.clusters = make(map[cluster.ID]*remoteCluster)
+	for _, build := range r.builds {
+		close(build.stop)
+	}
+	r.builds = make(map[cluster.ID]*clusterBuild)
+	metricRemoteClusters.Set(0)
+	r.lock.Unlock()
+
+	for _, remote := range clusters {
+		remote.shutdown()
+	}
+
+	r.building.Wait()
+}
+
+// clusterIDFromContext returns the ID of the cluster the istio agent is
+// running in, as sent in the gRPC metadata. Returns an empty string if not
+// sent.
+func clusterIDFromContext(ctx context.Context) cluster.ID {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if ids := md.Get(clusterIDMetadataKey); len(ids) > 0 {
+		return cluster.ID(ids[0])
+	}
+	return ""
+}
+
+// nodeAuthorizerFor returns the node authorizer of the cluster the request
+// was sent from. Returns nil if impersonation is not enabled for the cluster.
+func (s *Server) nodeAuthorizerFor(clusterID cluster.ID) *ClusterNodeAuthorizer {
+	if len(clusterID) == 0 || clusterID == cluster.ID(s.opts.ClusterID) || s.remoteClusters == nil {
+		return s.nodeAuthorizer
+	}
+	return s.remoteClusters.nodeAuthorizer(clusterID)
+}
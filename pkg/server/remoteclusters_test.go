@@ -0,0 +1,432 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc/metadata"
+	"istio.io/istio/pkg/cluster"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeKubeClient struct {
+	kube.Client
+
+	clusterID cluster.ID
+	clientset kubernetes.Interface
+	stop      <-chan struct{}
+	shutdown  atomic.Bool
+}
+
+func (f *fakeKubeClient) Kube() kubernetes.Interface { return f.clientset }
+func (f *fakeKubeClient) RESTConfig() *rest.Config   { return nil }
+func (f *fakeKubeClient) ClusterID() cluster.ID      { return f.clusterID }
+func (f *fakeKubeClient) Shutdown()                  { f.shutdown.Store(true) }
+
+// stopped returns true if the informers of the client have been stopped.
+func (f *fakeKubeClient) stopped() bool {
+	select {
+	case <-f.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func remoteSecret(name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "istio-system",
+			Name:      name,
+			Labels:    map[string]string{multiClusterSecretLabel: "true"},
+		},
+		Data: make(map[string][]byte),
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+// builtClients records the clients built for each remote cluster.
+type builtClients struct {
+	lock    sync.Mutex
+	clients map[cluster.ID][]*fakeKubeClient
+}
+
+func (b *builtClients) get(clusterID cluster.ID) []*fakeKubeClient {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.clients[clusterID]
+}
+
+// newTestRemoteClusters returns remoteClusters building fake clients. The
+// kubeconfig "invalid" fails to build, and "unreachable" blocks until the
+// build is stopped.
+func newTestRemoteClusters(t *testing.T) (*remoteClusters, *builtClients) {
+	t.Helper()
+
+	built := &builtClients{clients: make(map[cluster.ID][]*fakeKubeClient)}
+	newCluster := func(clusterID cluster.ID, kubeconfig []byte, stop <-chan struct{}) (*remoteCluster, error) {
+		switch string(kubeconfig) {
+		case "invalid":
+			return nil, errors.New("invalid kubeconfig")
+		case "unreachable":
+			<-stop
+			return nil, errors.New("stopped")
+		}
+		client := &fakeKubeClient{clusterID: clusterID, clientset: kubefake.NewClientset(), stop: stop}
+		built.lock.Lock()
+		built.clients[clusterID] = append(built.clients[clusterID], client)
+		built.lock.Unlock()
+		return &remoteCluster{client: client, nodeAuthorizer: &ClusterNodeAuthorizer{}}, nil
+	}
+
+	opts := Options{ClusterID: "local", MultiCluster: MultiClusterOptions{RemoteSecretNamespace: "istio-system"}}
+	return newRemoteClusters(logr.Discard(), fake.NewClientBuilder().Build(), opts, newCluster), built
+}
+
+// updateSecretAndWait updates the secret, and waits for its clusters to be
+// built.
+func updateSecretAndWait(r *remoteClusters, secret *corev1.Secret) {
+	r.updateSecret(secret)
+	r.building.Wait()
+}
+
+// waitFor polls until cond returns true.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for range 100 {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
+
+func Test_remoteClusters(t *testing.T) {
+	r, built := newTestRemoteClusters(t)
+
+	// The local cluster and invalid kubeconfigs should be ignored.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", map[string]string{
+		"local":     "kubeconfig-local",
+		"cluster-a": "kubeconfig-a",
+		"cluster-b": "invalid",
+	}))
+	if r.kubeClient("local") != nil {
+		t.Error("expected the local cluster to be ignored")
+	}
+	if r.kubeClient("cluster-b") != nil {
+		t.Error("expected cluster with invalid kubeconfig to be ignored")
+	}
+	if r.kubeClient("cluster-a") == nil || r.nodeAuthorizer("cluster-a") == nil {
+		t.Fatal("expected cluster-a to be added")
+	}
+
+	// An unchanged kubeconfig should not rebuild the cluster.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "kubeconfig-a"}))
+	if len(built.get("cluster-a")) != 1 {
+		t.Errorf("expected cluster-a to be built once, got %d", len(built.get("cluster-a")))
+	}
+
+	// A changed kubeconfig should rebuild the cluster, shutting down the old
+	// client.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "kubeconfig-a-rotated"}))
+	if len(built.get("cluster-a")) != 2 {
+		t.Fatalf("expected cluster-a to be rebuilt, got %d", len(built.get("cluster-a")))
+	}
+	if old := built.get("cluster-a")[0]; !old.shutdown.Load() || !old.stopped() {
+		t.Error("expected old cluster-a client to be shut down and its informers stopped")
+	}
+	if r.kubeClient("cluster-a") != built.get("cluster-a")[1].clientset {
+		t.Error("expected the rebuilt cluster-a client to be used")
+	}
+
+	// A cluster configured by another secret should be ignored.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-b", map[string]string{
+		"cluster-a": "kubeconfig-a-other",
+		"cluster-c": "kubeconfig-c",
+	}))
+	if len(built.get("cluster-a")) != 2 {
+		t.Errorf("expected cluster-a configured by another secret to be ignored")
+	}
+	if r.kubeClient("cluster-c") == nil {
+		t.Error("expected cluster-c to be added")
+	}
+
+	// Removing a cluster from a secret should remove it.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", nil))
+	if r.kubeClient("cluster-a") != nil {
+		t.Error("expected cluster-a to be removed")
+	}
+	if removed := built.get("cluster-a")[1]; !removed.shutdown.Load() || !removed.stopped() {
+		t.Error("expected removed cluster-a client to be shut down and its informers stopped")
+	}
+
+	// Deleting a secret should remove its clusters only.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "kubeconfig-a"}))
+	r.deleteSecret(types.NamespacedName{Namespace: "istio-system", Name: "istio-remote-secret-b"})
+	if r.kubeClient("cluster-c") != nil {
+		t.Error("expected cluster-c to be removed")
+	}
+	if r.kubeClient("cluster-a") == nil {
+		t.Error("expected cluster-a to remain")
+	}
+}
+
+func Test_remoteClustersWatch(t *testing.T) {
+	r, built := newTestRemoteClusters(t)
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan struct{})
+	go func() {
+		r.Start(ctx)
+		close(done)
+	}()
+
+	// Wait for the watch to be established before creating the secret.
+	time.Sleep(100 * time.Millisecond)
+
+	secret := remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "kubeconfig-a"})
+	if err := r.client.Create(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.kubeClient("cluster-a") != nil })
+
+	if err := r.client.Delete(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.kubeClient("cluster-a") == nil })
+
+	if err := r.client.Create(ctx, remoteSecret("istio-remote-secret-b", map[string]string{"cluster-b": "kubeconfig-b"})); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.kubeClient("cluster-b") != nil })
+
+	cancel()
+	<-done
+	if !built.get("cluster-b")[0].shutdown.Load() {
+		t.Error("expected clients to be shut down when stopped")
+	}
+}
+
+func Test_remoteClustersUnreachable(t *testing.T) {
+	r, built := newTestRemoteClusters(t)
+
+	// An unreachable cluster should not block other secrets.
+	r.updateSecret(remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "unreachable"}))
+	r.updateSecret(remoteSecret("istio-remote-secret-b", map[string]string{"cluster-b": "kubeconfig-b"}))
+	waitFor(t, func() bool { return r.kubeClient("cluster-b") != nil })
+
+	// Updating the secret of the unreachable cluster should stop its build.
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret-a", map[string]string{"cluster-a": "kubeconfig-a"}))
+	if r.kubeClient("cluster-a") == nil {
+		t.Error("expected cluster-a to be added")
+	}
+
+	// Deleting a secret while its cluster is being built should stop the
+	// build, and not add the cluster.
+	r.updateSecret(remoteSecret("istio-remote-secret-c", map[string]string{"cluster-c": "unreachable"}))
+	r.deleteSecret(types.NamespacedName{Namespace: "istio-system", Name: "istio-remote-secret-c"})
+	r.building.Wait()
+	if r.kubeClient("cluster-c") != nil {
+		t.Error("expected cluster-c to not be added")
+	}
+
+	// Shutting down should stop builds and the informers of all clusters.
+	r.updateSecret(remoteSecret("istio-remote-secret-c", map[string]string{"cluster-c": "unreachable"}))
+	r.shutdown()
+	for _, clusterID := range []cluster.ID{"cluster-a", "cluster-b"} {
+		if client := built.get(clusterID)[0]; !client.stopped() {
+			t.Errorf("expected %s informers to be stopped", clusterID)
+		}
+	}
+}
+
+func Test_sanitizeKubeconfig(t *testing.T) {
+	tests := map[string]struct {
+		authInfo        *clientcmdapi.AuthInfo
+		cluster         *clientcmdapi.Cluster
+		insecureOptions []string
+		expErr          bool
+	}{
+		"embedded credentials should be allowed": {
+			authInfo: &clientcmdapi.AuthInfo{Token: "token", ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")},
+			cluster:  &clientcmdapi.Cluster{Server: "https://remote", CertificateAuthorityData: []byte("ca")},
+		},
+		"exec should be rejected": {
+			authInfo: &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "sh"}},
+			expErr:   true,
+		},
+		"exec should be allowed if an insecure option": {
+			authInfo:        &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "sh"}},
+			insecureOptions: []string{"exec"},
+		},
+		"auth provider should be rejected": {
+			authInfo: &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "gcp"}},
+			expErr:   true,
+		},
+		"auth provider should be allowed if an insecure option": {
+			authInfo:        &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "oidc"}},
+			insecureOptions: []string{"oidc"},
+		},
+		"token file should be rejected": {
+			authInfo: &clientcmdapi.AuthInfo{TokenFile: "/var/run/secrets/token"},
+			expErr:   true,
+		},
+		"client certificate file should be rejected": {
+			authInfo: &clientcmdapi.AuthInfo{ClientCertificate: "/etc/cert.pem"},
+			expErr:   true,
+		},
+		"client key file should be rejected": {
+			authInfo: &clientcmdapi.AuthInfo{ClientKey: "/etc/key.pem"},
+			expErr:   true,
+		},
+		"certificate authority file should be rejected": {
+			cluster: &clientcmdapi.Cluster{Server: "https://remote", CertificateAuthority: "/etc/ca.pem"},
+			expErr:  true,
+		},
+		"certificate authority file should be allowed if an insecure option": {
+			cluster:         &clientcmdapi.Cluster{Server: "https://remote", CertificateAuthority: "/etc/ca.pem"},
+			insecureOptions: []string{"certificateAuthority"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := clientcmdapi.NewConfig()
+			if test.authInfo != nil {
+				config.AuthInfos["remote"] = test.authInfo
+			}
+			if test.cluster != nil {
+				config.Clusters["remote"] = test.cluster
+			}
+
+			err := sanitizeKubeconfig(config, sets.New(test.insecureOptions...))
+			if (err != nil) != test.expErr {
+				t.Errorf("unexpected error, exp=%t got=%v", test.expErr, err)
+			}
+		})
+	}
+}
+
+func Test_newRemoteClusterBuilderRejectsExec(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+users:
+- name: remote
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: sh
+      args: ["-c", "touch /tmp/pwned"]
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+`)
+
+	build := newRemoteClusterBuilder(sets.New[types.NamespacedName](), sets.New[string]())
+	if _, err := build("cluster-a", kubeconfig, make(chan struct{})); err == nil || !strings.Contains(err.Error(), "exec is not allowed") {
+		t.Errorf("expected kubeconfig with exec plugin to be rejected, got=%v", err)
+	}
+}
+
+func Test_nodeAuthorizerFor(t *testing.T) {
+	local := &ClusterNodeAuthorizer{trustedNodeAccounts: sets.New[types.NamespacedName]()}
+	r, _ := newTestRemoteClusters(t)
+	updateSecretAndWait(r, remoteSecret("istio-remote-secret", map[string]string{"cluster-a": "kubeconfig-a"}))
+
+	tests := map[string]struct {
+		remotes   *remoteClusters
+		ctx       context.Context
+		expRemote bool
+		expNil    bool
+	}{
+		"if no cluster ID is sent, should use the local node authorizer": {
+			remotes: r,
+			ctx:     context.Background(),
+		},
+		"if the local cluster ID is sent, should use the local node authorizer": {
+			remotes: r,
+			ctx:     metadata.NewIncomingContext(context.Background(), metadata.Pairs("ClusterID", "local")),
+		},
+		"if multi-cluster is not enabled, should use the local node authorizer": {
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("ClusterID", "cluster-a")),
+		},
+		"if a remote cluster ID is sent, should use the remote node authorizer": {
+			remotes:   r,
+			ctx:       metadata.NewIncomingContext(context.Background(), metadata.Pairs("ClusterID", "cluster-a")),
+			expRemote: true,
+		},
+		"if an unknown cluster ID is sent, should return nil": {
+			remotes: r,
+			ctx:     metadata.NewIncomingContext(context.Background(), metadata.Pairs("ClusterID", "cluster-z")),
+			expNil:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				opts:           Options{ClusterID: "local"},
+				nodeAuthorizer: local,
+				remoteClusters: test.remotes,
+			}
+
+			got := s.nodeAuthorizerFor(clusterIDFromContext(test.ctx))
+			switch {
+			case test.expNil:
+				if got != nil {
+					t.Errorf("expected no node authorizer, got %v", got)
+				}
+			case test.expRemote:
+				if got == nil || got == local {
+					t.Errorf("expected remote node authorizer, got %v", got)
+				}
+			default:
+				if got != local {
+					t.Errorf("expected local node authorizer, got %v", got)
+				}
+			}
+		})
+	}
+}
@@ -21,20 +21,22 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
-	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
 	"github.com/go-logr/logr"
 	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/status"
 	securityapi "istio.io/api/security/v1alpha1"
 	"istio.io/istio/pkg/cluster"
 	"istio.io/istio/pkg/config/mesh"
@@ -46,12 +48,21 @@ import (
 	"istio.io/istio/security/pkg/server/ca/authenticate/kubeauth"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/cert-manager/istio-csr/pkg/certmanager"
+	"github.com/cert-manager/istio-csr/pkg/server/audit"
+	"github.com/cert-manager/istio-csr/pkg/server/authz"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/policy"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/routing"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/sanpolicy"
 	"github.com/cert-manager/istio-csr/pkg/tls"
+	"github.com/cert-manager/istio-csr/pkg/tracing"
 )
 
+var tracer = otel.Tracer("github.com/cert-manager/istio-csr/pkg/server")
+
 type Options struct {
 	// ClusterID is the ID of the cluster to verify requests to.
 	ClusterID string
@@ -64,15 +75,101 @@ type Options struct {
 	// this value, this value will be used instead.
 	MaximumClientCertificateDuration time.Duration
 
+	// NamespaceDurationAnnotations enables reading certificate duration limits
+	// from the annotations of the namespaces of requesting workloads. The
+	// lowest of the global and namespace maximums is applied.
+	NamespaceDurationAnnotations bool
+
 	// Authenticators configures authenticators to use for incoming CSR requests.
 	Authenticators AuthenticatorOptions
 
 	CATrustedNodeAccounts []string
+
+	// KeyPolicy configures which public keys workload CSRs may use.
+	KeyPolicy KeyPolicyOptions
+
+	// ExternalAuthorization configures an optional policy service which must
+	// approve each authenticated request before it is signed.
+	ExternalAuthorization authz.Options
+
+	// ExtKeyUsagePolicy constrains the extended key usages granted to
+	// workloads, which are otherwise those requested by the CSR.
+	ExtKeyUsagePolicy ExtKeyUsagePolicyOptions
+
+	// Denylist configures the identity denylist, consulted before signing.
+	Denylist DenylistOptions
+
+	// MultiCluster configures authenticating workloads running in remote
+	// clusters.
+	MultiCluster MultiClusterOptions
+
+	// IssuedTrustDomainPolicy is the policy on which trust domain the SPIFFE
+	// IDs of issued certificates must use. Defaults to
+	// TrustDomainPolicyRequested.
+	IssuedTrustDomainPolicy TrustDomainPolicy
+
+	// AdmissionPolicyFile is an optional file path to a list of CEL admission
+	// policies. Every authorized request must be admitted by all policies to
+	// be signed. The file is reloaded when changed.
+	AdmissionPolicyFile string
+
+	// SANPolicyFile is an optional file path to a SAN policy, which allows
+	// the workloads of specific service accounts to request DNS and IP SANs.
+	// If empty, workload CSRs may only contain URI SANs.
+	SANPolicyFile string
+
+	// IssuerRoutingPolicyFile is an optional file path to an issuer routing
+	// policy. If set, workloads matching a rule of the policy are signed by
+	// that rule's issuer, rather than the default issuer.
+	IssuerRoutingPolicyFile string
+
+	// CertSignerIssuers maps the names of CertSigners, which may be requested
+	// by istio agents in the CertSigner request metadata, to the issuer that
+	// signs their certificates. Issuers are in the form
+	// "<kind>.<group>/<name>". If empty, the CertSigner metadata is ignored.
+	CertSignerIssuers map[string]string
+
+	// RateLimit configures rate limiting of incoming CSR requests.
+	RateLimit RateLimitOptions
+
+	// MaxInFlightSigningRequests is the maximum number of certificate requests
+	// which are signed concurrently. 0 means unlimited.
+	MaxInFlightSigningRequests int
+
+	// MaxQueuedSigningRequests is the maximum number of certificate requests
+	// which wait for a signing slot when MaxInFlightSigningRequests is
+	// reached. Requests exceeding the queue are rejected with Unavailable.
+	MaxQueuedSigningRequests int
+
+	// VerboseErrorDetails returns the underlying cause of rejected requests to
+	// clients, in both the status message and ErrorInfo details. The cause may
+	// contain sensitive information, such as token validation errors.
+	VerboseErrorDetails bool
+
+	// AuditLogPath is an optional file path to which an audit record of every
+	// certificate request is appended as a JSON line. If "-", records are
+	// written to stdout.
+	AuditLogPath string
 }
 
 type AuthenticatorOptions struct {
 	// EnableClientCert enables the client certificate authenticator when true.
 	EnableClientCert bool
+
+	// Audiences are the audiences accepted for Kubernetes service account
+	// tokens. Tokens must have been minted for at least one of these
+	// audiences. If empty, the default audiences of the authenticator are
+	// used.
+	Audiences []string
+
+	// CacheTTL is the maximum duration the result of a successful Kubernetes
+	// token authentication is cached for. Entries never outlive the expiry
+	// of the token. A value of 0 disables the cache.
+	CacheTTL time.Duration
+
+	// CacheSize is the maximum number of authenticated tokens held in the
+	// cache.
+	CacheSize int
 }
 
 // Server is the implementation of the istio CreateCertificate service
@@ -83,6 +180,10 @@ type Server struct {
 	log  logr.Logger
 
 	authenticators []security.Authenticator
+	meshWatcher    meshwatcher.WatcherCollection
+
+	trustDomain        string
+	trustDomainAliases []string
 
 	cm  certmanager.Signer
 	tls tls.Interface
@@ -90,57 +191,227 @@ type Server struct {
 	ready bool
 	lock  sync.RWMutex
 
+	keyPolicy      *keyPolicy
 	nodeAuthorizer *ClusterNodeAuthorizer
+	remoteClusters *remoteClusters
+	denylist       *denylist
+	authorizer     authz.Authorizer
+
+	namespaceDurations *namespaceDurations
+
+	admissionPolicy *policy.Engine
+	sanPolicy       *sanpolicy.Policy
+
+	routingPolicy *routing.Policy
+	certSigners   map[string]cmmeta.IssuerReference
+	rateLimiters  rateLimiters
+	signLimiter   *signLimiter
+	auditSink     audit.Sink
 }
 
-func New(log logr.Logger, restConfig *rest.Config, cm certmanager.Signer, tls tls.Interface, opts Options) (*Server, error) {
+// New constructs a new gRPC server. meshWatcher holds the live MeshConfig
+// used to authenticate requests; if nil, the default MeshConfig is used.
+func New(log logr.Logger, restConfig *rest.Config, cm certmanager.Signer, tls tls.Interface, meshWatcher meshwatcher.WatcherCollection, opts Options) (*Server, error) {
 	client, err := kube.NewClient(kube.NewClientConfigForRestConfig(restConfig), cluster.ID(opts.ClusterID))
 	if err != nil {
 		return nil, fmt.Errorf("failed creating kube client: %v", err)
 	}
 
-	meshcnf := mesh.DefaultMeshConfig()
-	meshcnf.TrustDomain = tls.TrustDomain()
+	if meshWatcher == nil {
+		meshcnf := mesh.DefaultMeshConfig()
+		meshcnf.TrustDomain = tls.TrustDomain()
+		meshcnf.TrustDomainAliases = tls.TrustDomainAliases()
+		meshWatcher = meshwatcher.NewTestWatcher(meshcnf)
+	}
 
-	var authenticators []security.Authenticator
-	if opts.Authenticators.EnableClientCert {
-		authenticators = append(authenticators, &authenticate.ClientCertAuthenticator{})
+	trustedNodeAccounts := sets.New[types.NamespacedName]()
+	for _, v := range opts.CATrustedNodeAccounts {
+		ns, sa, valid := strings.Cut(v, "/")
+		if !valid {
+			log.Info("Invalid CA_TRUSTED_NODE_ACCOUNTS, ignoring", "account", v)
+			continue
+		}
+		trustedNodeAccounts.Insert(types.NamespacedName{
+			Namespace: ns,
+			Name:      sa,
+		})
+	}
+
+	// Tokens sent from remote clusters are reviewed against the remote
+	// cluster, discovered from istio remote secrets.
+	var remotes *remoteClusters
+	var remoteKubeClientGetter kubeauth.RemoteKubeClientGetter
+	if len(opts.MultiCluster.RemoteSecretNamespace) > 0 {
+		k8sClient, err := ctrlclient.NewWithWatch(restConfig, ctrlclient.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+		}
+		remotes = newRemoteClusters(log, k8sClient, opts, newRemoteClusterBuilder(trustedNodeAccounts, sets.New(opts.MultiCluster.InsecureKubeconfigOptions...)))
+		remoteKubeClientGetter = remotes.kubeClient
+	}
+
+	var identityDenylist *denylist
+	switch {
+	case len(opts.Denylist.File) > 0 && len(opts.Denylist.ConfigMapName) > 0:
+		return nil, errors.New("only one of a denylist file or ConfigMap may be configured")
+
+	case len(opts.Denylist.File) > 0:
+		identityDenylist, err = newDenylist(log, nil, opts.Denylist)
+		if err != nil {
+			return nil, err
+		}
+
+	case len(opts.Denylist.ConfigMapName) > 0:
+		if len(opts.Denylist.ConfigMapNamespace) == 0 {
+			return nil, errors.New("the namespace of the denylist ConfigMap must be configured")
+		}
+		k8sClient, err := ctrlclient.NewWithWatch(restConfig, ctrlclient.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+		}
+		identityDenylist, err = newDenylist(log, k8sClient, opts.Denylist)
+		if err != nil {
+			return nil, err
+		}
 	}
-	authenticators = append(authenticators, kubeauth.NewKubeJWTAuthenticator(
-		meshwatcher.NewTestWatcher(meshcnf),
+
+	authenticators := newAuthenticators(kubeauth.NewKubeJWTAuthenticator(
+		meshWatcher,
 		client.Kube(),
 		cluster.ID(opts.ClusterID),
+		remoteKubeClientGetter,
 		nil,
-		nil,
-	))
+	), opts.Authenticators)
 
 	var nodeAuthorizer *ClusterNodeAuthorizer
-	if len(opts.CATrustedNodeAccounts) > 0 {
-		trustedNodeAccounts := sets.New[types.NamespacedName]()
-		for _, v := range opts.CATrustedNodeAccounts {
-			ns, sa, valid := strings.Cut(v, "/")
-			if !valid {
-				log.Info("Invalid CA_TRUSTED_NODE_ACCOUNTS, ignoring", "account", v)
-				continue
-			}
-			trustedNodeAccounts.Insert(types.NamespacedName{
-				Namespace: ns,
-				Name:      sa,
-			})
-		}
+	if trustedNodeAccounts.Len() > 0 {
 		nodeAuthorizer = NewClusterNodeAuthorizer(client, trustedNodeAccounts)
 	}
 
+	switch opts.IssuedTrustDomainPolicy {
+	case "", TrustDomainPolicyRequested, TrustDomainPolicyTrustDomain:
+	default:
+		return nil, fmt.Errorf("unknown issued trust domain policy %q, must be one of %q or %q",
+			opts.IssuedTrustDomainPolicy, TrustDomainPolicyRequested, TrustDomainPolicyTrustDomain)
+	}
+
+	var nsDurations *namespaceDurations
+	if opts.NamespaceDurationAnnotations {
+		nsDurations = newNamespaceDurations(client.Kube())
+		log.Info("reading certificate duration limits from namespace annotations")
+	}
+
+	keyPolicy, err := newKeyPolicy(opts.KeyPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.ExtKeyUsagePolicy.validate(); err != nil {
+		return nil, err
+	}
+
+	authorizer, err := authz.New(opts.ExternalAuthorization)
+	if err != nil {
+		return nil, err
+	}
+	if authorizer != nil {
+		log.Info("using external authorization", "endpoint", opts.ExternalAuthorization.Endpoint, "fail-open", opts.ExternalAuthorization.FailOpen)
+	}
+
+	var admissionPolicy *policy.Engine
+	if len(opts.AdmissionPolicyFile) > 0 {
+		admissionPolicy, err = policy.New(log, opts.AdmissionPolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("loaded admission policies", "file", opts.AdmissionPolicyFile, "policies", admissionPolicy.Policies())
+	}
+
+	var sanPolicy *sanpolicy.Policy
+	if len(opts.SANPolicyFile) > 0 {
+		sanPolicy, err = sanpolicy.Load(opts.SANPolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("loaded SAN policy", "file", opts.SANPolicyFile, "rules", len(sanPolicy.Rules))
+	}
+
+	var routingPolicy *routing.Policy
+	if len(opts.IssuerRoutingPolicyFile) > 0 {
+		routingPolicy, err = routing.Load(opts.IssuerRoutingPolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("loaded issuer routing policy", "file", opts.IssuerRoutingPolicyFile, "rules", len(routingPolicy.Rules))
+	}
+
+	certSigners := make(map[string]cmmeta.IssuerReference, len(opts.CertSignerIssuers))
+	for certSigner, ref := range opts.CertSignerIssuers {
+		issuerRef, err := routing.ParseIssuerRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issuer for CertSigner %q: %w", certSigner, err)
+		}
+		certSigners[certSigner] = issuerRef
+	}
+
+	rateLimiters, err := newRateLimiters(opts.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditSink audit.Sink
+	if len(opts.AuditLogPath) > 0 {
+		auditSink, err = audit.NewFileSink(opts.AuditLogPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("writing certificate request audit log", "path", opts.AuditLogPath)
+	}
+
 	return &Server{
-		opts:           opts,
-		log:            log.WithName("grpc-server").WithValues("serving-addr", opts.ServingAddress),
-		authenticators: authenticators,
-		cm:             cm,
-		tls:            tls,
-		nodeAuthorizer: nodeAuthorizer,
+		opts:               opts,
+		log:                log.WithName("grpc-server").WithValues("serving-addr", opts.ServingAddress),
+		authenticators:     authenticators,
+		meshWatcher:        meshWatcher,
+		trustDomain:        tls.TrustDomain(),
+		trustDomainAliases: tls.TrustDomainAliases(),
+		cm:                 cm,
+		tls:                tls,
+		keyPolicy:          keyPolicy,
+		nodeAuthorizer:     nodeAuthorizer,
+		remoteClusters:     remotes,
+		denylist:           identityDenylist,
+		authorizer:         authorizer,
+		namespaceDurations: nsDurations,
+		admissionPolicy:    admissionPolicy,
+		sanPolicy:          sanPolicy,
+		routingPolicy:      routingPolicy,
+		certSigners:        certSigners,
+		rateLimiters:       rateLimiters,
+		signLimiter:        newSignLimiter(opts.MaxInFlightSigningRequests, opts.MaxQueuedSigningRequests),
+		auditSink:          auditSink,
 	}, nil
 }
 
+// newAuthenticators returns the chain of authenticators used to authenticate
+// incoming requests, wrapping the given Kubernetes token authenticator with
+// audience enforcement and caching where configured.
+func newAuthenticators(kubeAuthenticator security.Authenticator, opts AuthenticatorOptions) []security.Authenticator {
+	var authenticators []security.Authenticator
+	if opts.EnableClientCert {
+		authenticators = append(authenticators, &authenticate.ClientCertAuthenticator{})
+	}
+
+	if len(opts.Audiences) > 0 {
+		kubeAuthenticator = &audienceAuthenticator{Authenticator: kubeAuthenticator, audiences: opts.Audiences}
+	}
+	if opts.CacheTTL > 0 {
+		kubeAuthenticator = newCachingAuthenticator(kubeAuthenticator, opts.CacheTTL, opts.CacheSize)
+	}
+
+	return append(authenticators, kubeAuthenticator)
+}
+
 // Start is a blocking func that will run the client facing certificate service
 func (s *Server) Start(ctx context.Context) error {
 	tlsConfig, err := s.tls.Config(ctx)
@@ -153,9 +424,17 @@ func (s *Server) Start(ctx context.Context) error {
 		grpcprom.WithServerCounterOptions(grpcprom.WithNamespace("cert_manager_istio_csr")),
 		grpcprom.WithServerHandlingTimeHistogram(grpcprom.WithHistogramNamespace("cert_manager_istio_csr")),
 	)
+	interceptors := []grpc.UnaryServerInterceptor{srvmetrics.UnaryServerInterceptor(), s.loggingInterceptor}
+	if s.rateLimiters.enabled() {
+		interceptors = append(interceptors, s.rateLimitInterceptor)
+	}
+
 	creds := credentials.NewTLS(tlsConfig)
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(srvmetrics.UnaryServerInterceptor()),
+		// Trace requests, propagating the trace context of the istio agent if
+		// present. This is a no-op if tracing is not enabled.
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
 		grpc.Creds(creds),
 	)
 
@@ -168,6 +447,26 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to register gRPC Prometheus metrics: %w", err)
 	}
 
+	if s.remoteClusters != nil {
+		go s.remoteClusters.Start(ctx)
+	}
+
+	if s.denylist != nil {
+		go s.denylist.Start(ctx)
+	}
+
+	if s.namespaceDurations != nil {
+		go s.namespaceDurations.Start(ctx)
+	}
+
+	if s.admissionPolicy != nil {
+		go func() {
+			if err := s.admissionPolicy.Start(ctx); err != nil {
+				s.log.Error(err, "failed to watch admission policy file, policies will not be reloaded")
+			}
+		}()
+	}
+
 	// listen on the configured address
 	lc := net.ListenConfig{}
 	listener, err := lc.Listen(ctx, "tcp", s.opts.ServingAddress)
@@ -176,7 +475,9 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	// handle termination gracefully
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		<-ctx.Done()
 
 		s.lock.Lock()
@@ -194,35 +495,147 @@ func (s *Server) Start(ctx context.Context) error {
 	s.ready = true
 	s.lock.Unlock()
 
-	return grpcServer.Serve(listener)
+	err = grpcServer.Serve(listener)
+	if ctx.Err() != nil {
+		// Wait for in-flight requests to complete, so that nothing is written
+		// to the audit log once the server has stopped.
+		<-stopped
+	}
+	return err
+}
+
+// Close releases the resources held by the server, flushing and closing the
+// audit log. It must only be called once the server has stopped.
+func (s *Server) Close() error {
+	if closer, ok := s.auditSink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close audit log: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // CreateCertificate is the istio grpc API func, to authenticate, authorize,
 // and sign CSRs requests from istio clients.
 func (s *Server) CreateCertificate(ctx context.Context, icr *securityapi.IstioCertificateRequest) (*securityapi.IstioCertificateResponse, error) {
+	record := newAuditRecord(icr, time.Now())
+	defer s.writeAuditRecord(record)
 
 	// authn incoming requests, and build concatenated identities for labelling
-	identities, ok := s.authRequest(ctx, icr)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
+	identities, csr, caller, err := s.authRequest(ctx, icr)
+	auditCaller(record, icr, caller)
+	if err != nil {
+		record.Reason = err.Error()
+		return nil, s.statusError(err)
+	}
+
+	log := s.requestLogger(ctx).WithValues("identities", identities)
+
+	if s.denylist != nil {
+		if err := s.checkDenylist(record, strings.Split(identities, ","), caller.Identities); err != nil {
+			log.Error(err, "certificate request was blocked by the identity denylist")
+			record.Reason = err.Error()
+			return nil, s.statusError(err)
+		}
 	}
 
-	log := s.log.WithValues("identities", identities)
+	if s.authorizer != nil {
+		if err := s.authorizeExternally(logr.NewContext(ctx, log), icr, csr, strings.Split(identities, ","), caller); err != nil {
+			log.Error(err, "certificate request was not authorized by external authorization")
+			record.Reason = err.Error()
+			return nil, s.statusError(err)
+		}
+	}
+	record.GrantedIdentities = strings.Split(identities, ",")
 
 	// If requested duration is larger than the maximum value, override with the
-	// maxiumum value.
-	duration := min(time.Duration(icr.GetValidityDuration())*time.Second, s.opts.MaximumClientCertificateDuration)
+	// maxiumum value. Namespace limits may further restrict the duration.
+	duration, maxDuration, err := s.certificateDuration(strings.Split(identities, ","), time.Duration(icr.GetValidityDuration())*time.Second)
+	if err != nil {
+		log.Error(err, "failed to determine certificate duration")
+		record.Reason = err.Error()
+		return nil, s.statusError(err)
+	}
+	record.GrantedDuration = duration.String()
+	record.MaxDuration = maxDuration.String()
+	log = log.WithValues("duration", duration, "max-duration", maxDuration)
 
-	bundle, err := s.cm.Sign(ctx, identities, []byte(icr.GetCsr()), duration, []cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageServerAuth})
+	// Grant the extended key usages requested by the CSR, as allowed by policy.
+	usages, err := s.grantedExtKeyUsages(strings.Split(identities, ","), csr)
+	if err != nil {
+		log.Error(err, "failed to determine certificate extended key usages")
+		record.Reason = err.Error()
+		return nil, s.statusError(err)
+	}
+	keyUsages := certManagerKeyUsages(usages)
+	record.Usages = keyUsages
+	log = log.WithValues("usages", keyUsages)
+
+	// Select the issuer for the request. A requested CertSigner takes
+	// precedence over the routing policy. If neither select an issuer, the
+	// default issuer is used.
+	var issuerRef *cmmeta.IssuerReference
+	if certSigner := icr.GetMetadata().GetFields()[security.CertSigner].GetStringValue(); len(certSigner) > 0 && len(s.certSigners) > 0 {
+		ref, ok := s.certSigners[certSigner]
+		if !ok {
+			err := newRequestError(codes.InvalidArgument, reasonUnknownCertSigner, fmt.Sprintf("unknown CertSigner %q", certSigner), nil)
+			log.Error(err, "rejecting request with unknown CertSigner", "cert-signer", certSigner)
+			record.Reason = err.Error()
+			return nil, s.statusError(err)
+		}
+		issuerRef = &ref
+		log = log.WithValues("cert-signer", certSigner)
+	} else if ref, rule := s.routingPolicy.IssuerRefFor(strings.Split(identities, ",")); ref != nil {
+		issuerRef = ref
+		log = log.WithValues("routing-rule", rule)
+	}
+	if issuerRef != nil {
+		log = log.WithValues("issuer-name", issuerRef.Name, "issuer-kind", issuerRef.Kind, "issuer-group", issuerRef.Group)
+	}
+
+	ctx = logr.NewContext(ctx, log)
+
+	// Signing waits for a free signing slot, held until the request is signed
+	// even if it is shared with, and outlives, this call. The request is shed
+	// if the queue is full so that clients back off.
+	ctx = certmanager.WithSigningSlots(ctx, s.signLimiter.acquire)
+
+	bundle, err := s.cm.Sign(ctx, identities, []byte(icr.GetCsr()), duration, keyUsages, issuerRef)
+	signed := time.Now()
+	if errors.Is(err, errSigningQueueFull) {
+		log.V(2).Info("shedding certificate request, signing queue is full")
+		record.Reason = errSigningQueueFull.Error()
+		return nil, s.statusError(newRequestError(codes.Unavailable, reasonSigningQueueFull, "too many in-flight certificate requests, retry later", nil))
+	}
+
+	record.Outcome = audit.OutcomeFailed
 	if err != nil {
 		log.Error(err, "failed to sign incoming client certificate signing request")
-		return nil, status.Error(codes.Internal, "failed to sign certificate request")
+		record.Issuer = issuerRef
+		record.Reason = err.Error()
+		return nil, s.statusError(signRequestError(err))
 	}
+	record.Issuer = &bundle.IssuerRef
 
-	certChain, err := s.parseCertificateBundle(ctx, bundle)
+	verifyCtx, verifySpan := tracer.Start(ctx, "VerifyCertificateChain")
+	certChain, leaf, err := s.parseCertificateBundle(verifyCtx, bundle, usages)
 	if err != nil {
+		tracing.EndSpan(verifySpan, err)
 		log.Error(err, "failed to parse and verify signed certificate chain from issuer")
-		return nil, status.Error(codes.Internal, "failed to parse and verify signed certificate from issuer")
+		record.Reason = err.Error()
+		return nil, s.statusError(newRequestError(codes.Internal, reasonInvalidIssuedCertificate, "failed to parse and verify signed certificate from issuer", err))
+	}
+	auditCertificate(record, leaf)
+
+	// Ensure the issuer returned a certificate for the CSR, identities and
+	// usages that were requested.
+	err = verifyLeaf(leaf, csr, strings.Split(identities, ","), signed, duration, usages)
+	tracing.EndSpan(verifySpan, err)
+	if err != nil {
+		log.Error(err, "issued certificate does not match the certificate request")
+		record.Reason = err.Error()
+		return nil, s.statusError(newRequestError(codes.Internal, reasonIssuedCertificateMismatch, "issued certificate does not match the certificate request", err))
 	}
 
 	// Build client response object
@@ -231,6 +644,7 @@ func (s *Server) CreateCertificate(ctx context.Context, icr *securityapi.IstioCe
 	}
 
 	log.V(2).Info("workload CertificateRequest signed")
+	record.Outcome = audit.OutcomeGranted
 
 	// Return response to the client
 	return response, nil
@@ -247,28 +661,39 @@ func (s *Server) Check(_ *http.Request) error {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
-	if s.ready {
-		return nil
+	if !s.ready {
+		return errors.New("not ready")
 	}
-	return errors.New("not ready")
+
+	// Do not serve until the denylist is known.
+	if s.denylist != nil && !s.denylist.synced.Load() {
+		return errors.New("denylist has not been read")
+	}
+
+	// Do not serve until namespace duration limits are known.
+	if s.namespaceDurations != nil && !s.namespaceDurations.synced() {
+		return errors.New("namespace informer has not synced")
+	}
+
+	return nil
 }
 
 // parseCertificateChain will attempt to parse the certmanager certificate
 // bundle, and return a chain of certificates with the last being the root CAs
-// bundle.
+// bundle, along with the parsed leaf certificate.
 // This function will ensure the chain is a flat linked list, and is valid for
-// at least one of the root CAs.
-func (s *Server) parseCertificateBundle(ctx context.Context, bundle certmanager.Bundle) ([]string, error) {
+// at least one of the root CAs, for each of the given extended key usages.
+func (s *Server) parseCertificateBundle(ctx context.Context, bundle certmanager.Bundle, usages []x509.ExtKeyUsage) ([]string, *x509.Certificate, error) {
 	// Parse returned signed certificate chain. Append root CA and validate it is a flat chain.
 	respBundle, err := pki.ParseSingleCertificateChainPEM(bundle.Certificate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse and verify chain returned from issuer: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse and verify chain returned from issuer: %w", err)
 	}
 
 	// Verify that the signed chain is a member of one of the root CAs.
 	respCerts, err := pki.DecodeX509CertificateChainBytes(respBundle.ChainPEM)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode certificate chain returned from issuer: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode certificate chain returned from issuer: %w", err)
 	}
 
 	intermediatePool := x509.NewCertPool()
@@ -278,16 +703,20 @@ func (s *Server) parseCertificateBundle(ctx context.Context, bundle certmanager.
 
 	rootCAs := s.tls.RootCAs(ctx)
 	if rootCAs == nil {
-		return nil, ctx.Err()
+		return nil, nil, ctx.Err()
 	}
 
-	opts := x509.VerifyOptions{
-		Intermediates: intermediatePool,
-		Roots:         rootCAs.CertPool,
-		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-	}
-	if _, err := respCerts[0].Verify(opts); err != nil {
-		return nil, fmt.Errorf("failed to verify the issued certificate chain against the current mesh roots: %w", err)
+	// Verify each usage separately, as verification only requires the chain
+	// to be valid for any one of the given usages.
+	for _, usage := range usages {
+		opts := x509.VerifyOptions{
+			Intermediates: intermediatePool,
+			Roots:         rootCAs.CertPool,
+			KeyUsages:     []x509.ExtKeyUsage{usage},
+		}
+		if _, err := respCerts[0].Verify(opts); err != nil {
+			return nil, nil, fmt.Errorf("failed to verify the issued certificate chain against the current mesh roots: %w", err)
+		}
 	}
 
 	// Build the certificate chain, and tag on the rootCAs as the last entry.
@@ -295,10 +724,10 @@ func (s *Server) parseCertificateBundle(ctx context.Context, bundle certmanager.
 	for _, cert := range respCerts {
 		certEncoded, err := pki.EncodeX509(cert)
 		if err != nil {
-			return nil, fmt.Errorf("failed to encode signed certificate: %w", err)
+			return nil, nil, fmt.Errorf("failed to encode signed certificate: %w", err)
 		}
 		certChain = append(certChain, string(certEncoded))
 	}
 
-	return append(certChain, string(rootCAs.PEM)), nil
+	return append(certChain, string(rootCAs.PEM)), respCerts[0], nil
 }
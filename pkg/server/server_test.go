@@ -25,12 +25,18 @@ import (
 	"crypto/x509/pkix"
 	"errors"
 	"math/big"
+	"net/url"
 	"testing"
 	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
@@ -55,6 +61,7 @@ import (
 
 	"github.com/cert-manager/istio-csr/pkg/certmanager"
 	cmfake "github.com/cert-manager/istio-csr/pkg/certmanager/fake"
+	"github.com/cert-manager/istio-csr/pkg/server/internal/routing"
 	csrtls "github.com/cert-manager/istio-csr/pkg/tls"
 	tlsfake "github.com/cert-manager/istio-csr/pkg/tls/fake"
 	"github.com/cert-manager/istio-csr/test/gen"
@@ -72,7 +79,9 @@ func (p pod) Identity() string {
 	}.String()
 }
 
-func genRootLeafPEM(t *testing.T) ([]byte, []byte, *x509.CertPool) {
+// genRootLeafPEM generates a root CA, and a leaf certificate signed by it for
+// the test CSR key and the given identities.
+func genRootLeafPEM(t *testing.T, identities ...string) ([]byte, []byte, *x509.CertPool) {
 	rootPK, err := pki.GenerateECPrivateKey(256)
 	if err != nil {
 		t.Fatal(err)
@@ -97,10 +106,15 @@ func genRootLeafPEM(t *testing.T) ([]byte, []byte, *x509.CertPool) {
 	rootPool := x509.NewCertPool()
 	rootPool.AddCert(rootCert)
 
-	leafPK, err := pki.GenerateECPrivateKey(256)
-	if err != nil {
-		t.Fatal(err)
+	var uris []*url.URL
+	for _, identity := range identities {
+		uri, err := url.Parse(identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uris = append(uris, uri)
 	}
+
 	leafCertPEM, _, err := pki.SignCertificate(&x509.Certificate{
 		Version: 2, BasicConstraintsValid: true, SerialNumber: big.NewInt(0),
 		Subject: pkix.Name{
@@ -108,8 +122,8 @@ func genRootLeafPEM(t *testing.T) ([]byte, []byte, *x509.CertPool) {
 		},
 		NotBefore: time.Now(), NotAfter: time.Now().Add(time.Minute),
 		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		PublicKey: leafPK.Public(), IsCA: false,
-	}, rootCert, leafPK.Public(), rootPK)
+		PublicKey: gen.PublicKey(), IsCA: false, URIs: uris,
+	}, rootCert, gen.PublicKey(), rootPK)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -119,19 +133,21 @@ func genRootLeafPEM(t *testing.T) ([]byte, []byte, *x509.CertPool) {
 func Test_CreateCertificate(t *testing.T) {
 	const spiffeDomain = "spiffe://foo"
 
-	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t)
+	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t, spiffeDomain)
 
 	tests := map[string]struct {
 		icr func(t *testing.T) *securityapi.IstioCertificateRequest
 
-		cm          func(t *testing.T) certmanager.Signer
-		tls         csrtls.Interface
-		maxDuration time.Duration
+		cm            func(t *testing.T) certmanager.Signer
+		tls           csrtls.Interface
+		maxDuration   time.Duration
+		routingPolicy *routing.Policy
+		certSigners   map[string]cmmeta.IssuerReference
 
 		expResponse *securityapi.IstioCertificateResponse
 		expErr      error
 	}{
-		"if authn fails, should return Unauthenticated error code": {
+		"if CSR identities do not match the authenticated identities, should return PermissionDenied error code": {
 			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
 				return &securityapi.IstioCertificateRequest{
 					Csr: string(gen.MustCSR(t,
@@ -141,7 +157,7 @@ func Test_CreateCertificate(t *testing.T) {
 			},
 			cm:          func(t *testing.T) certmanager.Signer { return cmfake.New() },
 			expResponse: nil,
-			expErr:      status.Error(codes.Unauthenticated, "request authenticate failure"),
+			expErr:      statusWithReason(codes.PermissionDenied, reasonIdentityMismatch, "CSR URIs do not match the authenticated identities", nil),
 		},
 		"if authn succeeds but sign fails, should return Internal error code": {
 			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
@@ -152,7 +168,7 @@ func Test_CreateCertificate(t *testing.T) {
 				}
 			},
 			cm: func(t *testing.T) certmanager.Signer {
-				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 					if identity != spiffeDomain {
 						t.Errorf("unexpected identity, exp=%s got=%s", spiffeDomain, identity)
 					}
@@ -161,7 +177,7 @@ func Test_CreateCertificate(t *testing.T) {
 			},
 			maxDuration: time.Hour,
 			expResponse: nil,
-			expErr:      status.Error(codes.Internal, "failed to sign certificate request"),
+			expErr:      statusWithReason(codes.Internal, reasonSigningFailed, "failed to sign certificate request", nil),
 		},
 		"if authn and sign succeeds, should sign certificate with given duration and respond": {
 			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
@@ -173,7 +189,7 @@ func Test_CreateCertificate(t *testing.T) {
 				}
 			},
 			cm: func(t *testing.T) certmanager.Signer {
-				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 					if identity != spiffeDomain {
 						t.Errorf("unexpected identity, exp=%s got=%s", spiffeDomain, identity)
 					}
@@ -200,7 +216,7 @@ func Test_CreateCertificate(t *testing.T) {
 				}
 			},
 			cm: func(t *testing.T) certmanager.Signer {
-				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 					if identity != spiffeDomain {
 						t.Errorf("unexpected identity, exp=%s got=%s", spiffeDomain, identity)
 					}
@@ -217,6 +233,123 @@ func Test_CreateCertificate(t *testing.T) {
 			expResponse: &securityapi.IstioCertificateResponse{CertChain: []string{string(leafCertPEM), string(rootCertPEM)}},
 			expErr:      nil,
 		},
+		"if the identity matches an issuer routing policy rule, should sign with the rule's issuer": {
+			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
+				return &securityapi.IstioCertificateRequest{
+					Csr: string(gen.MustCSR(t,
+						gen.SetCSRIdentities([]string{spiffeDomain}),
+					)),
+					ValidityDuration: 60 * 60,
+				}
+			},
+			routingPolicy: &routing.Policy{
+				Rules: []routing.Rule{
+					{
+						Identities: []string{"spiffe://bar"},
+						IssuerRef:  cmmeta.IssuerReference{Name: "bar-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+					},
+					{
+						Identities: []string{spiffeDomain},
+						IssuerRef:  cmmeta.IssuerReference{Name: "foo-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+					},
+				},
+			},
+			cm: func(t *testing.T) certmanager.Signer {
+				return cmfake.New().WithSign(func(_ context.Context, _ string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					assert.Equal(t, &cmmeta.IssuerReference{Name: "foo-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"}, issuerRef)
+
+					return certmanager.Bundle{Certificate: leafCertPEM, CA: []byte("bad-cert")}, nil
+				})
+			},
+			tls:         tlsfake.New().WithRootCAs(rootCertPEM, rootPool),
+			maxDuration: time.Hour,
+			expResponse: &securityapi.IstioCertificateResponse{CertChain: []string{string(leafCertPEM), string(rootCertPEM)}},
+			expErr:      nil,
+		},
+		"if a known CertSigner is requested, should sign with the CertSigner's issuer over the routing policy": {
+			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
+				return &securityapi.IstioCertificateRequest{
+					Csr: string(gen.MustCSR(t,
+						gen.SetCSRIdentities([]string{spiffeDomain}),
+					)),
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						security.CertSigner: structpb.NewStringValue("payments"),
+					}},
+					ValidityDuration: 60 * 60,
+				}
+			},
+			routingPolicy: &routing.Policy{
+				Rules: []routing.Rule{
+					{
+						Identities: []string{spiffeDomain},
+						IssuerRef:  cmmeta.IssuerReference{Name: "foo-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+					},
+				},
+			},
+			certSigners: map[string]cmmeta.IssuerReference{
+				"payments": {Name: "payments-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			},
+			cm: func(t *testing.T) certmanager.Signer {
+				return cmfake.New().WithSign(func(_ context.Context, _ string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					assert.Equal(t, &cmmeta.IssuerReference{Name: "payments-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"}, issuerRef)
+
+					return certmanager.Bundle{Certificate: leafCertPEM, CA: []byte("bad-cert")}, nil
+				})
+			},
+			tls:         tlsfake.New().WithRootCAs(rootCertPEM, rootPool),
+			maxDuration: time.Hour,
+			expResponse: &securityapi.IstioCertificateResponse{CertChain: []string{string(leafCertPEM), string(rootCertPEM)}},
+			expErr:      nil,
+		},
+		"if an unknown CertSigner is requested, should return InvalidArgument error code": {
+			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
+				return &securityapi.IstioCertificateRequest{
+					Csr: string(gen.MustCSR(t,
+						gen.SetCSRIdentities([]string{spiffeDomain}),
+					)),
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						security.CertSigner: structpb.NewStringValue("unknown"),
+					}},
+					ValidityDuration: 60 * 60,
+				}
+			},
+			certSigners: map[string]cmmeta.IssuerReference{
+				"payments": {Name: "payments-ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			},
+			cm: func(t *testing.T) certmanager.Signer {
+				return cmfake.New().WithSign(func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					t.Error("unexpected call to sign")
+					return certmanager.Bundle{}, nil
+				})
+			},
+			maxDuration: time.Hour,
+			expResponse: nil,
+			expErr:      statusWithReason(codes.InvalidArgument, reasonUnknownCertSigner, `unknown CertSigner "unknown"`, nil),
+		},
+		"if CertSigners are not configured, a requested CertSigner should be ignored": {
+			icr: func(t *testing.T) *securityapi.IstioCertificateRequest {
+				return &securityapi.IstioCertificateRequest{
+					Csr: string(gen.MustCSR(t,
+						gen.SetCSRIdentities([]string{spiffeDomain}),
+					)),
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						security.CertSigner: structpb.NewStringValue("payments"),
+					}},
+					ValidityDuration: 60 * 60,
+				}
+			},
+			cm: func(t *testing.T) certmanager.Signer {
+				return cmfake.New().WithSign(func(_ context.Context, _ string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, issuerRef *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+					assert.Nil(t, issuerRef)
+
+					return certmanager.Bundle{Certificate: leafCertPEM, CA: []byte("bad-cert")}, nil
+				})
+			},
+			tls:         tlsfake.New().WithRootCAs(rootCertPEM, rootPool),
+			maxDuration: time.Hour,
+			expResponse: &securityapi.IstioCertificateResponse{CertChain: []string{string(leafCertPEM), string(rootCertPEM)}},
+			expErr:      nil,
+		},
 	}
 
 	for name, test := range tests {
@@ -228,9 +361,11 @@ func Test_CreateCertificate(t *testing.T) {
 				authenticators: []security.Authenticator{
 					newMockAuthn([]string{spiffeDomain}, ""),
 				},
-				log: ktesting.NewLogger(t, ktesting.DefaultConfig),
-				cm:  test.cm(t),
-				tls: test.tls,
+				log:           ktesting.NewLogger(t, ktesting.DefaultConfig),
+				cm:            test.cm(t),
+				tls:           test.tls,
+				routingPolicy: test.routingPolicy,
+				certSigners:   test.certSigners,
 			}
 
 			resp, err := s.CreateCertificate(t.Context(), test.icr(t))
@@ -249,46 +384,7 @@ func Test_CreateCertificate(t *testing.T) {
 func Test_CreateCertificateE2EUsingClientCertAuthenticator(t *testing.T) {
 	const spiffeDomain = "spiffe://foo"
 
-	rootPK, err := pki.GenerateECPrivateKey(256)
-	if err != nil {
-		t.Fatal(err)
-	}
-	rootCert := &x509.Certificate{
-		Version:               2,
-		BasicConstraintsValid: true,
-		SerialNumber:          big.NewInt(0),
-		Subject: pkix.Name{
-			CommonName: "root-ca",
-		},
-		NotBefore: time.Now(),
-		NotAfter:  time.Now().Add(time.Minute),
-		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		PublicKey: rootPK.Public(),
-		IsCA:      true,
-	}
-	rootCertPEM, rootCert, err := pki.SignCertificate(rootCert, rootCert, rootPK.Public(), rootPK)
-	if err != nil {
-		t.Fatal(err)
-	}
-	rootPool := x509.NewCertPool()
-	rootPool.AddCert(rootCert)
-
-	leafPK, err := pki.GenerateECPrivateKey(256)
-	if err != nil {
-		t.Fatal(err)
-	}
-	leafCertPEM, _, err := pki.SignCertificate(&x509.Certificate{
-		Version: 2, BasicConstraintsValid: true, SerialNumber: big.NewInt(0),
-		Subject: pkix.Name{
-			CommonName: "leaf-cert",
-		},
-		NotBefore: time.Now(), NotAfter: time.Now().Add(time.Minute),
-		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		PublicKey: leafPK.Public(), IsCA: false,
-	}, rootCert, leafPK.Public(), rootPK)
-	if err != nil {
-		t.Fatal(err)
-	}
+	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t, spiffeDomain)
 
 	tests := map[string]struct {
 		certChain   func(t *testing.T) [][]*x509.Certificate
@@ -298,7 +394,7 @@ func Test_CreateCertificateE2EUsingClientCertAuthenticator(t *testing.T) {
 		"no client cert": {
 			certChain:   func(t *testing.T) [][]*x509.Certificate { return nil },
 			expResponse: nil,
-			expErr:      status.Error(codes.Unauthenticated, "request authenticate failure"),
+			expErr:      statusWithReason(codes.Unauthenticated, reasonAuthenticationFailed, "request authenticate failure", nil),
 		},
 		"invalid identity": {
 			certChain: func(t *testing.T) [][]*x509.Certificate {
@@ -317,7 +413,7 @@ func Test_CreateCertificateE2EUsingClientCertAuthenticator(t *testing.T) {
 				}
 			},
 			expResponse: nil,
-			expErr:      status.Error(codes.Unauthenticated, "request authenticate failure"),
+			expErr:      statusWithReason(codes.PermissionDenied, reasonIdentityMismatch, "CSR URIs do not match the authenticated identities", nil),
 		},
 		"if cert provides valid identities, should sign and respond": {
 			certChain: func(t *testing.T) [][]*x509.Certificate {
@@ -350,7 +446,7 @@ func Test_CreateCertificateE2EUsingClientCertAuthenticator(t *testing.T) {
 					&authenticate.ClientCertAuthenticator{},
 				},
 				log: ktesting.NewLogger(t, ktesting.DefaultConfig),
-				cm: cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+				cm: cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 					if identity != spiffeDomain {
 						t.Errorf("unexpected identity, exp=%s got=%s", spiffeDomain, identity)
 					}
@@ -393,9 +489,6 @@ func Test_CreateCertificateE2EUsingClientCertAuthenticator(t *testing.T) {
 // See original code: https://github.com/istio/istio/blob/1.22.3/security/pkg/server/ca/server_test.go
 // See license of original code: https://github.com/istio/istio/blob/1.22.3/LICENSE
 func Test_CreateCertificateWithImpersonateIdentity(t *testing.T) {
-
-	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t)
-
 	allowZtunnel := sets.Set[types.NamespacedName]{
 		{Name: "ztunnel", Namespace: "istio-system"}: {},
 	}
@@ -420,6 +513,8 @@ func Test_CreateCertificateWithImpersonateIdentity(t *testing.T) {
 		node:      "zt-node",
 	}
 
+	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t, podSameNode.Identity())
+
 	tests := map[string]struct {
 		csr string
 
@@ -440,7 +535,7 @@ func Test_CreateCertificateWithImpersonateIdentity(t *testing.T) {
 				gen.SetCSRIdentities([]string{podSameNode.Identity()}),
 			)),
 			cm: func(t *testing.T) certmanager.Signer {
-				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+				return cmfake.New().WithSign(func(_ context.Context, identity string, _ []byte, dur time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 					if identity != podSameNode.Identity() {
 						t.Errorf("unexpected identity, exp=%s got=%s", podSameNode.Identity(), identity)
 					}
@@ -479,7 +574,7 @@ func Test_CreateCertificateWithImpersonateIdentity(t *testing.T) {
 			pods:                 []pod{ztunnelPod, podSameNode},
 			impersonatedIdentity: podSameNode.Identity(),
 			expResponse:          nil,
-			expErr:               status.Error(codes.Unauthenticated, "request authenticate failure"),
+			expErr:               statusWithReason(codes.PermissionDenied, reasonImpersonationDenied, "caller is not authorized to impersonate the requested identity", nil),
 		},
 		"identites do not match": {
 			csr: string(gen.MustCSR(t,
@@ -496,7 +591,7 @@ func Test_CreateCertificateWithImpersonateIdentity(t *testing.T) {
 			pods:                 []pod{ztunnelPod, podSameNode},
 			impersonatedIdentity: podSameNode.Identity(),
 			expResponse:          nil,
-			expErr:               status.Error(codes.Unauthenticated, "request authenticate failure"),
+			expErr:               statusWithReason(codes.PermissionDenied, reasonIdentityMismatch, "CSR URIs do not match the impersonated identity", nil),
 		},
 	}
 
@@ -562,7 +657,7 @@ type testBundle struct {
 	pk   crypto.PrivateKey
 }
 
-func mustCreateBundle(t *testing.T, issuer *testBundle, name string) *testBundle {
+func mustCreateBundle(t *testing.T, issuer *testBundle, name string, extKeyUsages ...x509.ExtKeyUsage) *testBundle {
 	pk, err := pki.GenerateECPrivateKey(256)
 	if err != nil {
 		t.Fatal(err)
@@ -583,9 +678,10 @@ func mustCreateBundle(t *testing.T, issuer *testBundle, name string) *testBundle
 		Subject: pkix.Name{
 			CommonName: name,
 		},
-		NotBefore: time.Now(),
-		NotAfter:  time.Now().Add(time.Minute),
-		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		NotBefore:   time.Now(),
+		NotAfter:    time.Now().Add(time.Minute),
+		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: extKeyUsages,
 	}
 
 	var (
@@ -626,9 +722,11 @@ func Test_parseCertificateBundle(t *testing.T) {
 	int1B := mustCreateBundle(t, int1A, "intA-2")
 	int2A := mustCreateBundle(t, root2, "intB-1")
 	leaf := mustCreateBundle(t, int1B, "leaf")
+	clientLeaf := mustCreateBundle(t, int1B, "client-leaf", x509.ExtKeyUsageClientAuth)
 
 	tests := map[string]struct {
 		bundle    certmanager.Bundle
+		usages    []x509.ExtKeyUsage
 		rootCerts func(t *testing.T) ([]byte, *x509.CertPool)
 		expChain  []string
 		expErr    bool
@@ -684,6 +782,27 @@ func Test_parseCertificateBundle(t *testing.T) {
 			expChain: []string{string(leaf.pem), string(int1B.pem), string(int1A.pem), string(root1.pem) + string(root2.pem) + string(root3.pem)},
 			expErr:   false,
 		},
+		"if leaf is only valid for client auth, and client auth is requested, return single chain": {
+			bundle: certmanager.Bundle{Certificate: joinPEM(clientLeaf.pem, int1B.pem, int1A.pem)},
+			usages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			rootCerts: func(t *testing.T) ([]byte, *x509.CertPool) {
+				pool := x509.NewCertPool()
+				pool.AddCert(root1.cert)
+				return root1.pem, pool
+			},
+			expChain: []string{string(clientLeaf.pem), string(int1B.pem), string(int1A.pem), string(root1.pem)},
+			expErr:   false,
+		},
+		"if leaf is only valid for client auth, and client and server auth are requested, error": {
+			bundle: certmanager.Bundle{Certificate: joinPEM(clientLeaf.pem, int1B.pem, int1A.pem)},
+			rootCerts: func(t *testing.T) ([]byte, *x509.CertPool) {
+				pool := x509.NewCertPool()
+				pool.AddCert(root1.cert)
+				return root1.pem, pool
+			},
+			expChain: nil,
+			expErr:   true,
+		},
 	}
 
 	for name, test := range tests {
@@ -693,9 +812,53 @@ func Test_parseCertificateBundle(t *testing.T) {
 				tls: tlsfake.New().WithRootCAs(rootCAsPEM, rootCAsPool),
 			}
 
-			chain, err := s.parseCertificateBundle(t.Context(), test.bundle)
+			usages := test.usages
+			if usages == nil {
+				usages = defaultExtKeyUsages
+			}
+
+			chain, _, err := s.parseCertificateBundle(t.Context(), test.bundle, usages)
 			assert.Equalf(t, test.expErr, err != nil, "%v", err)
 			assert.Equal(t, test.expChain, chain)
 		})
 	}
 }
+
+func Test_CreateCertificateTracing(t *testing.T) {
+	const spiffeDomain = "spiffe://foo"
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	rootCertPEM, leafCertPEM, rootPool := genRootLeafPEM(t, spiffeDomain)
+
+	s := &Server{
+		opts: Options{
+			MaximumClientCertificateDuration: time.Hour,
+		},
+		authenticators: []security.Authenticator{
+			newMockAuthn(nil, "bad token"),
+			newMockAuthn([]string{spiffeDomain}, ""),
+		},
+		log: ktesting.NewLogger(t, ktesting.DefaultConfig),
+		cm: cmfake.New().WithSign(func(_ context.Context, _ string, _ []byte, _ time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+			return certmanager.Bundle{Certificate: leafCertPEM}, nil
+		}),
+		tls: tlsfake.New().WithRootCAs(rootCertPEM, rootPool),
+	}
+
+	_, err := s.CreateCertificate(t.Context(), &securityapi.IstioCertificateRequest{
+		Csr:              string(gen.MustCSR(t, gen.SetCSRIdentities([]string{spiffeDomain}))),
+		ValidityDuration: 60 * 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"Authenticate", "Authenticate", "VerifyCertificateChain"}, names)
+	assert.Equal(t, otelcodes.Error, recorder.Ended()[0].Status().Code, "expected failed authenticator span to record the error")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	metricSigningInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "signing_requests_in_flight",
+			Help:      "Number of workload certificate requests currently being signed.",
+		},
+	)
+	metricSigningQueued = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "signing_requests_queued",
+			Help:      "Number of workload certificate requests waiting for a free signing slot.",
+		},
+	)
+	metricSigningShed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "signing_requests_shed",
+			Help:      "Total number of workload certificate requests rejected because the signing queue was full.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricSigningInFlight, metricSigningQueued, metricSigningShed)
+}
+
+// errSigningQueueFull is returned when a request cannot be signed as all
+// signing slots are in use and the queue is full.
+var errSigningQueueFull = errors.New("signing queue is full")
+
+// signLimiter limits the number of concurrent calls to the signer. Requests
+// which cannot immediately be signed wait in a bounded queue. Requests which
+// arrive when the queue is full are shed. A nil signLimiter is unlimited.
+type signLimiter struct {
+	slots     chan struct{}
+	maxQueued int64
+	queued    atomic.Int64
+}
+
+// newSignLimiter returns a signLimiter allowing maxInFlight concurrent signing
+// requests, with up to maxQueued waiting requests. Returns nil if maxInFlight
+// is not positive.
+func newSignLimiter(maxInFlight, maxQueued int) *signLimiter {
+	if maxInFlight <= 0 {
+		return nil
+	}
+
+	return &signLimiter{
+		slots:     make(chan struct{}, maxInFlight),
+		maxQueued: int64(max(maxQueued, 0)),
+	}
+}
+
+// acquire blocks until a signing slot is available, or the context is done.
+// Returns errSigningQueueFull if the queue is full. The returned func must be
+// called to release the slot once signing has completed.
+func (l *signLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		metricSigningInFlight.Inc()
+		return l.release, nil
+	default:
+	}
+
+	if l.queued.Add(1) > l.maxQueued {
+		l.queued.Add(-1)
+		metricSigningShed.Inc()
+		return nil, errSigningQueueFull
+	}
+
+	metricSigningQueued.Inc()
+	defer func() {
+		l.queued.Add(-1)
+		metricSigningQueued.Dec()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		metricSigningInFlight.Inc()
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release frees a signing slot.
+func (l *signLimiter) release() {
+	<-l.slots
+	metricSigningInFlight.Dec()
+}
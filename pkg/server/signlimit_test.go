@@ -0,0 +1,140 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"k8s.io/klog/v2/ktesting"
+
+	"github.com/cert-manager/istio-csr/pkg/certmanager"
+	cmfake "github.com/cert-manager/istio-csr/pkg/certmanager/fake"
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func TestSignLimiter(t *testing.T) {
+	t.Run("a nil limiter should not limit", func(t *testing.T) {
+		l := newSignLimiter(0, 0)
+		assert.Nil(t, l)
+
+		for range 10 {
+			_, err := l.acquire(t.Context())
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("requests over the queue should be shed", func(t *testing.T) {
+		l := newSignLimiter(1, 0)
+
+		release, err := l.acquire(t.Context())
+		assert.NoError(t, err)
+
+		_, err = l.acquire(t.Context())
+		assert.ErrorIs(t, err, errSigningQueueFull)
+
+		release()
+
+		release, err = l.acquire(t.Context())
+		assert.NoError(t, err)
+		release()
+	})
+
+	t.Run("queued requests should be signed once a slot is released", func(t *testing.T) {
+		l := newSignLimiter(1, 1)
+
+		release, err := l.acquire(t.Context())
+		assert.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			_, err := l.acquire(t.Context())
+			acquired <- err
+		}()
+
+		assert.Eventually(t, func() bool { return l.queued.Load() == 1 }, time.Second, time.Millisecond)
+
+		_, err = l.acquire(t.Context())
+		assert.ErrorIs(t, err, errSigningQueueFull)
+
+		release()
+		assert.NoError(t, <-acquired)
+		assert.Equal(t, int64(0), l.queued.Load())
+	})
+
+	t.Run("queued requests should return when the context is done", func(t *testing.T) {
+		l := newSignLimiter(1, 1)
+
+		_, err := l.acquire(t.Context())
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err = l.acquire(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), l.queued.Load())
+	})
+}
+
+func Test_CreateCertificateSignLimit(t *testing.T) {
+	const spiffeDomain = "spiffe://foo"
+
+	signing := make(chan struct{})
+	done := make(chan struct{})
+
+	s := &Server{
+		opts: Options{
+			MaximumClientCertificateDuration: time.Hour,
+		},
+		authenticators: []security.Authenticator{
+			newMockAuthn([]string{spiffeDomain}, ""),
+		},
+		log: ktesting.NewLogger(t, ktesting.DefaultConfig),
+		cm: cmfake.New().WithSign(func(context.Context, string, []byte, time.Duration, []cmapi.KeyUsage, *cmmeta.IssuerReference) (certmanager.Bundle, error) {
+			signing <- struct{}{}
+			<-done
+			return certmanager.Bundle{}, errors.New("generic error")
+		}),
+		signLimiter: newSignLimiter(1, 0),
+	}
+
+	icr := &securityapi.IstioCertificateRequest{
+		Csr: string(gen.MustCSR(t,
+			gen.SetCSRIdentities([]string{spiffeDomain}),
+		)),
+	}
+
+	go func() {
+		_, _ = s.CreateCertificate(t.Context(), icr)
+	}()
+	<-signing
+
+	_, err := s.CreateCertificate(t.Context(), icr)
+	assert.Equal(t, codes.Unavailable, status.Code(err), "%v", err)
+
+	close(done)
+}
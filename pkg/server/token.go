@@ -0,0 +1,149 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"istio.io/istio/pkg/security"
+)
+
+const (
+	// authorizationMetadataKey is the gRPC metadata key holding the bearer
+	// token of the client.
+	authorizationMetadataKey = "authorization"
+
+	// bearerTokenPrefix is the prefix of the authorization metadata value.
+	bearerTokenPrefix = "Bearer "
+)
+
+// tokenClaims are the claims of a JWT bearer token used by istio-csr. The
+// claims are not verified, so must only be relied upon for a token which
+// has been, or will be, authenticated.
+type tokenClaims struct {
+	Exp *json.Number  `json:"exp"`
+	Aud tokenAudience `json:"aud"`
+}
+
+// tokenAudience is the aud claim of a JWT, which may either be a single
+// string or a list of strings.
+type tokenAudience []string
+
+func (a *tokenAudience) UnmarshalJSON(data []byte) error {
+	var aud string
+	if err := json.Unmarshal(data, &aud); err == nil {
+		*a = tokenAudience{aud}
+		return nil
+	}
+
+	var auds []string
+	if err := json.Unmarshal(data, &auds); err != nil {
+		return err
+	}
+	*a = auds
+
+	return nil
+}
+
+// expiry returns the time of the exp claim. Must only be called if Exp is
+// set.
+func (c *tokenClaims) expiry() time.Time {
+	exp, err := c.Exp.Float64()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
+
+// bearerToken returns the bearer token from the gRPC metadata of the request.
+func bearerToken(ctx security.AuthContext) (string, bool) {
+	if ctx.GrpcContext == nil {
+		return "", false
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx.GrpcContext)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get(authorizationMetadataKey) {
+		if token, ok := strings.CutPrefix(value, bearerTokenPrefix); ok && len(token) > 0 {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+// parseTokenClaims returns the claims of the JWT without verifying it.
+func parseTokenClaims(token string) (*tokenClaims, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, false
+	}
+
+	return &claims, true
+}
+
+// audienceAuthenticator rejects bearer tokens which were not minted for one
+// of the accepted audiences, before passing the request to the wrapped
+// authenticator. The Kubernetes JWT authenticator has no option to restrict
+// the accepted audiences, so they are enforced here; the wrapped
+// authenticator still verifies the token itself.
+type audienceAuthenticator struct {
+	security.Authenticator
+
+	audiences []string
+}
+
+// Authenticate rejects the request if its bearer token does not contain one
+// of the accepted audiences, otherwise authenticates the request.
+func (a *audienceAuthenticator) Authenticate(ctx security.AuthContext) (*security.Caller, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return a.Authenticator.Authenticate(ctx)
+	}
+
+	claims, ok := parseTokenClaims(token)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse bearer token")
+	}
+
+	if !slices.ContainsFunc(claims.Aud, func(aud string) bool {
+		return slices.Contains(a.audiences, aud)
+	}) {
+		return nil, fmt.Errorf("token audiences %v do not contain any of the accepted audiences %v", []string(claims.Aud), a.audiences)
+	}
+
+	return a.Authenticator.Authenticate(ctx)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/server/ca/authenticate"
+)
+
+func Test_parseTokenClaims(t *testing.T) {
+	tests := map[string]struct {
+		token  string
+		expOK  bool
+		expAud []string
+		expExp int64
+	}{
+		"if the token is not a JWT, should fail": {
+			token: "not-a-jwt",
+			expOK: false,
+		},
+		"if the payload is not valid base64, should fail": {
+			token: "header.!!!.sig",
+			expOK: false,
+		},
+		"if the audience is a string, should return a single audience": {
+			token:  testJWT(t, `{"aud":"istio-ca","exp":1700000000}`),
+			expOK:  true,
+			expAud: []string{"istio-ca"},
+			expExp: 1700000000,
+		},
+		"if the audience is a list, should return all audiences": {
+			token:  testJWT(t, `{"aud":["istio-ca","https://kubernetes.default.svc"]}`),
+			expOK:  true,
+			expAud: []string{"istio-ca", "https://kubernetes.default.svc"},
+		},
+		"if the audience is invalid, should fail": {
+			token: testJWT(t, `{"aud":123}`),
+			expOK: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims, ok := parseTokenClaims(test.token)
+			if ok != test.expOK {
+				t.Fatalf("unexpected ok, exp=%t got=%t", test.expOK, ok)
+			}
+			if !ok {
+				return
+			}
+
+			if len(claims.Aud) != len(test.expAud) {
+				t.Fatalf("unexpected audiences, exp=%v got=%v", test.expAud, claims.Aud)
+			}
+			for i := range test.expAud {
+				if claims.Aud[i] != test.expAud[i] {
+					t.Errorf("unexpected audiences, exp=%v got=%v", test.expAud, claims.Aud)
+				}
+			}
+
+			if test.expExp != 0 {
+				if claims.Exp == nil || claims.expiry().Unix() != test.expExp {
+					t.Errorf("unexpected expiry, exp=%d got=%v", test.expExp, claims.Exp)
+				}
+			}
+		})
+	}
+}
+
+func Test_newAuthenticators(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	caller := &security.Caller{Identities: []string{"spiffe://cluster.local/ns/sandbox/sa/default"}}
+
+	tests := map[string]struct {
+		opts AuthenticatorOptions
+		ctx  security.AuthContext
+
+		expTypes    []string
+		expErr      bool
+		expKubeAuth int
+	}{
+		"if no options are set, should only use the kube authenticator": {
+			opts:        AuthenticatorOptions{},
+			ctx:         tokenAuthContext(testJWT(t, `{"aud":"other"}`)),
+			expTypes:    []string{"CountingAuthenticator"},
+			expKubeAuth: 2,
+		},
+		"if client cert authenticator is enabled, should be first in the chain": {
+			opts:        AuthenticatorOptions{EnableClientCert: true},
+			ctx:         tokenAuthContext(testJWT(t, `{"aud":"istio-ca"}`)),
+			expTypes:    []string{authenticate.ClientCertAuthenticatorType, "CountingAuthenticator"},
+			expKubeAuth: 2,
+		},
+		"if audiences are set and the token has an accepted audience, should authenticate": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca", "cluster-a"}},
+			ctx:         tokenAuthContext(testJWT(t, `{"aud":["https://kubernetes.default.svc","cluster-a"]}`)),
+			expTypes:    []string{"CountingAuthenticator"},
+			expKubeAuth: 2,
+		},
+		"if audiences are set and the token has no accepted audience, should reject without calling the kube authenticator": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}},
+			ctx:         tokenAuthContext(testJWT(t, `{"aud":"https://kubernetes.default.svc"}`)),
+			expTypes:    []string{"CountingAuthenticator"},
+			expErr:      true,
+			expKubeAuth: 0,
+		},
+		"if audiences are set and the token has no audience, should reject": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}},
+			ctx:         tokenAuthContext(testJWT(t, `{"sub":"foo"}`)),
+			expTypes:    []string{"CountingAuthenticator"},
+			expErr:      true,
+			expKubeAuth: 0,
+		},
+		"if audiences are set and the token cannot be parsed, should reject": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}},
+			ctx:         tokenAuthContext("not-a-jwt"),
+			expTypes:    []string{"CountingAuthenticator"},
+			expErr:      true,
+			expKubeAuth: 0,
+		},
+		"if audiences are set and there is no bearer token, should defer to the kube authenticator": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}},
+			ctx:         security.AuthContext{GrpcContext: context.Background()},
+			expTypes:    []string{"CountingAuthenticator"},
+			expKubeAuth: 2,
+		},
+		"if the cache is enabled, should only call the kube authenticator once for an accepted token": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}, CacheTTL: time.Minute, CacheSize: 10},
+			ctx:         tokenAuthContext(testJWT(t, fmt.Sprintf(`{"aud":"istio-ca","exp":%d}`, exp))),
+			expTypes:    []string{"CountingAuthenticator"},
+			expKubeAuth: 1,
+		},
+		"if the cache is enabled, should not cache a token with no accepted audience": {
+			opts:        AuthenticatorOptions{Audiences: []string{"istio-ca"}, CacheTTL: time.Minute, CacheSize: 10},
+			ctx:         tokenAuthContext(testJWT(t, fmt.Sprintf(`{"aud":"other","exp":%d}`, exp))),
+			expTypes:    []string{"CountingAuthenticator"},
+			expErr:      true,
+			expKubeAuth: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			kubeAuth := &countingAuthenticator{caller: caller}
+			authenticators := newAuthenticators(kubeAuth, test.opts)
+
+			if len(authenticators) != len(test.expTypes) {
+				t.Fatalf("unexpected number of authenticators, exp=%d got=%d", len(test.expTypes), len(authenticators))
+			}
+			for i, authenticator := range authenticators {
+				if authenticator.AuthenticatorType() != test.expTypes[i] {
+					t.Errorf("unexpected authenticator type at %d, exp=%s got=%s", i, test.expTypes[i], authenticator.AuthenticatorType())
+				}
+			}
+
+			// The kube authenticator is always last in the chain.
+			last := authenticators[len(authenticators)-1]
+			for range 2 {
+				_, err := last.Authenticate(test.ctx)
+				if (err != nil) != test.expErr {
+					t.Errorf("unexpected error, exp=%t got=%v", test.expErr, err)
+				}
+			}
+
+			if kubeAuth.calls != test.expKubeAuth {
+				t.Errorf("unexpected number of kube authenticator calls, exp=%d got=%d", test.expKubeAuth, kubeAuth.calls)
+			}
+		})
+	}
+}
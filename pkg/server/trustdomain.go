@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const spiffeScheme = "spiffe"
+
+// TrustDomainPolicy is the policy on which trust domain the SPIFFE IDs of
+// issued certificates must use.
+type TrustDomainPolicy string
+
+const (
+	// TrustDomainPolicyRequested issues certificates for the trust domain
+	// requested in the CSR, which may be the trust domain or one of its
+	// aliases.
+	TrustDomainPolicyRequested TrustDomainPolicy = "Requested"
+
+	// TrustDomainPolicyTrustDomain only issues certificates for the
+	// configured trust domain, rejecting CSRs for any of its aliases.
+	TrustDomainPolicyTrustDomain TrustDomainPolicy = "TrustDomain"
+)
+
+// acceptedTrustDomainAliases returns the configured trust domain aliases,
+// along with those of the live MeshConfig.
+func (s *Server) acceptedTrustDomainAliases() []string {
+	aliases := slices.Clone(s.trustDomainAliases)
+	if s.meshWatcher != nil {
+		for _, alias := range s.meshWatcher.Mesh().GetTrustDomainAliases() {
+			if !slices.Contains(aliases, alias) {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	return aliases
+}
+
+// matchCSRIdentities matches the expected identities against the URIs of the
+// CSR. URIs using an accepted trust domain alias match the same identity in
+// the trust domain. Returns the comma separated identities to request,
+// which are those of the CSR if matched using an alias.
+func (s *Server) matchCSRIdentities(expected []string, uris []*url.URL) (string, bool) {
+	if identitiesMatch(expected, uris) {
+		return strings.Join(expected, ","), true
+	}
+
+	aliases := s.acceptedTrustDomainAliases()
+	if len(aliases) == 0 || len(expected) != len(uris) {
+		return "", false
+	}
+
+	canonical := make([]string, len(uris))
+	requested := make([]string, len(uris))
+	for i, uri := range uris {
+		canonical[i] = canonicalIdentity(uri, s.trustDomain, aliases)
+		requested[i] = uri.String()
+	}
+
+	expectedCanonical := make([]string, len(expected))
+	for i, id := range expected {
+		uri, err := url.Parse(id)
+		if err != nil {
+			return "", false
+		}
+		expectedCanonical[i] = canonicalIdentity(uri, s.trustDomain, aliases)
+	}
+
+	slices.Sort(canonical)
+	slices.Sort(expectedCanonical)
+	if !slices.Equal(canonical, expectedCanonical) {
+		return "", false
+	}
+
+	return strings.Join(requested, ","), true
+}
+
+// checkIssuedTrustDomain returns an error if the SPIFFE IDs of the CSR use a
+// trust domain which is not allowed by the issued trust domain policy.
+func (s *Server) checkIssuedTrustDomain(uris []*url.URL) error {
+	if s.opts.IssuedTrustDomainPolicy != TrustDomainPolicyTrustDomain {
+		return nil
+	}
+
+	for _, uri := range uris {
+		if uri.Scheme == spiffeScheme && uri.Host != s.trustDomain {
+			return fmt.Errorf("SPIFFE ID %q does not use the trust domain %q", uri, s.trustDomain)
+		}
+	}
+
+	return nil
+}
+
+// canonicalIdentity returns the identity of the URI, replacing the trust
+// domain with the mesh trust domain if it is one of the aliases.
+func canonicalIdentity(uri *url.URL, trustDomain string, aliases []string) string {
+	if uri.Scheme != spiffeScheme || !slices.Contains(aliases, uri.Host) {
+		return uri.String()
+	}
+
+	canonical := *uri
+	canonical.Host = trustDomain
+	return canonical.String()
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	securityapi "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"k8s.io/klog/v2/ktesting"
+
+	"github.com/cert-manager/istio-csr/test/gen"
+
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/mesh/meshwatcher"
+)
+
+func Test_matchCSRIdentities(t *testing.T) {
+	mustParse := func(uris ...string) []*url.URL {
+		var out []*url.URL
+		for _, u := range uris {
+			parsed, err := url.Parse(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, parsed)
+		}
+		return out
+	}
+
+	tests := map[string]struct {
+		aliases     []string
+		meshAliases []string
+		expected    []string
+		uris        []*url.URL
+
+		expIdentities string
+		expOK         bool
+	}{
+		"if the identities match exactly, should return the expected identities": {
+			expected:      []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:          mustParse("spiffe://cluster.local/ns/sandbox/sa/default"),
+			expIdentities: "spiffe://cluster.local/ns/sandbox/sa/default",
+			expOK:         true,
+		},
+		"if the CSR uses another trust domain and there are no aliases, should not match": {
+			expected: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:     mustParse("spiffe://corp.example/ns/sandbox/sa/default"),
+			expOK:    false,
+		},
+		"if the CSR uses a configured trust domain alias, should return the CSR identities": {
+			aliases:       []string{"corp.example"},
+			expected:      []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:          mustParse("spiffe://corp.example/ns/sandbox/sa/default"),
+			expIdentities: "spiffe://corp.example/ns/sandbox/sa/default",
+			expOK:         true,
+		},
+		"if the caller uses a trust domain alias and the CSR the trust domain, should return the CSR identities": {
+			aliases:       []string{"corp.example"},
+			expected:      []string{"spiffe://corp.example/ns/sandbox/sa/default"},
+			uris:          mustParse("spiffe://cluster.local/ns/sandbox/sa/default"),
+			expIdentities: "spiffe://cluster.local/ns/sandbox/sa/default",
+			expOK:         true,
+		},
+		"if the CSR uses a trust domain alias of the mesh config, should return the CSR identities": {
+			meshAliases:   []string{"corp.example"},
+			expected:      []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:          mustParse("spiffe://corp.example/ns/sandbox/sa/default"),
+			expIdentities: "spiffe://corp.example/ns/sandbox/sa/default",
+			expOK:         true,
+		},
+		"if the CSR uses a trust domain which is not an alias, should not match": {
+			aliases:  []string{"corp.example"},
+			expected: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:     mustParse("spiffe://evil.example/ns/sandbox/sa/default"),
+			expOK:    false,
+		},
+		"if the CSR uses a trust domain alias for a different identity, should not match": {
+			aliases:  []string{"corp.example"},
+			expected: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:     mustParse("spiffe://corp.example/ns/sandbox/sa/admin"),
+			expOK:    false,
+		},
+		"if the CSR has a different number of identities, should not match": {
+			aliases:  []string{"corp.example"},
+			expected: []string{"spiffe://cluster.local/ns/sandbox/sa/default"},
+			uris:     mustParse("spiffe://corp.example/ns/sandbox/sa/default", "spiffe://cluster.local/ns/sandbox/sa/default"),
+			expOK:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			meshConfig := mesh.DefaultMeshConfig()
+			meshConfig.TrustDomainAliases = test.meshAliases
+			s := &Server{
+				meshWatcher:        meshwatcher.NewTestWatcher(meshConfig),
+				trustDomain:        "cluster.local",
+				trustDomainAliases: test.aliases,
+			}
+
+			identities, ok := s.matchCSRIdentities(test.expected, test.uris)
+			if ok != test.expOK {
+				t.Fatalf("unexpected match, exp=%t got=%t", test.expOK, ok)
+			}
+			if identities != test.expIdentities {
+				t.Errorf("unexpected identities, exp=%q got=%q", test.expIdentities, identities)
+			}
+		})
+	}
+}
+
+func Test_matchCSRIdentitiesReactsToMeshChanges(t *testing.T) {
+	watcher := meshwatcher.NewTestWatcher(mesh.DefaultMeshConfig())
+	s := &Server{meshWatcher: watcher, trustDomain: "cluster.local"}
+
+	expected := []string{"spiffe://cluster.local/ns/sandbox/sa/default"}
+	uris := []*url.URL{{Scheme: "spiffe", Host: "corp.example", Path: "/ns/sandbox/sa/default"}}
+
+	if _, ok := s.matchCSRIdentities(expected, uris); ok {
+		t.Fatal("expected no match before the alias is added")
+	}
+
+	meshConfig := mesh.DefaultMeshConfig()
+	meshConfig.TrustDomainAliases = []string{"corp.example"}
+	watcher.Set(meshConfig)
+
+	if _, ok := s.matchCSRIdentities(expected, uris); !ok {
+		t.Fatal("expected match after the alias is added")
+	}
+}
+
+func Test_authRequestIssuedTrustDomainPolicy(t *testing.T) {
+	aliasCSR := string(gen.MustCSR(t, gen.SetCSRIdentities([]string{"spiffe://corp.example/ns/sandbox/sa/default"})))
+	trustDomainCSR := string(gen.MustCSR(t, gen.SetCSRIdentities([]string{"spiffe://cluster.local/ns/sandbox/sa/default"})))
+
+	tests := map[string]struct {
+		policy TrustDomainPolicy
+		csr    string
+
+		expIdentities string
+		expReason     errorReason
+	}{
+		"if no policy, should issue for the requested alias": {
+			csr:           aliasCSR,
+			expIdentities: "spiffe://corp.example/ns/sandbox/sa/default",
+		},
+		"if Requested policy, should issue for the requested alias": {
+			policy:        TrustDomainPolicyRequested,
+			csr:           aliasCSR,
+			expIdentities: "spiffe://corp.example/ns/sandbox/sa/default",
+		},
+		"if TrustDomain policy, should reject the requested alias": {
+			policy:    TrustDomainPolicyTrustDomain,
+			csr:       aliasCSR,
+			expReason: reasonTrustDomainNotAllowed,
+		},
+		"if TrustDomain policy, should issue for the trust domain": {
+			policy:        TrustDomainPolicyTrustDomain,
+			csr:           trustDomainCSR,
+			expIdentities: "spiffe://cluster.local/ns/sandbox/sa/default",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				opts:               Options{IssuedTrustDomainPolicy: test.policy},
+				log:                ktesting.NewLogger(t, ktesting.DefaultConfig),
+				authenticators:     []security.Authenticator{newMockAuthn([]string{"spiffe://cluster.local/ns/sandbox/sa/default"}, "")},
+				trustDomain:        "cluster.local",
+				trustDomainAliases: []string{"corp.example"},
+			}
+
+			identities, _, _, err := s.authRequest(t.Context(), &securityapi.IstioCertificateRequest{Csr: test.csr})
+			if len(test.expReason) > 0 {
+				var rerr *requestError
+				if !errors.As(err, &rerr) || rerr.reason != test.expReason || rerr.code != codes.PermissionDenied {
+					t.Fatalf("expected request error with reason %s, got %v", test.expReason, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if identities != test.expIdentities {
+				t.Errorf("unexpected identities, exp=%q got=%q", test.expIdentities, identities)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"fmt"
+	"path"
+	"slices"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"google.golang.org/grpc/codes"
+
+	"github.com/cert-manager/istio-csr/pkg/server/internal/extensions"
+)
+
+// defaultExtKeyUsages are the extended key usages granted to CSRs which do not
+// request any.
+var defaultExtKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+
+// ExtKeyUsagePolicyOptions constrains the extended key usages granted to
+// workloads. Identities are SPIFFE ID glob patterns, e.g.
+// "spiffe://cluster.local/ns/batch/sa/*". Note that "*" does not match across
+// "/" separators.
+type ExtKeyUsagePolicyOptions struct {
+	// ClientAuthOnlyIdentities are identities which are only granted the
+	// client auth extended key usage.
+	ClientAuthOnlyIdentities []string
+
+	// ServerAuthOnlyIdentities are identities which are only granted the
+	// server auth extended key usage.
+	ServerAuthOnlyIdentities []string
+}
+
+// validate ensures all identity patterns of the policy are well formed.
+func (o ExtKeyUsagePolicyOptions) validate() error {
+	for _, pattern := range slices.Concat(o.ClientAuthOnlyIdentities, o.ServerAuthOnlyIdentities) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid extended key usage policy identity pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// allowed returns whether the extended key usage is allowed for all of the
+// identities by the policy.
+func (o ExtKeyUsagePolicyOptions) allowed(usage x509.ExtKeyUsage, identities []string) bool {
+	var deny []string
+	switch usage {
+	case x509.ExtKeyUsageClientAuth:
+		deny = o.ServerAuthOnlyIdentities
+	case x509.ExtKeyUsageServerAuth:
+		deny = o.ClientAuthOnlyIdentities
+	}
+
+	for _, identity := range identities {
+		for _, pattern := range deny {
+			if ok, _ := path.Match(pattern, identity); ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// grantedExtKeyUsages returns the extended key usages to request for the CSR.
+// These are the usages requested by the CSR, or both client and server auth
+// if none are requested, constrained by the extended key usage policy.
+// Returns an error if no usages remain.
+func (s *Server) grantedExtKeyUsages(identities []string, csr *x509.CertificateRequest) ([]x509.ExtKeyUsage, error) {
+	requested, err := extensions.ExtKeyUsages(csr)
+	if err != nil {
+		return nil, newRequestError(codes.InvalidArgument, reasonInvalidCSR, "failed to parse CSR extended key usages", err)
+	}
+	if requested == nil {
+		requested = defaultExtKeyUsages
+	}
+
+	var usages []x509.ExtKeyUsage
+	for _, usage := range requested {
+		if s.opts.ExtKeyUsagePolicy.allowed(usage, identities) {
+			usages = append(usages, usage)
+		}
+	}
+
+	if len(usages) == 0 {
+		return nil, newRequestError(codes.PermissionDenied, reasonExtKeyUsageNotAllowed, "none of the CSR extended key usages are allowed for the identities", nil)
+	}
+
+	return usages, nil
+}
+
+// certManagerKeyUsages converts the extended key usages to cert-manager key
+// usages.
+func certManagerKeyUsages(usages []x509.ExtKeyUsage) []cmapi.KeyUsage {
+	var cmUsages []cmapi.KeyUsage
+	for _, usage := range usages {
+		switch usage {
+		case x509.ExtKeyUsageClientAuth:
+			cmUsages = append(cmUsages, cmapi.UsageClientAuth)
+		case x509.ExtKeyUsageServerAuth:
+			cmUsages = append(cmUsages, cmapi.UsageServerAuth)
+		}
+	}
+
+	return cmUsages
+}
+
+// extKeyUsageNames returns the names of the extended key usages, for logging.
+func extKeyUsageNames(usages []x509.ExtKeyUsage) []string {
+	var names []string
+	for _, usage := range usages {
+		switch usage {
+		case x509.ExtKeyUsageClientAuth:
+			names = append(names, string(cmapi.UsageClientAuth))
+		case x509.ExtKeyUsageServerAuth:
+			names = append(names, string(cmapi.UsageServerAuth))
+		default:
+			names = append(names, fmt.Sprintf("unknown (%d)", usage))
+		}
+	}
+	return names
+}
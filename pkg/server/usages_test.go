@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_grantedExtKeyUsages(t *testing.T) {
+	const (
+		batch   = "spiffe://cluster.local/ns/batch/sa/job"
+		web     = "spiffe://cluster.local/ns/web/sa/frontend"
+		ingress = "spiffe://cluster.local/ns/istio-ingress/sa/gateway"
+	)
+
+	sk, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustCSR := func(usages ...cmapi.KeyUsage) *x509.CertificateRequest {
+		template, err := pki.GenerateCSR(&cmapi.Certificate{
+			Spec: cmapi.CertificateSpec{URIs: []string{batch}, Usages: usages},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csrDER, err := pki.EncodeCSR(template, sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csr, err := x509.ParseCertificateRequest(csrDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return csr
+	}
+
+	policy := ExtKeyUsagePolicyOptions{
+		ClientAuthOnlyIdentities: []string{"spiffe://cluster.local/ns/batch/sa/*"},
+		ServerAuthOnlyIdentities: []string{ingress},
+	}
+
+	tests := map[string]struct {
+		policy     ExtKeyUsagePolicyOptions
+		identities []string
+		csr        *x509.CertificateRequest
+
+		expUsages []x509.ExtKeyUsage
+		expCode   codes.Code
+	}{
+		"if CSR requests no usages, should grant client and server auth": {
+			identities: []string{web},
+			csr:        mustCSR(),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		},
+		"if CSR requests client auth only, should grant client auth": {
+			identities: []string{web},
+			csr:        mustCSR(cmapi.UsageClientAuth),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		"if CSR requests server auth only, should grant server auth": {
+			identities: []string{web},
+			csr:        mustCSR(cmapi.UsageDigitalSignature, cmapi.UsageServerAuth),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		},
+		"if identity is client auth only, and CSR requests no usages, should grant client auth": {
+			policy:     policy,
+			identities: []string{batch},
+			csr:        mustCSR(),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		"if identity is server auth only, and CSR requests both, should grant server auth": {
+			policy:     policy,
+			identities: []string{ingress},
+			csr:        mustCSR(cmapi.UsageClientAuth, cmapi.UsageServerAuth),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		},
+		"if identity is not matched by the policy, should grant requested usages": {
+			policy:     policy,
+			identities: []string{web},
+			csr:        mustCSR(),
+			expUsages:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		},
+		"if identity is client auth only, and CSR requests server auth only, should error": {
+			policy:     policy,
+			identities: []string{batch},
+			csr:        mustCSR(cmapi.UsageServerAuth),
+			expCode:    codes.PermissionDenied,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{opts: Options{ExtKeyUsagePolicy: test.policy}}
+
+			usages, err := s.grantedExtKeyUsages(test.identities, test.csr)
+			if test.expCode == codes.OK {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expCode, status.Code(s.statusError(err)))
+			}
+			assert.Equal(t, test.expUsages, usages)
+		})
+	}
+}
+
+func Test_ExtKeyUsagePolicyOptionsValidate(t *testing.T) {
+	assert.NoError(t, ExtKeyUsagePolicyOptions{ClientAuthOnlyIdentities: []string{"spiffe://cluster.local/ns/batch/sa/*"}}.validate())
+	assert.Error(t, ExtKeyUsagePolicyOptions{ServerAuthOnlyIdentities: []string{"spiffe://cluster.local/ns/[/sa/*"}}.validate())
+}
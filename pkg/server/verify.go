@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"cmp"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// notAfterSkew is the tolerated clock skew between istio-csr and the
+	// issuer, when verifying the NotAfter of an issued certificate.
+	notAfterSkew = time.Minute
+
+	mismatchPublicKey = "public_key"
+	mismatchURISANs   = "uri_sans"
+	mismatchExtraSANs = "extra_sans"
+	mismatchNotAfter  = "not_after"
+	mismatchUsages    = "ext_key_usages"
+)
+
+var (
+	metricIssuedCertificateMismatches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cert_manager_istio_csr",
+			Name:      "issued_certificate_mismatches",
+			Help:      "Total number of certificates returned by an issuer which did not match the workload certificate request.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricIssuedCertificateMismatches)
+}
+
+// verifyLeaf verifies that the leaf certificate returned by the issuer matches
+// the CSR and the identities it was requested for. The leaf must have the
+// CSR's public key, exactly the requested identities as URI SANs, exactly the
+// CSR's DNS and IP SANs, no other SANs, no extended key usages other than
+// those requested, and must not expire after the requested duration from when
+// it was signed. The signed time is when the issuer returned the certificate,
+// so that issuers which take a long time to sign are not rejected. Mismatches
+// are counted by reason.
+func verifyLeaf(leaf *x509.Certificate, csr *x509.CertificateRequest, identities []string, signed time.Time, duration time.Duration, usages []x509.ExtKeyUsage) error {
+	if err := verifyLeafMatches(leaf, csr, identities, signed, duration, usages); err != nil {
+		metricIssuedCertificateMismatches.WithLabelValues(err.reason).Inc()
+		return err
+	}
+
+	return nil
+}
+
+// leafMismatchError is returned when an issued certificate does not match the
+// certificate request.
+type leafMismatchError struct {
+	reason string
+	err    error
+}
+
+func (e *leafMismatchError) Error() string {
+	return e.err.Error()
+}
+
+func verifyLeafMatches(leaf *x509.Certificate, csr *x509.CertificateRequest, identities []string, signed time.Time, duration time.Duration, usages []x509.ExtKeyUsage) *leafMismatchError {
+	pub, ok := leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(csr.PublicKey) {
+		return &leafMismatchError{mismatchPublicKey, fmt.Errorf("issued certificate public key does not match the CSR public key")}
+	}
+
+	var uris []string
+	for _, uri := range leaf.URIs {
+		uris = append(uris, uri.String())
+	}
+	slices.Sort(uris)
+
+	expURIs := slices.Sorted(slices.Values(identities))
+	if !slices.Equal(uris, expURIs) {
+		return &leafMismatchError{mismatchURISANs, fmt.Errorf("issued certificate URI SANs %v do not match the requested identities %v", uris, expURIs)}
+	}
+
+	if !sortedEqual(leaf.DNSNames, csr.DNSNames) || !sortedEqual(ipStrings(leaf.IPAddresses), ipStrings(csr.IPAddresses)) || len(leaf.EmailAddresses) > 0 {
+		return &leafMismatchError{mismatchExtraSANs, fmt.Errorf("issued certificate contains unexpected SANs: dns=%v ips=%v emails=%v",
+			leaf.DNSNames, leaf.IPAddresses, leaf.EmailAddresses)}
+	}
+
+	// A leaf without extended key usages is valid for any usage, so is only
+	// accepted if all usages were requested.
+	if len(leaf.ExtKeyUsage) == 0 && !sortedEqual(usages, defaultExtKeyUsages) {
+		return &leafMismatchError{mismatchUsages, fmt.Errorf("issued certificate has no extended key usages, requested %v", extKeyUsageNames(usages))}
+	}
+	for _, usage := range leaf.ExtKeyUsage {
+		if !slices.Contains(usages, usage) {
+			return &leafMismatchError{mismatchUsages, fmt.Errorf("issued certificate has extended key usages %v, requested %v", extKeyUsageNames(leaf.ExtKeyUsage), extKeyUsageNames(usages))}
+		}
+	}
+
+	if maxNotAfter := signed.Add(duration + notAfterSkew); leaf.NotAfter.After(maxNotAfter) {
+		return &leafMismatchError{mismatchNotAfter, fmt.Errorf("issued certificate expires at %s, after the requested duration of %s",
+			leaf.NotAfter.UTC().Format(time.RFC3339), duration)}
+	}
+
+	return nil
+}
+
+// sortedEqual returns true if both slices contain the same elements,
+// regardless of order.
+func sortedEqual[T cmp.Ordered](a, b []T) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// ipStrings returns the string form of each IP address.
+func ipStrings(ips []net.IP) []string {
+	var s []string
+	for _, ip := range ips {
+		s = append(s, ip.String())
+	}
+	return s
+}
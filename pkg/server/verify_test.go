@@ -0,0 +1,222 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	pkiutil "istio.io/istio/security/pkg/pki/util"
+
+	"github.com/cert-manager/istio-csr/test/gen"
+)
+
+func Test_verifyLeaf(t *testing.T) {
+	const identity = "spiffe://cluster.local/ns/foo/sa/bar"
+
+	csr, err := pkiutil.ParsePemEncodedCSR(gen.MustCSR(t, gen.SetCSRIdentities([]string{identity})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gatewayCSR, err := pkiutil.ParsePemEncodedCSR(gen.MustCSR(t,
+		gen.SetCSRIdentities([]string{identity}),
+		gen.SetCSRDNS([]string{"example.com"}),
+		gen.SetCSRIPs([]string{"10.0.0.1"}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherPK, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustURL := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	now := time.Now()
+
+	tests := map[string]struct {
+		csr    *x509.CertificateRequest
+		leaf   *x509.Certificate
+		usages []x509.ExtKeyUsage
+
+		expMismatch string
+	}{
+		"if the leaf matches the request, should not error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour),
+			},
+		},
+		"if the leaf expires within the tolerated clock skew, should not error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour + notAfterSkew),
+			},
+		},
+		"if the leaf has a different public key, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: otherPK.Public(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour),
+			},
+			expMismatch: mismatchPublicKey,
+		},
+		"if the leaf is for a different identity, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL("spiffe://cluster.local/ns/foo/sa/other")},
+				NotAfter:  now.Add(time.Hour),
+			},
+			expMismatch: mismatchURISANs,
+		},
+		"if the leaf has additional identities, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity), mustURL("spiffe://cluster.local/ns/foo/sa/other")},
+				NotAfter:  now.Add(time.Hour),
+			},
+			expMismatch: mismatchURISANs,
+		},
+		"if the leaf has DNS names, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				DNSNames:  []string{"example.com"},
+				NotAfter:  now.Add(time.Hour),
+			},
+			expMismatch: mismatchExtraSANs,
+		},
+		"if the leaf has IP addresses, should error": {
+			leaf: &x509.Certificate{
+				PublicKey:   gen.PublicKey(),
+				URIs:        []*url.URL{mustURL(identity)},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+				NotAfter:    now.Add(time.Hour),
+			},
+			expMismatch: mismatchExtraSANs,
+		},
+		"if the leaf has the DNS names and IP addresses of the CSR, should not error": {
+			csr: gatewayCSR,
+			leaf: &x509.Certificate{
+				PublicKey:   gen.PublicKey(),
+				URIs:        []*url.URL{mustURL(identity)},
+				DNSNames:    []string{"example.com"},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+				NotAfter:    now.Add(time.Hour),
+			},
+		},
+		"if the leaf has different DNS names to the CSR, should error": {
+			csr: gatewayCSR,
+			leaf: &x509.Certificate{
+				PublicKey:   gen.PublicKey(),
+				URIs:        []*url.URL{mustURL(identity)},
+				DNSNames:    []string{"example.com", "example.org"},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+				NotAfter:    now.Add(time.Hour),
+			},
+			expMismatch: mismatchExtraSANs,
+		},
+		"if the leaf has the requested extended key usages, should not error": {
+			leaf: &x509.Certificate{
+				PublicKey:   gen.PublicKey(),
+				URIs:        []*url.URL{mustURL(identity)},
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				NotAfter:    now.Add(time.Hour),
+			},
+			usages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		"if the leaf has extended key usages which were not requested, should error": {
+			leaf: &x509.Certificate{
+				PublicKey:   gen.PublicKey(),
+				URIs:        []*url.URL{mustURL(identity)},
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+				NotAfter:    now.Add(time.Hour),
+			},
+			usages:      []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			expMismatch: mismatchUsages,
+		},
+		"if the leaf has no extended key usages, and only client auth was requested, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour),
+			},
+			usages:      []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			expMismatch: mismatchUsages,
+		},
+		"if the leaf has no extended key usages, and all usages were requested in any order, should not error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour),
+			},
+			usages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		},
+		"if the leaf expires after the requested duration, should error": {
+			leaf: &x509.Certificate{
+				PublicKey: gen.PublicKey(),
+				URIs:      []*url.URL{mustURL(identity)},
+				NotAfter:  now.Add(time.Hour * 2),
+			},
+			expMismatch: mismatchNotAfter,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var before float64
+			if len(test.expMismatch) > 0 {
+				before = testutil.ToFloat64(metricIssuedCertificateMismatches.WithLabelValues(test.expMismatch))
+			}
+
+			reqCSR := csr
+			if test.csr != nil {
+				reqCSR = test.csr
+			}
+
+			usages := test.usages
+			if usages == nil {
+				usages = defaultExtKeyUsages
+			}
+
+			err := verifyLeaf(test.leaf, reqCSR, []string{identity}, now, time.Hour, usages)
+			assert.Equal(t, len(test.expMismatch) > 0, err != nil, "%v", err)
+
+			if len(test.expMismatch) > 0 {
+				after := testutil.ToFloat64(metricIssuedCertificateMismatches.WithLabelValues(test.expMismatch))
+				assert.Equal(t, before+1, after, "expected mismatch metric to be incremented")
+			}
+		})
+	}
+}
@@ -32,6 +32,7 @@ var _ cmtls.Interface = &FakeTLS{}
 // FakeTLS is a fake implementation of tls.Interface that can be used for testing.
 type FakeTLS struct {
 	funcTrustDomain           func() string
+	funcTrustDomainAliases    func() []string
 	funcRootCAs               func(ctx context.Context) *rootca.RootCAs
 	funcConfig                func(ctx context.Context) (*tls.Config, error)
 	funcSubscribeRootCAsEvent func() <-chan event.GenericEvent
@@ -40,6 +41,7 @@ type FakeTLS struct {
 func New() *FakeTLS {
 	return &FakeTLS{
 		funcTrustDomain:           func() string { return "" },
+		funcTrustDomainAliases:    func() []string { return nil },
 		funcRootCAs:               func(ctx context.Context) *rootca.RootCAs { return &rootca.RootCAs{} },
 		funcConfig:                func(_ context.Context) (*tls.Config, error) { return nil, nil },
 		funcSubscribeRootCAsEvent: func() <-chan event.GenericEvent { return make(chan event.GenericEvent) },
@@ -55,6 +57,10 @@ func (f *FakeTLS) TrustDomain() string {
 	return f.funcTrustDomain()
 }
 
+func (f *FakeTLS) TrustDomainAliases() []string {
+	return f.funcTrustDomainAliases()
+}
+
 func (f *FakeTLS) RootCAs(ctx context.Context) *rootca.RootCAs {
 	return f.funcRootCAs(ctx)
 }
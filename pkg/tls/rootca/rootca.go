@@ -24,8 +24,9 @@ import (
 	"os"
 
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
-	"github.com/fsnotify/fsnotify"
 	"github.com/go-logr/logr"
+
+	"github.com/cert-manager/istio-csr/pkg/filewatch"
 )
 
 // RootCAs is a Root CAs bundle that contains raw PEM encoded CAs, as well as
@@ -67,52 +68,17 @@ func Watch(ctx context.Context, log logr.Logger, filepath string) (<-chan RootCA
 		return nil, fmt.Errorf("root CA bundle is empty")
 	}
 
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := filewatch.New(w.log, w.filepath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file watch: %w", err)
-	}
-
-	if err := watcher.Add(w.filepath); err != nil {
-		return nil, fmt.Errorf("failed to add root CAs file for watching %q: %w", w.filepath, err)
+		return nil, err
 	}
 
 	go func() {
-		defer watcher.Close()
-
 		// Send initial root CAs state
 		w.broadcastChan <- rootCAs
 		w.rootCAsPEM = rootCAs.PEM
 
-		for {
-			select {
-			case <-ctx.Done():
-				w.log.Info("closing root CAs file watcher")
-				return
-
-			case event := <-watcher.Events:
-				w.log.V(3).Info("received event from file watcher", "event", event.Op.String())
-
-				// Watch for remove events, since this is actually the syslink being
-				// changed in the volume mount.
-				if event.Op == fsnotify.Remove {
-					if err := watcher.Remove(event.Name); err != nil {
-						w.log.Error(err, "failed to remove file watch")
-					}
-					if err := watcher.Add(w.filepath); err != nil {
-						w.log.Error(err, "failed to add new file watch")
-					}
-					w.reloadConfig()
-				}
-
-				// Also allow normal files to be modified and reloaded.
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					w.reloadConfig()
-				}
-
-			case err := <-watcher.Errors:
-				w.log.Error(err, "errors watching root CAs file")
-			}
-		}
+		watcher.Run(ctx, w.reloadConfig)
 	}()
 
 	return w.broadcastChan, nil
@@ -63,6 +63,10 @@ type Interface interface {
 	// TrustDomain returns the Trust Domain of the mesh.
 	TrustDomain() string
 
+	// TrustDomainAliases returns the aliases of the Trust Domain of the mesh,
+	// which are also accepted for authentication.
+	TrustDomainAliases() []string
+
 	// RootCAs returns the root CA PEM bundle as well as an *x509.CertPool
 	// containing the decoded CA certificates.
 	// This func blocks until the CA certificates are available.
@@ -82,6 +86,11 @@ type Options struct {
 	// TrustDomain is the trust domain to use for this mesh.
 	TrustDomain string
 
+	// TrustDomainAliases are other trust domains of this mesh, which are
+	// accepted for authentication, client certificate verification and CSR
+	// URIs, for example when migrating between trust domains.
+	TrustDomainAliases []string
+
 	// RootCAsCertFile is an optional file location containing a PEM CA bundle.
 	// If non-empty, this CA bundle will be used to populate the CA of the mesh.
 	RootCAsCertFile string
@@ -434,7 +443,7 @@ func (p *Provider) fetchCertificate(ctx context.Context) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("failed to generate serving private key and CSR: %s", err)
 	}
 
-	bundle, err := p.cm.Sign(ctx, "istio-csr-serving", csr, p.opts.ServingCertificateDuration, []cmapi.KeyUsage{cmapi.UsageServerAuth})
+	bundle, err := p.cm.Sign(ctx, "istio-csr-serving", csr, p.opts.ServingCertificateDuration, []cmapi.KeyUsage{cmapi.UsageServerAuth}, nil)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to sign serving certificate: %w", err)
 	}
@@ -459,8 +468,10 @@ func (p *Provider) fetchCertificate(ctx context.Context) (time.Time, error) {
 
 	// Build the client certificate verifier based upon the root certificate
 	peerCertVerifier := spiffe.NewPeerCertVerifier()
-	if err := peerCertVerifier.AddMappingFromPEM(p.opts.TrustDomain, p.rootCAs.PEM); err != nil {
-		return time.Time{}, fmt.Errorf("failed to add root CAs to SPIFFE peer certificate verifier: %w", err)
+	for _, trustDomain := range append([]string{p.opts.TrustDomain}, p.opts.TrustDomainAliases...) {
+		if err := peerCertVerifier.AddMappingFromPEM(trustDomain, p.rootCAs.PEM); err != nil {
+			return time.Time{}, fmt.Errorf("failed to add root CAs to SPIFFE peer certificate verifier for trust domain %q: %w", trustDomain, err)
+		}
 	}
 
 	tlsCert, err := tls.X509KeyPair(bundle.Certificate, pk)
@@ -507,6 +518,10 @@ func (p *Provider) TrustDomain() string {
 	return p.opts.TrustDomain
 }
 
+func (p *Provider) TrustDomainAliases() []string {
+	return p.opts.TrustDomainAliases
+}
+
 // All istio-csr pods need up-to-date serving certs to minimise the delay when a non-leader pod
 // takes leadership.
 func (p *Provider) NeedLeaderElection() bool {
@@ -83,7 +83,7 @@ func mustTestCA(t *testing.T) (*x509.Certificate, crypto.Signer) {
 func testSigner(t *testing.T, caCert *x509.Certificate, caKey crypto.Signer) certmanager.Signer {
 	t.Helper()
 
-	return cmfake.New().WithSign(func(_ context.Context, _ string, csrPEM []byte, duration time.Duration, _ []cmapi.KeyUsage) (certmanager.Bundle, error) {
+	return cmfake.New().WithSign(func(_ context.Context, _ string, csrPEM []byte, duration time.Duration, _ []cmapi.KeyUsage, _ *cmmeta.IssuerReference) (certmanager.Bundle, error) {
 		template, err := pki.CertificateTemplateFromCSRPEM(csrPEM)
 		if err != nil {
 			return certmanager.Bundle{}, err
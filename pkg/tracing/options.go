@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Options holds configuration for exporting OpenTelemetry traces.
+type Options struct {
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector that traces are
+	// exported to. If empty, tracing is disabled.
+	OTLPEndpoint string
+
+	// OTLPInsecure disables TLS when connecting to the OTLP collector.
+	OTLPInsecure bool
+
+	// SamplingRatio is the ratio of new traces which are sampled. Requests
+	// which propagate a trace context from the istio agent follow the
+	// sampling decision of the agent.
+	SamplingRatio float64
+}
+
+// Enabled returns true if traces should be exported.
+func (o *Options) Enabled() bool {
+	return len(o.OTLPEndpoint) > 0
+}
+
+// Validate confirms that the given tracing options are valid.
+func (o *Options) Validate() error {
+	if o.SamplingRatio < 0 || o.SamplingRatio > 1 {
+		return fmt.Errorf("tracing-sampling-ratio must be between 0 and 1, got %v", o.SamplingRatio)
+	}
+
+	return nil
+}
+
+func AddFlags(o *Options, fs *pflag.FlagSet) {
+	fs.StringVar(&o.OTLPEndpoint, "tracing-otlp-endpoint", "",
+		"The host:port of an OTLP gRPC collector to export OpenTelemetry traces to. If empty, tracing is disabled.")
+
+	fs.BoolVar(&o.OTLPInsecure, "tracing-otlp-insecure", false,
+		"If true, connect to the OTLP collector without TLS.")
+
+	fs.Float64Var(&o.SamplingRatio, "tracing-sampling-ratio", 1,
+		"Ratio of new traces to sample, between 0 and 1. Requests with a trace context propagated "+
+			"from the istio agent follow the agent's sampling decision.")
+}
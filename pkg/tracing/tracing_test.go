@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		opts   Options
+		expErr bool
+	}{
+		"a sampling ratio of 0 should be valid": {
+			opts: Options{SamplingRatio: 0},
+		},
+		"a sampling ratio of 1 should be valid": {
+			opts: Options{SamplingRatio: 1},
+		},
+		"a negative sampling ratio should be invalid": {
+			opts:   Options{SamplingRatio: -0.1},
+			expErr: true,
+		},
+		"a sampling ratio greater than 1 should be invalid": {
+			opts:   Options{SamplingRatio: 1.1},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.opts.Validate()
+			assert.Equal(t, test.expErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(t.Context(), Options{SamplingRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, shutdown(t.Context()))
+}
+
+func TestEndSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, span := tracer.Start(t.Context(), "ok")
+	EndSpan(span, nil)
+
+	_, span = tracer.Start(t.Context(), "failed")
+	EndSpan(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+	assert.Len(t, spans[1].Events(), 1, "expected error to be recorded as an event")
+}
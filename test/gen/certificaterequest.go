@@ -17,7 +17,11 @@ limitations under the License.
 package gen
 
 import (
+	"time"
+
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type CertificateRequestModifier func(*cmapi.CertificateRequest)
@@ -57,3 +61,39 @@ func SetCertificateRequestCA(caPEM []byte) CertificateRequestModifier {
 		cr.Status.CA = caPEM
 	}
 }
+
+func AddCertificateRequestLabel(key, value string) CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		if cr.Labels == nil {
+			cr.Labels = make(map[string]string)
+		}
+		cr.Labels[key] = value
+	}
+}
+
+func AddCertificateRequestAnnotation(key, value string) CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		if cr.Annotations == nil {
+			cr.Annotations = make(map[string]string)
+		}
+		cr.Annotations[key] = value
+	}
+}
+
+func SetCertificateRequestCSR(csrPEM []byte) CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		cr.Spec.Request = csrPEM
+	}
+}
+
+func SetCertificateRequestIssuerRef(issuerRef cmmeta.IssuerReference) CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		cr.Spec.IssuerRef = issuerRef
+	}
+}
+
+func SetCertificateRequestDuration(duration time.Duration) CertificateRequestModifier {
+	return func(cr *cmapi.CertificateRequest) {
+		cr.Spec.Duration = &metav1.Duration{Duration: duration}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package gen
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -44,6 +45,7 @@ func init() {
 type CSRBuilder struct {
 	ids, dns, ips, emails []string
 	cn                    string
+	signer                crypto.Signer
 }
 
 type CSRModifier func(*CSRBuilder)
@@ -91,7 +93,12 @@ func CSR(mods ...CSRModifier) ([]byte, error) {
 	csr.EmailAddresses = csrBuilder.emails
 	csr.Subject.CommonName = csrBuilder.cn
 
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, csr, sk)
+	var signer crypto.Signer = sk
+	if csrBuilder.signer != nil {
+		signer = csrBuilder.signer
+	}
+
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, csr, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -129,9 +136,22 @@ func SetCSRCommonName(cn string) CSRModifier {
 	}
 }
 
+// SetCSRSigner sets the private key which signs the CSR, and whose public key
+// the CSR holds. Defaults to the shared 2048-bit RSA key.
+func SetCSRSigner(signer crypto.Signer) CSRModifier {
+	return func(csr *CSRBuilder) {
+		csr.signer = signer
+	}
+}
+
 func Key() []byte {
 	return pem.EncodeToMemory(&pem.Block{
 		Type:  "RSA PRIVATE KEY",
 		Bytes: x509.MarshalPKCS1PrivateKey(sk),
 	})
 }
+
+// PublicKey returns the public key of the shared signer that signs all CSRs.
+func PublicKey() crypto.PublicKey {
+	return sk.Public()
+}